@@ -1,7 +1,40 @@
 package config
 
-import "os"
+import (
+	"os"
+	"strconv"
+)
 
 func IsDevMode() bool {
 	return os.Getenv("DEV_MODE") == "true"
 }
+
+// AutoCategorizeThreshold is the confidence above which learned category suggestions are applied
+// to imported expenditures. Auto-categorization is off if it is 0 or unset.
+func AutoCategorizeThreshold() float64 {
+	threshold, err := strconv.ParseFloat(os.Getenv("YABA_AUTO_CATEGORIZE_THRESHOLD"), 64)
+	if err != nil || threshold <= 0 {
+		return 0
+	}
+
+	return threshold
+}
+
+// defaultTrashRetentionDays is how long deleted records are kept if YABA_TRASH_RETENTION_DAYS is unset.
+const defaultTrashRetentionDays = 30
+
+// TrashRetentionDays is how many days deleted budgets, payment methods and expenditures are kept in
+// the trash before they are purged. They are never purged if it is 0.
+func TrashRetentionDays() int {
+	value, ok := os.LookupEnv("YABA_TRASH_RETENTION_DAYS")
+	if !ok {
+		return defaultTrashRetentionDays
+	}
+
+	days, err := strconv.Atoi(value)
+	if err != nil || days < 0 {
+		return defaultTrashRetentionDays
+	}
+
+	return days
+}
@@ -0,0 +1,29 @@
+package model
+
+import (
+	"time"
+	"yaba/internal/model"
+)
+
+// AttachmentURLPrefix is the path that attachments are downloaded from, followed by their ID.
+const AttachmentURLPrefix = "/api/attachments/"
+
+func AttachmentToAttachmentResponse(attachment *model.Attachment) *Attachment {
+	return &Attachment{
+		ID:          attachment.ID.String(),
+		Filename:    attachment.Filename,
+		ContentType: attachment.ContentType,
+		Size:        int(attachment.Size),
+		Created:     attachment.CreatedTime.Format(time.RFC3339),
+		URL:         AttachmentURLPrefix + attachment.ID.String(),
+	}
+}
+
+func AttachmentsToAttachmentResponses(attachments []*model.Attachment) []*Attachment {
+	ret := make([]*Attachment, len(attachments))
+	for i, attachment := range attachments {
+		ret[i] = AttachmentToAttachmentResponse(attachment)
+	}
+
+	return ret
+}
@@ -0,0 +1,39 @@
+package model
+
+import (
+	"strconv"
+	"time"
+	"yaba/internal/model"
+)
+
+func AuditEntryToAuditEntryResponse(entry *model.AuditEntry) *AuditEntry {
+	ret := &AuditEntry{
+		ID:            strconv.FormatInt(entry.ID, 10),
+		EntityType:    entry.EntityType,
+		EntityID:      entry.EntityID,
+		Action:        AuditAction(entry.Action),
+		Changed:       entry.Changed.Format(time.RFC3339),
+		TransactionID: strconv.FormatInt(entry.TransactionID, 10),
+	}
+
+	if entry.Before != nil {
+		before := string(entry.Before)
+		ret.Before = &before
+	}
+
+	if entry.After != nil {
+		after := string(entry.After)
+		ret.After = &after
+	}
+
+	return ret
+}
+
+func AuditEntriesToAuditEntryResponses(entries []*model.AuditEntry) []*AuditEntry {
+	ret := make([]*AuditEntry, len(entries))
+	for i, entry := range entries {
+		ret[i] = AuditEntryToAuditEntryResponse(entry)
+	}
+
+	return ret
+}
@@ -2,6 +2,7 @@ package model
 
 import (
 	"fmt"
+	"time"
 	"yaba/internal/model"
 
 	"github.com/google/uuid"
@@ -15,42 +16,114 @@ func BudgetFromNewBudgetInput(owner uuid.UUID, input *NewBudgetInput) (*model.Bu
 		return nil, err
 	}
 
-	return &model.Budget{
-		ID:       budgetID,
-		Owner:    owner,
-		Name:     input.Name,
-		Incomes:  incomesFromIncomeInput(budgetID, input.Incomes),
-		Expenses: expenses,
-	}, nil
+	budget := &model.Budget{
+		ID:          budgetID,
+		Owner:       owner,
+		Name:        input.Name,
+		Incomes:     incomesFromIncomeInput(budgetID, input.Incomes),
+		Expenses:    expenses,
+		Period:      model.BudgetPeriodMonthly,
+		PeriodStart: model.StartOfMonth(time.Now()),
+	}
+
+	if err = setBudgetPeriod(budget, input.Period, input.PeriodStart, input.PeriodDays); err != nil {
+		return nil, err
+	}
+
+	return budget, nil
 }
 
-func BudgetFromUpdateBudgetInput(
-	budgetID, owner uuid.UUID,
-	input *UpdateBudgetInput,
-) (*model.Budget, error) {
-	expenses, err := expensesFromExpenseInput(budgetID, input.Expenses)
+// BudgetFromUpdateBudgetInput returns the existing budget changed by the input. Period settings that
+// aren't in the input are kept.
+func BudgetFromUpdateBudgetInput(existing *model.Budget, input *UpdateBudgetInput) (*model.Budget, error) {
+	expenses, err := expensesFromExpenseInput(existing.ID, input.Expenses)
 	if err != nil {
 		return nil, err
 	}
 
-	return &model.Budget{
-		ID:       budgetID,
-		Owner:    owner,
-		Name:     *input.Name,
-		Incomes:  incomesFromIncomeInput(budgetID, input.Incomes),
-		Expenses: expenses,
-	}, nil
+	budget := &model.Budget{
+		ID:          existing.ID,
+		Owner:       existing.Owner,
+		Name:        existing.Name,
+		Incomes:     incomesFromIncomeInput(existing.ID, input.Incomes),
+		Expenses:    expenses,
+		Period:      existing.Period,
+		PeriodStart: existing.PeriodStart,
+		PeriodDays:  existing.PeriodDays,
+	}
+
+	if input.Name != nil {
+		budget.Name = *input.Name
+	}
+
+	if err = setBudgetPeriod(budget, input.Period, input.PeriodStart, input.PeriodDays); err != nil {
+		return nil, err
+	}
+
+	return budget, nil
+}
+
+func setBudgetPeriod(budget *model.Budget, period *BudgetPeriodType, start *string, days *int) error {
+	if period != nil {
+		budget.Period = model.BudgetPeriodType(*period)
+	}
+
+	if start != nil {
+		parsed, err := time.Parse(time.DateOnly, *start)
+		if err != nil {
+			return fmt.Errorf("failed to parse period start: %w", err)
+		}
+
+		budget.PeriodStart = parsed
+	}
+
+	if days != nil {
+		budget.PeriodDays = *days
+	}
+
+	return nil
 }
 
 func BudgetToBudgetResponse(b *model.Budget) *BudgetResponse {
 	id, owner, name := b.ID.String(), b.Owner.String(), b.Name
 
+	period := BudgetPeriodType(b.Period)
+	periodStart := b.PeriodStart.Format(time.DateOnly)
+
 	return &BudgetResponse{
-		ID:       &id,
-		Owner:    &owner,
-		Name:     &name,
-		Incomes:  incomesToIncomeResponse(b.Incomes),
-		Expenses: expensesToExpenseResponse(b.Expenses),
+		ID:          &id,
+		Owner:       &owner,
+		Name:        &name,
+		Incomes:     incomesToIncomeResponse(b.Incomes),
+		Expenses:    expensesToExpenseResponse(b.Expenses),
+		Period:      &period,
+		PeriodStart: &periodStart,
+		PeriodDays:  &b.PeriodDays,
+	}
+}
+
+// BudgetPeriodToBudgetPeriodResponse returns the period with what is left of each expense.
+func BudgetPeriodToBudgetPeriodResponse(period *model.BudgetPeriod) *BudgetPeriod {
+	expenses := make([]*BudgetPeriodExpense, len(period.Allocations))
+	for i, allocation := range period.Allocations {
+		expenses[i] = &BudgetPeriodExpense{
+			ExpenseID:     allocation.ExpenseID.String(),
+			Category:      allocation.Category,
+			Allocated:     allocation.Amount,
+			HomeAllocated: allocation.HomeAmount,
+			Spent:         allocation.Spent,
+			RolledOver:    allocation.RolledOver,
+			Remaining:     allocation.Remaining(),
+			Rollover:      allocation.Rollover,
+			Currency:      allocation.Currency,
+		}
+	}
+
+	return &BudgetPeriod{
+		BudgetID: period.BudgetID.String(),
+		Start:    period.Start.Format(time.DateOnly),
+		End:      period.End.Format(time.DateOnly),
+		Expenses: expenses,
 	}
 }
 
@@ -65,6 +138,8 @@ func expensesToExpenseResponse(expenses []*model.Expense) []*ExpenseResponse {
 			Amount:   &expense.Amount,
 			IsFixed:  &expense.Fixed,
 			IsSlack:  &expense.Slack,
+			Currency: &expense.Currency,
+			Rollover: &expense.Rollover,
 		}
 	}
 
@@ -75,8 +150,9 @@ func incomesToIncomeResponse(incomes []*model.Income) []*IncomeResponse {
 	ret := make([]*IncomeResponse, len(incomes))
 	for i, income := range incomes {
 		ret[i] = &IncomeResponse{
-			Source: &income.Source,
-			Amount: &income.Amount,
+			Source:   &income.Source,
+			Amount:   &income.Amount,
+			Currency: &income.Currency,
 		}
 	}
 
@@ -101,6 +177,7 @@ func expensesFromExpenseInput(budgetID uuid.UUID, input []*ExpenseInput) ([]*mod
 			BudgetID: budgetID,
 			Category: expense.Category,
 			Amount:   expense.Amount,
+			Currency: dereferenceOrEmpty(expense.Currency),
 		}
 
 		if expense.IsFixed != nil {
@@ -110,6 +187,10 @@ func expensesFromExpenseInput(budgetID uuid.UUID, input []*ExpenseInput) ([]*mod
 		if expense.IsSlack != nil {
 			expenses[i].Slack = *expense.IsSlack
 		}
+
+		if expense.Rollover != nil {
+			expenses[i].Rollover = *expense.Rollover
+		}
 	}
 
 	return expenses, nil
@@ -120,11 +201,34 @@ func incomesFromIncomeInput(budgetID uuid.UUID, input []*IncomeInput) []*model.I
 
 	for i, income := range input {
 		incomes[i] = &model.Income{
-			Owner:  budgetID,
-			Source: income.Source,
-			Amount: income.Amount,
+			Owner:    budgetID,
+			Source:   income.Source,
+			Amount:   income.Amount,
+			Currency: dereferenceOrEmpty(income.Currency),
 		}
 	}
 
 	return incomes
 }
+
+// BudgetPageToBudgetConnection returns the page with the cursor of each budget.
+func BudgetPageToBudgetConnection(page *model.BudgetPage) *BudgetConnection {
+	connection := &BudgetConnection{
+		Edges:      make([]*BudgetEdge, len(page.Budgets)),
+		PageInfo:   PageInfo{HasNextPage: page.HasNextPage},
+		TotalCount: page.TotalCount,
+	}
+
+	for i, budget := range page.Budgets {
+		connection.Edges[i] = &BudgetEdge{
+			Cursor: model.NewBudgetCursor(budget).String(),
+			Node:   *BudgetToBudgetResponse(budget),
+		}
+	}
+
+	if len(connection.Edges) > 0 {
+		connection.PageInfo.EndCursor = &connection.Edges[len(connection.Edges)-1].Cursor
+	}
+
+	return connection
+}
@@ -0,0 +1,102 @@
+package model
+
+import (
+	"fmt"
+	"strconv"
+	"yaba/internal/classifier"
+	"yaba/internal/model"
+
+	"github.com/google/uuid"
+)
+
+// CategorizationRuleFromCategorizationRuleInput converts a GraphQL input to an internal rule.
+// Unset actions are left empty.
+func CategorizationRuleFromCategorizationRuleInput(input *CategorizationRuleInput) (*model.CategorizationRule, error) {
+	rule := &model.CategorizationRule{
+		MatchType:      model.MatchTypeContains,
+		Pattern:        input.Pattern,
+		BudgetCategory: dereferenceOrEmpty(input.BudgetCategory),
+		RewardCategory: dereferenceOrEmpty(input.RewardCategory),
+	}
+
+	if input.Priority != nil {
+		rule.Priority = *input.Priority
+	}
+
+	if input.MatchType != nil {
+		rule.MatchType = model.MatchType(*input.MatchType)
+	}
+
+	if input.PaymentMethod != nil && *input.PaymentMethod != "" {
+		method, err := uuid.Parse(*input.PaymentMethod)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse UUID: %w", err)
+		}
+
+		rule.Method = method
+	}
+
+	return rule, nil
+}
+
+func CategorizationRuleToCategorizationRuleResponse(rule *model.CategorizationRule) *CategorizationRule {
+	var budgetCategory, rewardCategory, method *string
+
+	if rule.BudgetCategory != "" {
+		budgetCategory = &rule.BudgetCategory
+	}
+
+	if rule.RewardCategory != "" {
+		rewardCategory = &rule.RewardCategory
+	}
+
+	if rule.Method != uuid.Nil {
+		id := rule.Method.String()
+		method = &id
+	}
+
+	return &CategorizationRule{
+		ID:             rule.ID.String(),
+		Priority:       rule.Priority,
+		MatchType:      MatchType(rule.MatchType),
+		Pattern:        rule.Pattern,
+		BudgetCategory: budgetCategory,
+		RewardCategory: rewardCategory,
+		PaymentMethod:  method,
+	}
+}
+
+func FieldChangesToExpenditureChanges(changes []*model.FieldChange) []*ExpenditureChange {
+	out := make([]*ExpenditureChange, len(changes))
+
+	for i, change := range changes {
+		out[i] = &ExpenditureChange{
+			ExpenditureID: strconv.Itoa(change.ExpenditureID),
+			Field:         change.Field,
+			Before:        change.Before,
+			After:         change.After,
+		}
+	}
+
+	return out
+}
+
+func SuggestionToCategorySuggestion(suggestion *classifier.Suggestion) *CategorySuggestion {
+	var budgetCategory, rewardCategory *string
+
+	if suggestion.BudgetCategory != "" {
+		budgetCategory = &suggestion.BudgetCategory
+	}
+
+	if suggestion.RewardCategory != "" {
+		rewardCategory = &suggestion.RewardCategory
+	}
+
+	return &CategorySuggestion{
+		Name:                     suggestion.Name,
+		BudgetCategory:           budgetCategory,
+		BudgetCategoryConfidence: suggestion.BudgetCategoryConfidence,
+		RewardCategory:           rewardCategory,
+		RewardCategoryConfidence: suggestion.RewardCategoryConfidence,
+	}
+}
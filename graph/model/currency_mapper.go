@@ -0,0 +1,20 @@
+package model
+
+import (
+	"time"
+	"yaba/internal/model"
+)
+
+func ExchangeRatesToExchangeRateResponses(rates []*model.ExchangeRate) []*ExchangeRate {
+	ret := make([]*ExchangeRate, len(rates))
+	for i, rate := range rates {
+		ret[i] = &ExchangeRate{
+			Date:  rate.Date.Format(time.DateOnly),
+			Base:  rate.Base,
+			Quote: rate.Quote,
+			Rate:  rate.Rate,
+		}
+	}
+
+	return ret
+}
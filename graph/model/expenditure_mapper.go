@@ -36,12 +36,60 @@ func ExpendituresFromExpenditureInput(user uuid.UUID, input []*ExpenditureInput)
 			RewardCategory: dereferenceOrEmpty(expenditure.RewardCategory),
 			Comment:        dereferenceOrEmpty(expenditure.Comment),
 			Source:         dereferenceOrEmpty(expenditure.Source),
+			Kind:           model.KindExpense,
+			Currency:       dereferenceOrEmpty(expenditure.Currency),
+		}
+
+		if expenditure.Kind != nil {
+			expenditures[i].Kind = ConvertKind(*expenditure.Kind)
 		}
 	}
 
 	return expenditures, nil
 }
 
+// ApplyExpenditureInput overwrites the date and amount of an existing expenditure and any optional
+// fields that are set in the input.
+func ApplyExpenditureInput(expenditure *model.Expenditure, input *ExpenditureInput) error {
+	date, err := time.ParseInLocation(time.DateOnly, input.Date, time.UTC)
+	if err != nil {
+		return fmt.Errorf("failed to parse date: %w", err)
+	}
+
+	expenditure.Date = date
+	expenditure.Amount = input.Amount
+
+	if input.Method != nil {
+		method := uuid.Nil
+		if *input.Method != "" {
+			if method, err = uuid.Parse(*input.Method); err != nil {
+				return fmt.Errorf("failed to parse UUID: %w", err)
+			}
+		}
+
+		expenditure.Method = method
+	}
+
+	setIfNotNil(&expenditure.Name, input.Name)
+	setIfNotNil(&expenditure.BudgetCategory, input.BudgetCategory)
+	setIfNotNil(&expenditure.RewardCategory, input.RewardCategory)
+	setIfNotNil(&expenditure.Comment, input.Comment)
+	setIfNotNil(&expenditure.Source, input.Source)
+	setIfNotNil(&expenditure.Currency, input.Currency)
+
+	if input.Kind != nil {
+		expenditure.Kind = ConvertKind(*input.Kind)
+	}
+
+	return nil
+}
+
+func setIfNotNil(dst *string, src *string) {
+	if src != nil {
+		*dst = *src
+	}
+}
+
 func dereferenceOrEmpty(ptr *string) string {
 	if ptr != nil {
 		return *ptr
@@ -54,33 +102,135 @@ func ExpendituresToExpenitureResponse(expenditures []*model.Expenditure) ([]*Exp
 	ret := make([]*ExpenditureResponse, len(expenditures))
 
 	for i, obj := range expenditures {
-		id := strconv.Itoa(obj.ID)
-		owner := obj.Owner.String()
-		amount := fmt.Sprintf("%.2f", obj.Amount)
-		date := obj.Date.Format(time.DateOnly)
-		cat := obj.RewardCategory
-		created := obj.CreatedTime.Format(time.DateOnly)
-
-		ret[i] = &ExpenditureResponse{
-			ID:             &id,
-			Owner:          &owner,
-			Name:           &obj.Name,
-			Amount:         &amount,
-			Date:           &date,
-			BudgetCategory: &obj.BudgetCategory,
-			RewardCategory: &cat,
-			Comment:        &obj.Comment,
-			Created:        &created,
-			Source:         &obj.Source,
+		ret[i] = ExpenditureToExpenditureResponse(obj)
+	}
+
+	return ret, nil
+}
+
+// ExpenditurePatchFromInput returns the change that the patch makes. An empty method removes the
+// payment method.
+func ExpenditurePatchFromInput(input *ExpenditurePatch) (*model.ExpenditurePatch, error) {
+	patch := &model.ExpenditurePatch{
+		BudgetCategory: input.BudgetCategory,
+		RewardCategory: input.RewardCategory,
+		Comment:        input.Comment,
+		Tags:           input.Tags,
+	}
+
+	if input.Method != nil {
+		method := uuid.Nil
+		if *input.Method != "" {
+			var err error
+			if method, err = uuid.Parse(*input.Method); err != nil {
+				return nil, fmt.Errorf("failed to parse UUID: %w", err)
+			}
 		}
 
-		if obj.Method != uuid.Nil {
-			v := obj.Method.String()
-			ret[i].Method = &v
+		patch.Method = &method
+	}
+
+	return patch, nil
+}
+
+// ExpenditurePageToExpenditureConnection returns the page with the cursor of each expenditure in the
+// sort order.
+func ExpenditurePageToExpenditureConnection(
+	page *model.ExpenditurePage,
+	sort model.ExpenditureSort,
+) *ExpenditureConnection {
+	connection := &ExpenditureConnection{
+		Edges:      make([]*ExpenditureEdge, len(page.Expenditures)),
+		PageInfo:   PageInfo{HasNextPage: page.HasNextPage},
+		TotalCount: page.TotalCount,
+	}
+
+	for i, expenditure := range page.Expenditures {
+		connection.Edges[i] = &ExpenditureEdge{
+			Cursor: model.NewExpenditureCursor(sort, expenditure).String(),
+			Node:   *ExpenditureToExpenditureResponse(expenditure),
 		}
 	}
 
-	return ret, nil
+	if len(connection.Edges) > 0 {
+		connection.PageInfo.EndCursor = &connection.Edges[len(connection.Edges)-1].Cursor
+	}
+
+	return connection
+}
+
+func ExpenditureToExpenditureResponse(obj *model.Expenditure) *ExpenditureResponse {
+	id := strconv.Itoa(obj.ID)
+	owner := obj.Owner.String()
+	date := obj.Date.Format(time.DateOnly)
+	cat := obj.RewardCategory
+	created := obj.CreatedTime.Format(time.DateOnly)
+
+	ret := &ExpenditureResponse{
+		ID:             &id,
+		Owner:          &owner,
+		Name:           &obj.Name,
+		Amount:         &obj.Amount,
+		Date:           &date,
+		BudgetCategory: &obj.BudgetCategory,
+		RewardCategory: &cat,
+		Comment:        &obj.Comment,
+		Created:        &created,
+		Source:         &obj.Source,
+		Currency:       &obj.Currency,
+	}
+
+	if obj.Method != uuid.Nil {
+		v := obj.Method.String()
+		ret.Method = &v
+	}
+
+	if obj.MerchantID != uuid.Nil {
+		v := obj.MerchantID.String()
+		ret.MerchantID = &v
+	}
+
+	if obj.RefundOf.Valid {
+		v := strconv.FormatInt(obj.RefundOf.Int64, 10)
+		ret.RefundOf = &v
+	}
+
+	if obj.Kind != "" {
+		kind := Kind(obj.Kind)
+		ret.Kind = &kind
+	}
+
+	if obj.TransferID.Valid {
+		v := strconv.FormatInt(obj.TransferID.Int64, 10)
+		ret.TransferID = &v
+	}
+
+	return ret
+}
+
+func ExpenditureSplitsFromExpenditureSplitInput(input []*ExpenditureSplitInput) []*model.ExpenditureSplit {
+	splits := make([]*model.ExpenditureSplit, len(input))
+	for i, split := range input {
+		splits[i] = &model.ExpenditureSplit{
+			BudgetCategory: split.BudgetCategory,
+			Amount:         split.Amount,
+		}
+	}
+
+	return splits
+}
+
+func ExpenditureSplitsToExpenditureSplitResponses(splits []*model.ExpenditureSplit) []*ExpenditureSplit {
+	ret := make([]*ExpenditureSplit, len(splits))
+	for i, split := range splits {
+		ret[i] = &ExpenditureSplit{
+			ID:             strconv.Itoa(split.ID),
+			BudgetCategory: split.BudgetCategory,
+			Amount:         split.Amount,
+		}
+	}
+
+	return ret
 }
 
 func ExpenditureSummariesToAggregateExpenditures(
@@ -96,6 +246,7 @@ func ExpenditureSummariesToAggregateExpenditures(
 			Amount:          &obj.Amount,
 			SpanStart:       &start,
 			Span:            &timespan,
+			Unconverted:     obj.Unconverted,
 		}
 	}
 
@@ -135,7 +286,50 @@ func ConvertGroupBy(groupBy GroupBy) model.GroupBy {
 		return model.GroupByBudgetCategory
 	case GroupByRewardCategory:
 		return model.GroupByRewardCategory
+	case GroupByMerchant:
+		return model.GroupByMerchant
+	case GroupByTag:
+		return model.GroupByTag
 	default:
 		return model.GroupByNone
 	}
 }
+
+func ConvertExpenditureSort(sort ExpenditureSort) model.ExpenditureSort {
+	switch sort {
+	case ExpenditureSortDate:
+		return model.ExpenditureSortDate
+	case ExpenditureSortAmount:
+		return model.ExpenditureSortAmount
+	case ExpenditureSortName:
+		return model.ExpenditureSortName
+	case ExpenditureSortCreated:
+		return model.ExpenditureSortCreated
+	default:
+		return model.ExpenditureSortDate
+	}
+}
+
+func ConvertRefundAttribution(refunds RefundAttribution) model.RefundAttribution {
+	switch refunds {
+	case RefundAttributionOriginal:
+		return model.RefundAttributionOriginal
+	case RefundAttributionRefundDate:
+		return model.RefundAttributionRefundDate
+	default:
+		return model.RefundAttributionOriginal
+	}
+}
+
+func ConvertKind(kind Kind) model.Kind {
+	switch kind {
+	case KindExpense:
+		return model.KindExpense
+	case KindIncome:
+		return model.KindIncome
+	case KindTransfer:
+		return model.KindTransfer
+	default:
+		return model.KindExpense
+	}
+}
@@ -0,0 +1,37 @@
+package model
+
+import (
+	"net/url"
+	"strings"
+)
+
+// ExportURL is the path that exports are downloaded from. Its query takes the format and the fields
+// of an ExpenditureFilter, with a "tag" parameter for each tag and "filter" for the search.
+const ExportURL = "/api/export"
+
+// ExportURLFromFilter returns the URL that downloads the expenditures that match the filter.
+func ExportURLFromFilter(filter *ExpenditureFilter, format ExportFormat) string {
+	query := url.Values{}
+	query.Set("format", strings.ToLower(string(format)))
+
+	if filter != nil {
+		setIfNotNil := func(key string, value *string) {
+			if value != nil {
+				query.Set(key, *value)
+			}
+		}
+
+		setIfNotNil("filter", filter.Search)
+		setIfNotNil("category", filter.Category)
+		setIfNotNil("paymentMethod", filter.PaymentMethod)
+		setIfNotNil("source", filter.Source)
+		setIfNotNil("since", filter.Since)
+		setIfNotNil("until", filter.Until)
+
+		for _, tag := range filter.Tags {
+			query.Add("tag", tag)
+		}
+	}
+
+	return ExportURL + "?" + query.Encode()
+}
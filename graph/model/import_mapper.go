@@ -0,0 +1,116 @@
+package model
+
+import (
+	"fmt"
+	"time"
+	"unicode/utf8"
+	"yaba/errors"
+	"yaba/internal/importer"
+	"yaba/internal/model"
+
+	"github.com/google/uuid"
+)
+
+// CSVMappingFromCSVMappingInput overlays the input onto the default CSV mapping.
+func CSVMappingFromCSVMappingInput(input *CSVMappingInput) (*importer.CSVMapping, error) {
+	mapping := importer.DefaultCSVMapping()
+	if input == nil {
+		return mapping, nil
+	}
+
+	setIfNotNil(&mapping.Date, input.Date)
+	setIfNotNil(&mapping.Amount, input.Amount)
+	setIfNotNil(&mapping.Debit, input.Debit)
+	setIfNotNil(&mapping.Credit, input.Credit)
+	setIfNotNil(&mapping.Name, input.Name)
+	setIfNotNil(&mapping.Method, input.Method)
+	setIfNotNil(&mapping.BudgetCategory, input.BudgetCategory)
+	setIfNotNil(&mapping.RewardCategory, input.RewardCategory)
+	setIfNotNil(&mapping.Comment, input.Comment)
+	setIfNotNil(&mapping.Currency, input.Currency)
+	setIfNotNil(&mapping.Kind, input.Kind)
+	setIfNotNil(&mapping.DateFormat, input.DateFormat)
+
+	// Debit and credit columns replace the amount column unless it was explicitly mapped.
+	if input.Amount == nil && (input.Debit != nil || input.Credit != nil) {
+		mapping.Amount = ""
+	}
+
+	if input.SignConvention != nil {
+		mapping.Sign = importer.SignConvention(*input.SignConvention)
+	}
+
+	if input.Delimiter != nil {
+		if utf8.RuneCountInString(*input.Delimiter) != 1 {
+			return nil, errors.InvalidInputError{Input: "delimiter " + *input.Delimiter}
+		}
+
+		mapping.Delimiter, _ = utf8.DecodeRuneInString(*input.Delimiter)
+	}
+
+	if input.HasHeader != nil {
+		mapping.HasHeader = *input.HasHeader
+	}
+
+	return mapping, nil
+}
+
+// PaymentMethodFromCSVMappingInput returns the payment method assigned to rows without one.
+func PaymentMethodFromCSVMappingInput(input *CSVMappingInput) (uuid.UUID, error) {
+	if input == nil || input.PaymentMethod == nil || *input.PaymentMethod == "" {
+		return uuid.Nil, nil
+	}
+
+	method, err := uuid.Parse(*input.PaymentMethod)
+	if err != nil {
+		return uuid.Nil, fmt.Errorf("failed to parse UUID: %w", err)
+	}
+
+	return method, nil
+}
+
+func ImportResultToImportResultResponse(result *model.ImportResult) *ImportResult {
+	duplicates := make([]*PossibleDuplicate, len(result.PossibleDuplicates))
+
+	for i, duplicate := range result.PossibleDuplicates {
+		matches := make([]*ExpenditureResponse, len(duplicate.Matches))
+		for j, match := range duplicate.Matches {
+			matches[j] = ExpenditureToExpenditureResponse(match)
+		}
+
+		duplicates[i] = &PossibleDuplicate{
+			Expenditure: *ExpenditureToExpenditureResponse(duplicate.Expenditure),
+			Matches:     matches,
+		}
+	}
+
+	return &ImportResult{
+		Batch:              *ImportBatchToImportBatchResponse(result.Batch),
+		Inserted:           len(result.Inserted),
+		Skipped:            result.Skipped,
+		PossibleDuplicates: duplicates,
+	}
+}
+
+func ImportBatchToImportBatchResponse(batch *model.ImportBatch) *ImportBatch {
+	var start, end *string
+
+	if batch.StartDate.Valid {
+		date := batch.StartDate.Time.Format(time.DateOnly)
+		start = &date
+	}
+
+	if batch.EndDate.Valid {
+		date := batch.EndDate.Time.Format(time.DateOnly)
+		end = &date
+	}
+
+	return &ImportBatch{
+		ID:        batch.ID.String(),
+		Source:    batch.Source,
+		RowCount:  batch.RowCount,
+		StartDate: start,
+		EndDate:   end,
+		Created:   batch.CreatedTime.Format(time.RFC3339),
+	}
+}
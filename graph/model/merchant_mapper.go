@@ -0,0 +1,20 @@
+package model
+
+import "yaba/internal/model"
+
+func MerchantToMerchantResponse(merchant *model.Merchant) *Merchant {
+	return &Merchant{
+		ID:      merchant.ID.String(),
+		Name:    merchant.Name,
+		Aliases: merchant.Aliases,
+	}
+}
+
+func MerchantsToMerchantResponses(merchants []*model.Merchant) []*Merchant {
+	ret := make([]*Merchant, len(merchants))
+	for i, merchant := range merchants {
+		ret[i] = MerchantToMerchantResponse(merchant)
+	}
+
+	return ret
+}
@@ -6,81 +6,289 @@ import (
 	"fmt"
 	"io"
 	"strconv"
+	"yaba/internal/model"
 )
 
 type AggregatedExpendituresResponse struct {
-	GroupByCategory *string   `json:"groupByCategory,omitempty"`
-	Amount          *float64  `json:"amount,omitempty"`
-	SpanStart       *string   `json:"spanStart,omitempty"`
-	Span            *Timespan `json:"span,omitempty"`
+	GroupByCategory *string      `json:"groupByCategory,omitempty"`
+	Amount          *model.Money `json:"amount,omitempty"`
+	SpanStart       *string      `json:"spanStart,omitempty"`
+	Span            *Timespan    `json:"span,omitempty"`
+	Unconverted     int          `json:"unconverted"`
+}
+
+type Attachment struct {
+	ID          string `json:"id"`
+	Filename    string `json:"filename"`
+	ContentType string `json:"contentType"`
+	Size        int    `json:"size"`
+	Created     string `json:"created"`
+	URL         string `json:"url"`
+}
+
+type AuditEntry struct {
+	ID            string      `json:"id"`
+	EntityType    string      `json:"entityType"`
+	EntityID      string      `json:"entityId"`
+	Action        AuditAction `json:"action"`
+	Before        *string     `json:"before,omitempty"`
+	After         *string     `json:"after,omitempty"`
+	Changed       string      `json:"changed"`
+	TransactionID string      `json:"transactionId"`
+}
+
+type BudgetConnection struct {
+	Edges      []*BudgetEdge `json:"edges"`
+	PageInfo   PageInfo      `json:"pageInfo"`
+	TotalCount int           `json:"totalCount"`
+}
+
+type BudgetEdge struct {
+	Cursor string         `json:"cursor"`
+	Node   BudgetResponse `json:"node"`
+}
+
+type BudgetPeriod struct {
+	BudgetID string                 `json:"budgetId"`
+	Start    string                 `json:"start"`
+	End      string                 `json:"end"`
+	Expenses []*BudgetPeriodExpense `json:"expenses"`
+}
+
+type BudgetPeriodExpense struct {
+	ExpenseID     string      `json:"expenseId"`
+	Category      string      `json:"category"`
+	Allocated     model.Money `json:"allocated"`
+	HomeAllocated model.Money `json:"homeAllocated"`
+	Spent         model.Money `json:"spent"`
+	RolledOver    model.Money `json:"rolledOver"`
+	Remaining     model.Money `json:"remaining"`
+	Rollover      bool        `json:"rollover"`
+	Currency      string      `json:"currency"`
 }
 
 type BudgetResponse struct {
-	ID       *string            `json:"id,omitempty"`
-	Owner    *string            `json:"owner,omitempty"`
-	Name     *string            `json:"name,omitempty"`
-	Incomes  []*IncomeResponse  `json:"incomes,omitempty"`
-	Expenses []*ExpenseResponse `json:"expenses,omitempty"`
+	ID          *string            `json:"id,omitempty"`
+	Owner       *string            `json:"owner,omitempty"`
+	Name        *string            `json:"name,omitempty"`
+	Incomes     []*IncomeResponse  `json:"incomes,omitempty"`
+	Expenses    []*ExpenseResponse `json:"expenses,omitempty"`
+	Period      *BudgetPeriodType  `json:"period,omitempty"`
+	PeriodStart *string            `json:"periodStart,omitempty"`
+	PeriodDays  *int               `json:"periodDays,omitempty"`
+}
+
+type BulkUpdateResult struct {
+	Affected int                  `json:"affected"`
+	Preview  []*ExpenditureChange `json:"preview"`
+}
+
+type CategorizationRule struct {
+	ID             string    `json:"id"`
+	Priority       int       `json:"priority"`
+	MatchType      MatchType `json:"matchType"`
+	Pattern        string    `json:"pattern"`
+	BudgetCategory *string   `json:"budgetCategory,omitempty"`
+	RewardCategory *string   `json:"rewardCategory,omitempty"`
+	PaymentMethod  *string   `json:"paymentMethod,omitempty"`
+}
+
+type CategorizationRuleInput struct {
+	Priority       *int       `json:"priority,omitempty"`
+	MatchType      *MatchType `json:"matchType,omitempty"`
+	Pattern        string     `json:"pattern"`
+	BudgetCategory *string    `json:"budgetCategory,omitempty"`
+	RewardCategory *string    `json:"rewardCategory,omitempty"`
+	PaymentMethod  *string    `json:"paymentMethod,omitempty"`
+}
+
+type CategorySuggestion struct {
+	Name                     string  `json:"name"`
+	BudgetCategory           *string `json:"budgetCategory,omitempty"`
+	BudgetCategoryConfidence float64 `json:"budgetCategoryConfidence"`
+	RewardCategory           *string `json:"rewardCategory,omitempty"`
+	RewardCategoryConfidence float64 `json:"rewardCategoryConfidence"`
+}
+
+type CSVMappingInput struct {
+	Date           *string         `json:"date,omitempty"`
+	Amount         *string         `json:"amount,omitempty"`
+	Debit          *string         `json:"debit,omitempty"`
+	Credit         *string         `json:"credit,omitempty"`
+	Name           *string         `json:"name,omitempty"`
+	Method         *string         `json:"method,omitempty"`
+	BudgetCategory *string         `json:"budget_category,omitempty"`
+	RewardCategory *string         `json:"reward_category,omitempty"`
+	Comment        *string         `json:"comment,omitempty"`
+	Currency       *string         `json:"currency,omitempty"`
+	Kind           *string         `json:"kind,omitempty"`
+	DateFormat     *string         `json:"dateFormat,omitempty"`
+	SignConvention *SignConvention `json:"signConvention,omitempty"`
+	Delimiter      *string         `json:"delimiter,omitempty"`
+	HasHeader      *bool           `json:"hasHeader,omitempty"`
+	PaymentMethod  *string         `json:"paymentMethod,omitempty"`
+}
+
+type ExchangeRate struct {
+	Date  string  `json:"date"`
+	Base  string  `json:"base"`
+	Quote string  `json:"quote"`
+	Rate  float64 `json:"rate"`
+}
+
+type ExpenditureChange struct {
+	ExpenditureID string `json:"expenditureId"`
+	Field         string `json:"field"`
+	Before        string `json:"before"`
+	After         string `json:"after"`
+}
+
+type ExpenditureConnection struct {
+	Edges      []*ExpenditureEdge `json:"edges"`
+	PageInfo   PageInfo           `json:"pageInfo"`
+	TotalCount int                `json:"totalCount"`
+}
+
+type ExpenditureEdge struct {
+	Cursor string              `json:"cursor"`
+	Node   ExpenditureResponse `json:"node"`
+}
+
+type ExpenditureFilter struct {
+	Search        *string  `json:"search,omitempty"`
+	Category      *string  `json:"category,omitempty"`
+	PaymentMethod *string  `json:"paymentMethod,omitempty"`
+	Source        *string  `json:"source,omitempty"`
+	Tags          []string `json:"tags,omitempty"`
+	Since         *string  `json:"since,omitempty"`
+	Until         *string  `json:"until,omitempty"`
 }
 
 type ExpenditureInput struct {
-	Date           string  `json:"date"`
-	Amount         float64 `json:"amount"`
-	Name           *string `json:"name,omitempty"`
-	Method         *string `json:"method,omitempty"`
-	BudgetCategory *string `json:"budget_category,omitempty"`
-	RewardCategory *string `json:"reward_category,omitempty"`
-	Comment        *string `json:"comment,omitempty"`
-	Source         *string `json:"source,omitempty"`
+	Date           string      `json:"date"`
+	Amount         model.Money `json:"amount"`
+	Name           *string     `json:"name,omitempty"`
+	Method         *string     `json:"method,omitempty"`
+	BudgetCategory *string     `json:"budget_category,omitempty"`
+	RewardCategory *string     `json:"reward_category,omitempty"`
+	Comment        *string     `json:"comment,omitempty"`
+	Source         *string     `json:"source,omitempty"`
+	Kind           *Kind       `json:"kind,omitempty"`
+	Currency       *string     `json:"currency,omitempty"`
+}
+
+type ExpenditurePatch struct {
+	BudgetCategory *string  `json:"budget_category,omitempty"`
+	RewardCategory *string  `json:"reward_category,omitempty"`
+	Method         *string  `json:"method,omitempty"`
+	Comment        *string  `json:"comment,omitempty"`
+	Tags           []string `json:"tags,omitempty"`
 }
 
 type ExpenditureResponse struct {
-	ID             *string `json:"id,omitempty"`
-	Owner          *string `json:"owner,omitempty"`
-	Name           *string `json:"name,omitempty"`
-	Amount         *string `json:"amount,omitempty"`
-	Date           *string `json:"date,omitempty"`
-	Method         *string `json:"method,omitempty"`
-	BudgetCategory *string `json:"budget_category,omitempty"`
-	RewardCategory *string `json:"reward_category,omitempty"`
-	Comment        *string `json:"comment,omitempty"`
-	Created        *string `json:"created,omitempty"`
-	Source         *string `json:"source,omitempty"`
+	ID             *string             `json:"id,omitempty"`
+	Owner          *string             `json:"owner,omitempty"`
+	Name           *string             `json:"name,omitempty"`
+	Amount         *model.Money        `json:"amount,omitempty"`
+	Date           *string             `json:"date,omitempty"`
+	Method         *string             `json:"method,omitempty"`
+	BudgetCategory *string             `json:"budget_category,omitempty"`
+	RewardCategory *string             `json:"reward_category,omitempty"`
+	Comment        *string             `json:"comment,omitempty"`
+	Created        *string             `json:"created,omitempty"`
+	Source         *string             `json:"source,omitempty"`
+	MerchantID     *string             `json:"merchant_id,omitempty"`
+	RefundOf       *string             `json:"refund_of,omitempty"`
+	Kind           *Kind               `json:"kind,omitempty"`
+	TransferID     *string             `json:"transfer_id,omitempty"`
+	Currency       *string             `json:"currency,omitempty"`
+	HomeAmount     *model.Money        `json:"homeAmount,omitempty"`
+	Splits         []*ExpenditureSplit `json:"splits"`
+	Tags           []*Tag              `json:"tags"`
+	Attachments    []*Attachment       `json:"attachments"`
+}
+
+type ExpenditureSplit struct {
+	ID             string      `json:"id"`
+	BudgetCategory string      `json:"budget_category"`
+	Amount         model.Money `json:"amount"`
+}
+
+type ExpenditureSplitInput struct {
+	BudgetCategory string      `json:"budget_category"`
+	Amount         model.Money `json:"amount"`
 }
 
 type ExpenseInput struct {
-	Category string  `json:"category"`
-	Amount   float64 `json:"amount"`
-	IsFixed  *bool   `json:"isFixed,omitempty"`
-	IsSlack  *bool   `json:"isSlack,omitempty"`
-	ID       *string `json:"id,omitempty"`
+	Category string      `json:"category"`
+	Amount   model.Money `json:"amount"`
+	IsFixed  *bool       `json:"isFixed,omitempty"`
+	IsSlack  *bool       `json:"isSlack,omitempty"`
+	ID       *string     `json:"id,omitempty"`
+	Currency *string     `json:"currency,omitempty"`
+	Rollover *bool       `json:"rollover,omitempty"`
 }
 
 type ExpenseResponse struct {
-	Category *string  `json:"category,omitempty"`
-	Amount   *float64 `json:"amount,omitempty"`
-	IsFixed  *bool    `json:"isFixed,omitempty"`
-	IsSlack  *bool    `json:"isSlack,omitempty"`
-	ID       *string  `json:"id,omitempty"`
+	Category *string      `json:"category,omitempty"`
+	Amount   *model.Money `json:"amount,omitempty"`
+	IsFixed  *bool        `json:"isFixed,omitempty"`
+	IsSlack  *bool        `json:"isSlack,omitempty"`
+	ID       *string      `json:"id,omitempty"`
+	Currency *string      `json:"currency,omitempty"`
+	Rollover *bool        `json:"rollover,omitempty"`
+}
+
+type ImportBatch struct {
+	ID           string                 `json:"id"`
+	Source       string                 `json:"source"`
+	RowCount     int                    `json:"rowCount"`
+	StartDate    *string                `json:"startDate,omitempty"`
+	EndDate      *string                `json:"endDate,omitempty"`
+	Created      string                 `json:"created"`
+	Expenditures []*ExpenditureResponse `json:"expenditures"`
+}
+
+type ImportResult struct {
+	Batch              ImportBatch          `json:"batch"`
+	Inserted           int                  `json:"inserted"`
+	Skipped            int                  `json:"skipped"`
+	PossibleDuplicates []*PossibleDuplicate `json:"possibleDuplicates"`
 }
 
 type IncomeInput struct {
-	Source string  `json:"source"`
-	Amount float64 `json:"amount"`
+	Source   string      `json:"source"`
+	Amount   model.Money `json:"amount"`
+	Currency *string     `json:"currency,omitempty"`
 }
 
 type IncomeResponse struct {
-	Source *string  `json:"source,omitempty"`
-	Amount *float64 `json:"amount,omitempty"`
+	Source   *string      `json:"source,omitempty"`
+	Amount   *model.Money `json:"amount,omitempty"`
+	Currency *string      `json:"currency,omitempty"`
+}
+
+type Merchant struct {
+	ID      string   `json:"id"`
+	Name    string   `json:"name"`
+	Aliases []string `json:"aliases"`
 }
 
 type Mutation struct {
 }
 
 type NewBudgetInput struct {
-	Name     string          `json:"name"`
-	Incomes  []*IncomeInput  `json:"incomes,omitempty"`
-	Expenses []*ExpenseInput `json:"expenses,omitempty"`
+	Name        string            `json:"name"`
+	Incomes     []*IncomeInput    `json:"incomes,omitempty"`
+	Expenses    []*ExpenseInput   `json:"expenses,omitempty"`
+	Period      *BudgetPeriodType `json:"period,omitempty"`
+	PeriodStart *string           `json:"periodStart,omitempty"`
+	PeriodDays  *int              `json:"periodDays,omitempty"`
+}
+
+type PageInfo struct {
+	HasNextPage bool    `json:"hasNextPage"`
+	EndCursor   *string `json:"endCursor,omitempty"`
 }
 
 type PaymentMethod struct {
@@ -89,6 +297,7 @@ type PaymentMethod struct {
 	AcquiredDate *string     `json:"acquiredDate,omitempty"`
 	CancelByDate *string     `json:"cancelByDate,omitempty"`
 	CardType     string      `json:"cardType"`
+	AccountID    *string     `json:"accountId,omitempty"`
 	Rewards      *RewardCard `json:"rewards,omitempty"`
 }
 
@@ -97,11 +306,31 @@ type PaymentMethodInput struct {
 	AcquiredDate *string `json:"acquiredDate,omitempty"`
 	CancelByDate *string `json:"cancelByDate,omitempty"`
 	CardType     *string `json:"cardType,omitempty"`
+	AccountID    *string `json:"accountId,omitempty"`
+}
+
+type PossibleDuplicate struct {
+	Expenditure ExpenditureResponse    `json:"expenditure"`
+	Matches     []*ExpenditureResponse `json:"matches"`
 }
 
 type Query struct {
 }
 
+type RecurringExpenditure struct {
+	ID             string      `json:"id"`
+	MerchantID     string      `json:"merchantId"`
+	Name           string      `json:"name"`
+	Cadence        Cadence     `json:"cadence"`
+	Amount         model.Money `json:"amount"`
+	PreviousAmount model.Money `json:"previousAmount"`
+	Occurrences    int         `json:"occurrences"`
+	LastDate       string      `json:"lastDate"`
+	NextDate       string      `json:"nextDate"`
+	Missed         bool        `json:"missed"`
+	PriceChanged   bool        `json:"priceChanged"`
+}
+
 type RewardCard struct {
 	ID         string            `json:"id"`
 	Name       string            `json:"name"`
@@ -130,11 +359,26 @@ type RewardCategoryInput struct {
 	Rate     float64 `json:"rate"`
 }
 
+type Tag struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+type TrashItem struct {
+	ID        string    `json:"id"`
+	Type      TrashType `json:"type"`
+	Name      string    `json:"name"`
+	DeletedAt string    `json:"deletedAt"`
+}
+
 type UpdateBudgetInput struct {
-	ID       string          `json:"id"`
-	Name     *string         `json:"name,omitempty"`
-	Incomes  []*IncomeInput  `json:"incomes,omitempty"`
-	Expenses []*ExpenseInput `json:"expenses,omitempty"`
+	ID          string            `json:"id"`
+	Name        *string           `json:"name,omitempty"`
+	Incomes     []*IncomeInput    `json:"incomes,omitempty"`
+	Expenses    []*ExpenseInput   `json:"expenses,omitempty"`
+	Period      *BudgetPeriodType `json:"period,omitempty"`
+	PeriodStart *string           `json:"periodStart,omitempty"`
+	PeriodDays  *int              `json:"periodDays,omitempty"`
 }
 
 type Aggregation string
@@ -178,23 +422,244 @@ func (e Aggregation) MarshalGQL(w io.Writer) {
 	fmt.Fprint(w, strconv.Quote(e.String()))
 }
 
+type AuditAction string
+
+const (
+	AuditActionInsert AuditAction = "INSERT"
+	AuditActionUpdate AuditAction = "UPDATE"
+	AuditActionDelete AuditAction = "DELETE"
+)
+
+var AllAuditAction = []AuditAction{
+	AuditActionInsert,
+	AuditActionUpdate,
+	AuditActionDelete,
+}
+
+func (e AuditAction) IsValid() bool {
+	switch e {
+	case AuditActionInsert, AuditActionUpdate, AuditActionDelete:
+		return true
+	}
+	return false
+}
+
+func (e AuditAction) String() string {
+	return string(e)
+}
+
+func (e *AuditAction) UnmarshalGQL(v any) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = AuditAction(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid AuditAction", str)
+	}
+	return nil
+}
+
+func (e AuditAction) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+type BudgetPeriodType string
+
+const (
+	BudgetPeriodTypeMonthly  BudgetPeriodType = "MONTHLY"
+	BudgetPeriodTypeBiweekly BudgetPeriodType = "BIWEEKLY"
+	BudgetPeriodTypeCustom   BudgetPeriodType = "CUSTOM"
+)
+
+var AllBudgetPeriodType = []BudgetPeriodType{
+	BudgetPeriodTypeMonthly,
+	BudgetPeriodTypeBiweekly,
+	BudgetPeriodTypeCustom,
+}
+
+func (e BudgetPeriodType) IsValid() bool {
+	switch e {
+	case BudgetPeriodTypeMonthly, BudgetPeriodTypeBiweekly, BudgetPeriodTypeCustom:
+		return true
+	}
+	return false
+}
+
+func (e BudgetPeriodType) String() string {
+	return string(e)
+}
+
+func (e *BudgetPeriodType) UnmarshalGQL(v any) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = BudgetPeriodType(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid BudgetPeriodType", str)
+	}
+	return nil
+}
+
+func (e BudgetPeriodType) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+type Cadence string
+
+const (
+	CadenceWeekly  Cadence = "WEEKLY"
+	CadenceMonthly Cadence = "MONTHLY"
+	CadenceYearly  Cadence = "YEARLY"
+)
+
+var AllCadence = []Cadence{
+	CadenceWeekly,
+	CadenceMonthly,
+	CadenceYearly,
+}
+
+func (e Cadence) IsValid() bool {
+	switch e {
+	case CadenceWeekly, CadenceMonthly, CadenceYearly:
+		return true
+	}
+	return false
+}
+
+func (e Cadence) String() string {
+	return string(e)
+}
+
+func (e *Cadence) UnmarshalGQL(v any) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = Cadence(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid Cadence", str)
+	}
+	return nil
+}
+
+func (e Cadence) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+type ExpenditureSort string
+
+const (
+	ExpenditureSortDate    ExpenditureSort = "DATE"
+	ExpenditureSortAmount  ExpenditureSort = "AMOUNT"
+	ExpenditureSortName    ExpenditureSort = "NAME"
+	ExpenditureSortCreated ExpenditureSort = "CREATED"
+)
+
+var AllExpenditureSort = []ExpenditureSort{
+	ExpenditureSortDate,
+	ExpenditureSortAmount,
+	ExpenditureSortName,
+	ExpenditureSortCreated,
+}
+
+func (e ExpenditureSort) IsValid() bool {
+	switch e {
+	case ExpenditureSortDate, ExpenditureSortAmount, ExpenditureSortName, ExpenditureSortCreated:
+		return true
+	}
+	return false
+}
+
+func (e ExpenditureSort) String() string {
+	return string(e)
+}
+
+func (e *ExpenditureSort) UnmarshalGQL(v any) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = ExpenditureSort(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid ExpenditureSort", str)
+	}
+	return nil
+}
+
+func (e ExpenditureSort) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+type ExportFormat string
+
+const (
+	ExportFormatCSV    ExportFormat = "CSV"
+	ExportFormatNdjson ExportFormat = "NDJSON"
+	ExportFormatOfx    ExportFormat = "OFX"
+)
+
+var AllExportFormat = []ExportFormat{
+	ExportFormatCSV,
+	ExportFormatNdjson,
+	ExportFormatOfx,
+}
+
+func (e ExportFormat) IsValid() bool {
+	switch e {
+	case ExportFormatCSV, ExportFormatNdjson, ExportFormatOfx:
+		return true
+	}
+	return false
+}
+
+func (e ExportFormat) String() string {
+	return string(e)
+}
+
+func (e *ExportFormat) UnmarshalGQL(v any) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = ExportFormat(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid ExportFormat", str)
+	}
+	return nil
+}
+
+func (e ExportFormat) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
 type GroupBy string
 
 const (
 	GroupByNone           GroupBy = "NONE"
 	GroupByBudgetCategory GroupBy = "BUDGET_CATEGORY"
 	GroupByRewardCategory GroupBy = "REWARD_CATEGORY"
+	GroupByMerchant       GroupBy = "MERCHANT"
+	GroupByTag            GroupBy = "TAG"
 )
 
 var AllGroupBy = []GroupBy{
 	GroupByNone,
 	GroupByBudgetCategory,
 	GroupByRewardCategory,
+	GroupByMerchant,
+	GroupByTag,
 }
 
 func (e GroupBy) IsValid() bool {
 	switch e {
-	case GroupByNone, GroupByBudgetCategory, GroupByRewardCategory:
+	case GroupByNone, GroupByBudgetCategory, GroupByRewardCategory, GroupByMerchant, GroupByTag:
 		return true
 	}
 	return false
@@ -221,6 +686,217 @@ func (e GroupBy) MarshalGQL(w io.Writer) {
 	fmt.Fprint(w, strconv.Quote(e.String()))
 }
 
+type Kind string
+
+const (
+	KindExpense  Kind = "EXPENSE"
+	KindIncome   Kind = "INCOME"
+	KindTransfer Kind = "TRANSFER"
+)
+
+var AllKind = []Kind{
+	KindExpense,
+	KindIncome,
+	KindTransfer,
+}
+
+func (e Kind) IsValid() bool {
+	switch e {
+	case KindExpense, KindIncome, KindTransfer:
+		return true
+	}
+	return false
+}
+
+func (e Kind) String() string {
+	return string(e)
+}
+
+func (e *Kind) UnmarshalGQL(v any) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = Kind(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid Kind", str)
+	}
+	return nil
+}
+
+func (e Kind) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+type MatchType string
+
+const (
+	MatchTypeContains   MatchType = "CONTAINS"
+	MatchTypeEquals     MatchType = "EQUALS"
+	MatchTypeStartsWith MatchType = "STARTS_WITH"
+	MatchTypeRegex      MatchType = "REGEX"
+)
+
+var AllMatchType = []MatchType{
+	MatchTypeContains,
+	MatchTypeEquals,
+	MatchTypeStartsWith,
+	MatchTypeRegex,
+}
+
+func (e MatchType) IsValid() bool {
+	switch e {
+	case MatchTypeContains, MatchTypeEquals, MatchTypeStartsWith, MatchTypeRegex:
+		return true
+	}
+	return false
+}
+
+func (e MatchType) String() string {
+	return string(e)
+}
+
+func (e *MatchType) UnmarshalGQL(v any) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = MatchType(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid MatchType", str)
+	}
+	return nil
+}
+
+func (e MatchType) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+type RefundAttribution string
+
+const (
+	RefundAttributionOriginal   RefundAttribution = "ORIGINAL"
+	RefundAttributionRefundDate RefundAttribution = "REFUND_DATE"
+)
+
+var AllRefundAttribution = []RefundAttribution{
+	RefundAttributionOriginal,
+	RefundAttributionRefundDate,
+}
+
+func (e RefundAttribution) IsValid() bool {
+	switch e {
+	case RefundAttributionOriginal, RefundAttributionRefundDate:
+		return true
+	}
+	return false
+}
+
+func (e RefundAttribution) String() string {
+	return string(e)
+}
+
+func (e *RefundAttribution) UnmarshalGQL(v any) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = RefundAttribution(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid RefundAttribution", str)
+	}
+	return nil
+}
+
+func (e RefundAttribution) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+type SignConvention string
+
+const (
+	SignConventionExpensesPositive SignConvention = "EXPENSES_POSITIVE"
+	SignConventionExpensesNegative SignConvention = "EXPENSES_NEGATIVE"
+)
+
+var AllSignConvention = []SignConvention{
+	SignConventionExpensesPositive,
+	SignConventionExpensesNegative,
+}
+
+func (e SignConvention) IsValid() bool {
+	switch e {
+	case SignConventionExpensesPositive, SignConventionExpensesNegative:
+		return true
+	}
+	return false
+}
+
+func (e SignConvention) String() string {
+	return string(e)
+}
+
+func (e *SignConvention) UnmarshalGQL(v any) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = SignConvention(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid SignConvention", str)
+	}
+	return nil
+}
+
+func (e SignConvention) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+type SortDirection string
+
+const (
+	SortDirectionAsc  SortDirection = "ASC"
+	SortDirectionDesc SortDirection = "DESC"
+)
+
+var AllSortDirection = []SortDirection{
+	SortDirectionAsc,
+	SortDirectionDesc,
+}
+
+func (e SortDirection) IsValid() bool {
+	switch e {
+	case SortDirectionAsc, SortDirectionDesc:
+		return true
+	}
+	return false
+}
+
+func (e SortDirection) String() string {
+	return string(e)
+}
+
+func (e *SortDirection) UnmarshalGQL(v any) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = SortDirection(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid SortDirection", str)
+	}
+	return nil
+}
+
+func (e SortDirection) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
 type Timespan string
 
 const (
@@ -265,3 +941,46 @@ func (e *Timespan) UnmarshalGQL(v any) error {
 func (e Timespan) MarshalGQL(w io.Writer) {
 	fmt.Fprint(w, strconv.Quote(e.String()))
 }
+
+type TrashType string
+
+const (
+	TrashTypeBudget        TrashType = "BUDGET"
+	TrashTypePaymentMethod TrashType = "PAYMENT_METHOD"
+	TrashTypeExpenditure   TrashType = "EXPENDITURE"
+)
+
+var AllTrashType = []TrashType{
+	TrashTypeBudget,
+	TrashTypePaymentMethod,
+	TrashTypeExpenditure,
+}
+
+func (e TrashType) IsValid() bool {
+	switch e {
+	case TrashTypeBudget, TrashTypePaymentMethod, TrashTypeExpenditure:
+		return true
+	}
+	return false
+}
+
+func (e TrashType) String() string {
+	return string(e)
+}
+
+func (e *TrashType) UnmarshalGQL(v any) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = TrashType(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid TrashType", str)
+	}
+	return nil
+}
+
+func (e TrashType) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
@@ -29,12 +29,18 @@ func PaymentMethodToPaymentMethodResponse(pm *model.PaymentMethod) *PaymentMetho
 		cancel = &date
 	}
 
+	var accountID *string
+	if pm.AccountID != "" {
+		accountID = &pm.AccountID
+	}
+
 	return &PaymentMethod{
 		ID:           pm.ID.String(),
 		DisplayName:  pm.DisplayName,
 		AcquiredDate: acquired,
 		CancelByDate: cancel,
 		CardType:     pm.CardType.String(),
+		AccountID:    accountID,
 		Rewards:      RewardCardToRewardCardResponse(pm.Rewards),
 	}
 }
@@ -86,6 +92,7 @@ func PaymentMethodFromPaymentMethodInput(
 			Time:  cancel,
 			Valid: input.CancelByDate != nil,
 		},
-		CardType: cardType,
+		CardType:  cardType,
+		AccountID: dereferenceOrEmpty(input.AccountID),
 	}, nil
 }
@@ -0,0 +1,27 @@
+package model
+
+import (
+	"time"
+	"yaba/internal/model"
+)
+
+func RecurringSeriesToRecurringExpenditures(series []*model.RecurringSeries) []*RecurringExpenditure {
+	ret := make([]*RecurringExpenditure, len(series))
+	for i, s := range series {
+		ret[i] = &RecurringExpenditure{
+			ID:             s.ID.String(),
+			MerchantID:     s.MerchantID.String(),
+			Name:           s.Name,
+			Cadence:        Cadence(s.Cadence),
+			Amount:         s.Amount,
+			PreviousAmount: s.PreviousAmount,
+			Occurrences:    s.Occurrences,
+			LastDate:       s.LastDate.Format(time.DateOnly),
+			NextDate:       s.NextDate.Format(time.DateOnly),
+			Missed:         s.Missed,
+			PriceChanged:   s.PriceChanged,
+		}
+	}
+
+	return ret
+}
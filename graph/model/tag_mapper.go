@@ -0,0 +1,19 @@
+package model
+
+import "yaba/internal/model"
+
+func TagToTagResponse(tag *model.Tag) *Tag {
+	return &Tag{
+		ID:   tag.ID.String(),
+		Name: tag.Name,
+	}
+}
+
+func TagsToTagResponses(tags []*model.Tag) []*Tag {
+	ret := make([]*Tag, len(tags))
+	for i, tag := range tags {
+		ret[i] = TagToTagResponse(tag)
+	}
+
+	return ret
+}
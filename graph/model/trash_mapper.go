@@ -0,0 +1,24 @@
+package model
+
+import (
+	"time"
+	"yaba/internal/model"
+)
+
+func TrashItemToTrashItemResponse(item *model.TrashItem) *TrashItem {
+	return &TrashItem{
+		ID:        item.ID,
+		Type:      TrashType(item.Type),
+		Name:      item.Name,
+		DeletedAt: item.DeletedAt.Format(time.RFC3339),
+	}
+}
+
+func TrashItemsToTrashItemResponses(items []*model.TrashItem) []*TrashItem {
+	ret := make([]*TrashItem, len(items))
+	for i, item := range items {
+		ret[i] = TrashItemToTrashItemResponse(item)
+	}
+
+	return ret
+}
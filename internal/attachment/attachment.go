@@ -0,0 +1,161 @@
+// Package attachment saves files attached to expenditures, like receipts, in a blob store and keeps
+// track of them in the database.
+package attachment
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"unicode"
+	"yaba/errors"
+	"yaba/internal/blob"
+	"yaba/internal/ctxutil"
+	"yaba/internal/database"
+	"yaba/internal/model"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// MaxSize is the largest file that can be attached, in bytes.
+const MaxSize = 10 << 20
+
+const maxFilenameLength = 255
+
+// allowedTypes are the content types of receipts: images and PDFs.
+var allowedTypes = map[string]bool{ //nolint:gochecknoglobals
+	"application/pdf": true,
+	"image/gif":       true,
+	"image/jpeg":      true,
+	"image/png":       true,
+	"image/webp":      true,
+}
+
+// Save stores the content as an attachment of the user's expenditure. The content type is detected
+// from the content rather than trusted from the upload.
+func Save(
+	ctx context.Context,
+	pool *pgxpool.Pool,
+	store blob.Store,
+	expenditureID int,
+	filename string,
+	content io.Reader,
+) (*model.Attachment, error) {
+	data, err := io.ReadAll(io.LimitReader(content, MaxSize+1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read attachment: %w", err)
+	}
+
+	if len(data) > MaxSize {
+		return nil, fmt.Errorf("attachments can be at most %d MB: %w", MaxSize>>20, errors.InvalidInputError{Input: filename})
+	}
+
+	contentType := http.DetectContentType(data)
+	if !allowedTypes[contentType] {
+		return nil, fmt.Errorf("attachments must be images or PDFs, not %s: %w",
+			contentType, errors.InvalidInputError{Input: filename})
+	}
+
+	// Check the expenditure before anything is written to the store.
+	if _, err = database.GetExpenditure(ctx, pool, expenditureID); err != nil {
+		return nil, err
+	}
+
+	attachment := &model.Attachment{
+		ID:            uuid.New(),
+		Owner:         ctxutil.GetUser(ctx),
+		ExpenditureID: expenditureID,
+		Filename:      cleanFilename(filename),
+		ContentType:   contentType,
+		Size:          int64(len(data)),
+	}
+
+	if err = store.Put(ctx, attachment.BlobKey(), bytes.NewReader(data), contentType); err != nil {
+		return nil, err
+	}
+
+	if err = database.CreateAttachment(ctx, pool, attachment); err != nil {
+		if deleteErr := store.Delete(ctx, attachment.BlobKey()); deleteErr != nil {
+			log.Println("failed to delete blob of unsaved attachment:", deleteErr)
+		}
+
+		return nil, err
+	}
+
+	return attachment, nil
+}
+
+// Open returns the user's attachment and its content. The caller must close the content.
+func Open(
+	ctx context.Context,
+	pool *pgxpool.Pool,
+	store blob.Store,
+	id uuid.UUID,
+) (*model.Attachment, io.ReadCloser, error) {
+	attachment, err := database.GetAttachment(ctx, pool, id)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	content, err := store.Get(ctx, attachment.BlobKey())
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return attachment, content, nil
+}
+
+// Delete deletes the user's attachment and its content.
+func Delete(ctx context.Context, pool *pgxpool.Pool, store blob.Store, id uuid.UUID) error {
+	attachment, err := database.DeleteAttachment(ctx, pool, id)
+	if err != nil {
+		return err
+	}
+
+	return store.Delete(ctx, attachment.BlobKey())
+}
+
+// UndoImport undoes the user's import batch like database.UndoImport, and deletes the files attached
+// to its expenditures.
+func UndoImport(ctx context.Context, pool *pgxpool.Pool, store blob.Store, id uuid.UUID) (int64, error) {
+	deleted, attachments, err := database.UndoImport(ctx, pool, id)
+	if err != nil {
+		return 0, err
+	}
+
+	// The records are already gone, so a file that can't be deleted is only logged.
+	for _, attachment := range attachments {
+		if err = store.Delete(ctx, attachment.BlobKey()); err != nil {
+			log.Println("failed to delete blob of attachment of undone import:", err)
+		}
+	}
+
+	return deleted, nil
+}
+
+// cleanFilename drops any directories and control characters from an uploaded file's name.
+func cleanFilename(filename string) string {
+	filename = filepath.Base(strings.ReplaceAll(filename, `\`, "/"))
+	filename = strings.Map(func(r rune) rune {
+		if unicode.IsControl(r) {
+			return -1
+		}
+
+		return r
+	}, filename)
+
+	if filename == "." || filename == "/" || filename == "" {
+		return "attachment"
+	}
+
+	if runes := []rune(filename); len(runes) > maxFilenameLength {
+		filename = string(runes[len(runes)-maxFilenameLength:])
+	}
+
+	return filename
+}
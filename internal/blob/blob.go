@@ -0,0 +1,78 @@
+// Package blob stores files uploaded by users, like receipts, outside the database.
+package blob
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"strings"
+	"yaba/errors"
+)
+
+// Store saves content under slash-separated keys such as "owner/id".
+type Store interface {
+	// Put saves the content under the key, replacing anything saved there before.
+	Put(ctx context.Context, key string, content io.Reader, contentType string) error
+	// Get opens the content saved under the key. It returns errors.NoSuchElementError if there is
+	// none.
+	Get(ctx context.Context, key string) (io.ReadCloser, error)
+	// Delete removes the content saved under the key. Deleting a missing key is not an error.
+	Delete(ctx context.Context, key string) error
+}
+
+// keyPattern limits keys to characters that are safe in file paths and URLs without escaping.
+var keyPattern = regexp.MustCompile(`^[\w-][\w.-]*(/[\w-][\w.-]*)*$`) //nolint:gochecknoglobals
+
+func validateKey(key string) error {
+	if !keyPattern.MatchString(key) || strings.Contains(key, "..") {
+		return errors.InvalidInputError{Input: "blob key " + key}
+	}
+
+	return nil
+}
+
+// NewStoreFromEnv returns the store configured by YABA_BLOB_STORE. Files are kept in the directory
+// YABA_BLOB_DIR by default, or in an S3-compatible bucket if YABA_BLOB_STORE is "s3".
+func NewStoreFromEnv() (Store, error) {
+	switch backend := os.Getenv("YABA_BLOB_STORE"); backend {
+	case "", "local":
+		dir, ok := os.LookupEnv("YABA_BLOB_DIR")
+		if !ok {
+			dir = "attachments"
+		}
+
+		return NewLocalStore(dir)
+	case "s3":
+		return newS3StoreFromEnv()
+	default:
+		return nil, errors.InvalidStateError{Message: "unknown blob store: " + backend}
+	}
+}
+
+func newS3StoreFromEnv() (*S3Store, error) {
+	missing := []string{}
+	get := func(key string) string {
+		value, ok := os.LookupEnv(key)
+		if !ok {
+			missing = append(missing, key)
+		}
+
+		return value
+	}
+
+	store := &S3Store{
+		Endpoint:  get("YABA_S3_ENDPOINT"),
+		Bucket:    get("YABA_S3_BUCKET"),
+		AccessKey: get("YABA_S3_ACCESS_KEY_ID"),
+		SecretKey: get("YABA_S3_SECRET_ACCESS_KEY"),
+		Region:    os.Getenv("YABA_S3_REGION"),
+	}
+
+	if len(missing) > 0 {
+		return nil, errors.InvalidStateError{Message: fmt.Sprintf("missing S3 env variables: %v", missing)}
+	}
+
+	return store, nil
+}
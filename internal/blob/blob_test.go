@@ -0,0 +1,132 @@
+package blob_test
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"yaba/errors"
+	"yaba/internal/blob"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestLocalStore(t *testing.T) {
+	t.Parallel()
+
+	store, err := blob.NewLocalStore(t.TempDir())
+	require.NoError(t, err)
+
+	testStore(t, store)
+}
+
+func TestS3Store(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(newFakeS3(t, "yaba", "minio"))
+	defer server.Close()
+
+	store := &blob.S3Store{
+		Endpoint:  server.URL,
+		Bucket:    "yaba",
+		AccessKey: "minio",
+		SecretKey: "minio123",
+	}
+
+	testStore(t, store)
+
+	store.AccessKey = "someone-else"
+	require.ErrorContains(t, store.Put(t.Context(), "a/b", strings.NewReader("x"), ""), "403")
+}
+
+func testStore(t *testing.T, store blob.Store) {
+	t.Helper()
+
+	ctx := t.Context()
+	read := func(key string) string {
+		reader, err := store.Get(ctx, key)
+		require.NoError(t, err)
+
+		defer func() { _ = reader.Close() }()
+
+		content, err := io.ReadAll(reader)
+		require.NoError(t, err)
+
+		return string(content)
+	}
+
+	require.NoError(t, store.Put(ctx, "owner/receipt", strings.NewReader("first"), "text/plain"))
+	require.NoError(t, store.Put(ctx, "owner/receipt", strings.NewReader("second"), "text/plain"))
+	require.NoError(t, store.Put(ctx, "owner/other.pdf", strings.NewReader("other"), "application/pdf"))
+	require.Equal(t, "second", read("owner/receipt"))
+	require.Equal(t, "other", read("owner/other.pdf"))
+
+	require.NoError(t, store.Delete(ctx, "owner/receipt"))
+	require.NoError(t, store.Delete(ctx, "owner/receipt"))
+
+	_, err := store.Get(ctx, "owner/receipt")
+	require.ErrorAs(t, err, &errors.NoSuchElementError{})
+
+	for _, key := range []string{"", "../escape", "owner/../../escape", "/absolute", "owner/", "a b"} {
+		require.Error(t, store.Put(ctx, key, strings.NewReader("x"), ""), key)
+	}
+}
+
+// newFakeS3 serves a single bucket in memory, like a local MinIO. Requests must be signed by the
+// access key and carry the hash of their body.
+func newFakeS3(t *testing.T, bucket, accessKey string) http.Handler {
+	t.Helper()
+
+	var mu sync.Mutex
+	objects := make(map[string][]byte)
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		require.NoError(t, err)
+
+		sum := sha256.Sum256(body)
+		auth := r.Header.Get("Authorization")
+
+		if !strings.HasPrefix(auth, "AWS4-HMAC-SHA256 Credential="+accessKey+"/") ||
+			!strings.Contains(auth, "/us-east-1/s3/aws4_request, SignedHeaders=") ||
+			r.Header.Get("X-Amz-Content-Sha256") != hex.EncodeToString(sum[:]) ||
+			r.Header.Get("X-Amz-Date") == "" {
+			w.WriteHeader(http.StatusForbidden)
+
+			return
+		}
+
+		key, ok := strings.CutPrefix(r.URL.Path, "/"+bucket+"/")
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+
+			return
+		}
+
+		mu.Lock()
+		defer mu.Unlock()
+
+		switch r.Method {
+		case http.MethodPut:
+			objects[key] = body
+		case http.MethodGet:
+			object, ok := objects[key]
+			if !ok {
+				w.WriteHeader(http.StatusNotFound)
+
+				return
+			}
+
+			_, _ = w.Write(object)
+		case http.MethodDelete:
+			delete(objects, key)
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			w.WriteHeader(http.StatusMethodNotAllowed)
+		}
+	})
+}
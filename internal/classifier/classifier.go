@@ -0,0 +1,73 @@
+// Package classifier suggests categories for expenditures from the categories the user already
+// assigned to similar ones.
+package classifier
+
+import (
+	"strings"
+	"yaba/internal/model"
+)
+
+// Model holds one user's budget and reward category classifiers.
+type Model struct {
+	BudgetCategory *NaiveBayes `json:"budgetCategory"`
+	RewardCategory *NaiveBayes `json:"rewardCategory"`
+}
+
+// Suggestion is the most likely budget and reward category for an expenditure name. Categories
+// are empty if there is nothing to learn from.
+type Suggestion struct {
+	Name                     string
+	BudgetCategory           string
+	BudgetCategoryConfidence float64
+	RewardCategory           string
+	RewardCategoryConfidence float64
+}
+
+func NewModel() *Model {
+	return &Model{
+		BudgetCategory: NewNaiveBayes(),
+		RewardCategory: NewNaiveBayes(),
+	}
+}
+
+// Learn trains the model on the name and comment of a categorized expenditure. Uncategorized
+// expenditures are ignored. Categories are learned in lower case, since they are matched ignoring
+// case.
+func (m *Model) Learn(expenditure *model.Expenditure) {
+	tokens := Tokenize(expenditure.Name + " " + expenditure.Comment)
+	if len(tokens) == 0 {
+		return
+	}
+
+	if expenditure.BudgetCategory != "" {
+		m.BudgetCategory.Train(tokens, strings.ToLower(expenditure.BudgetCategory))
+	}
+
+	if expenditure.RewardCategory != "" {
+		m.RewardCategory.Train(tokens, strings.ToLower(expenditure.RewardCategory))
+	}
+}
+
+func (m *Model) Suggest(name string) *Suggestion {
+	tokens := Tokenize(name)
+	suggestion := &Suggestion{Name: name}
+
+	suggestion.BudgetCategory, suggestion.BudgetCategoryConfidence = m.BudgetCategory.Predict(tokens)
+	suggestion.RewardCategory, suggestion.RewardCategoryConfidence = m.RewardCategory.Predict(tokens)
+
+	return suggestion
+}
+
+// Fill sets the blank categories of the expenditure to the suggested ones if the suggestion's
+// confidence is at least threshold.
+func (m *Model) Fill(expenditure *model.Expenditure, threshold float64) {
+	suggestion := m.Suggest(expenditure.Name)
+
+	if expenditure.BudgetCategory == "" && suggestion.BudgetCategoryConfidence >= threshold {
+		expenditure.BudgetCategory = suggestion.BudgetCategory
+	}
+
+	if expenditure.RewardCategory == "" && suggestion.RewardCategoryConfidence >= threshold {
+		expenditure.RewardCategory = suggestion.RewardCategory
+	}
+}
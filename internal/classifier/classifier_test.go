@@ -0,0 +1,90 @@
+package classifier_test
+
+import (
+	"encoding/json"
+	"testing"
+	"yaba/internal/classifier"
+	"yaba/internal/model"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestTokenize(t *testing.T) {
+	t.Parallel()
+
+	require.Equal(t, []string{"sq", "blue", "bottle", "sf", "4g"},
+		classifier.Tokenize("SQ *BLUE BOTTLE #1234 SF, 4G"))
+	require.Empty(t, classifier.Tokenize("# 12 - a"))
+}
+
+func TestSuggest(t *testing.T) {
+	t.Parallel()
+
+	m := classifier.NewModel()
+
+	for _, e := range []*model.Expenditure{
+		{Name: "COSTCO WHOLESALE #123", BudgetCategory: "Groceries", RewardCategory: "grocery"},
+		{Name: "Costco Wholesale 456", BudgetCategory: "Groceries", RewardCategory: "grocery"},
+		{Name: "LOBLAWS 1001", BudgetCategory: "GROCERIES", RewardCategory: "Grocery"},
+		{Name: "COSTCO GAS W789", BudgetCategory: "Gas", RewardCategory: "gas"},
+		{Name: "SHELL 5555", BudgetCategory: "Gas", RewardCategory: "gas"},
+		{Name: "UBER TRIP", Comment: "ride home", BudgetCategory: "Transit"},
+		{Name: "mystery charge"},
+	} {
+		m.Learn(e)
+	}
+
+	// Categories that differ only in case are the same
+	require.Equal(t, 3, m.BudgetCategory.Documents["groceries"])
+	require.Equal(t, 3, m.RewardCategory.Documents["grocery"])
+
+	suggestion := m.Suggest("Costco Wholesale #999")
+	require.Equal(t, "groceries", suggestion.BudgetCategory)
+	require.Greater(t, suggestion.BudgetCategoryConfidence, 0.8)
+	require.Equal(t, "grocery", suggestion.RewardCategory)
+
+	suggestion = m.Suggest("Shell Oil")
+	require.Equal(t, "gas", suggestion.BudgetCategory)
+	require.Equal(t, "gas", suggestion.RewardCategory)
+
+	// Comments are learned too
+	suggestion = m.Suggest("UBER RIDE")
+	require.Equal(t, "transit", suggestion.BudgetCategory)
+	require.Empty(t, suggestion.RewardCategory)
+	require.Zero(t, suggestion.RewardCategoryConfidence)
+
+	// Nothing is known about new words
+	suggestion = m.Suggest("Hardware Store")
+	require.Equal(t, &classifier.Suggestion{Name: "Hardware Store"}, suggestion)
+
+	// Ambiguous names have lower confidence
+	ambiguous := m.Suggest("COSTCO")
+	require.Less(t, ambiguous.BudgetCategoryConfidence, m.Suggest("Costco Wholesale").BudgetCategoryConfidence)
+
+	// Models survive a round trip through JSON
+	data, err := json.Marshal(m)
+	require.NoError(t, err)
+
+	restored := &classifier.Model{}
+	require.NoError(t, json.Unmarshal(data, restored))
+	require.Equal(t, m.Suggest("costco"), restored.Suggest("costco"))
+}
+
+func TestFill(t *testing.T) {
+	t.Parallel()
+
+	m := classifier.NewModel()
+	m.Learn(&model.Expenditure{Name: "Netflix", BudgetCategory: "Subscriptions", RewardCategory: "recurring bill"})
+	m.Learn(&model.Expenditure{Name: "Spotify", BudgetCategory: "Subscriptions"})
+	m.Learn(&model.Expenditure{Name: "Pizza Pizza", BudgetCategory: "Restaurants"})
+
+	e := &model.Expenditure{Name: "NETFLIX.COM", RewardCategory: "streaming"}
+	m.Fill(e, 0.5)
+	require.Equal(t, "subscriptions", e.BudgetCategory)
+	require.Equal(t, "streaming", e.RewardCategory)
+
+	e = &model.Expenditure{Name: "NETFLIX.COM"}
+	m.Fill(e, 1.1)
+	require.Empty(t, e.BudgetCategory)
+	require.Empty(t, e.RewardCategory)
+}
@@ -0,0 +1,119 @@
+package classifier
+
+import (
+	"math"
+	"strings"
+	"unicode"
+)
+
+// NaiveBayes is a multinomial naive Bayes text classifier with add-one smoothing. It can be
+// trained incrementally and serialized as JSON.
+type NaiveBayes struct {
+	// Documents is the number of training documents per label.
+	Documents map[string]int `json:"documents"`
+	// Tokens counts the occurrences of each token per label.
+	Tokens map[string]map[string]int `json:"tokens"`
+	// TokenTotals is the total number of tokens seen per label.
+	TokenTotals map[string]int `json:"tokenTotals"`
+	// Vocabulary counts the occurrences of each token across all labels.
+	Vocabulary map[string]int `json:"vocabulary"`
+}
+
+func NewNaiveBayes() *NaiveBayes {
+	return &NaiveBayes{
+		Documents:   make(map[string]int),
+		Tokens:      make(map[string]map[string]int),
+		TokenTotals: make(map[string]int),
+		Vocabulary:  make(map[string]int),
+	}
+}
+
+// Train adds a document with the given tokens to the label.
+func (nb *NaiveBayes) Train(tokens []string, label string) {
+	nb.Documents[label]++
+
+	if nb.Tokens[label] == nil {
+		nb.Tokens[label] = make(map[string]int)
+	}
+
+	for _, token := range tokens {
+		nb.Tokens[label][token]++
+		nb.TokenTotals[label]++
+		nb.Vocabulary[token]++
+	}
+}
+
+// Predict returns the most likely label of a document and its posterior probability. Tokens that
+// were never seen in training are ignored. It returns an empty label if none of the tokens were
+// seen.
+func (nb *NaiveBayes) Predict(tokens []string) (string, float64) {
+	known := false
+	for _, token := range tokens {
+		if _, ok := nb.Vocabulary[token]; ok {
+			known = true
+
+			break
+		}
+	}
+
+	if !known {
+		return "", 0
+	}
+
+	totalDocuments := 0
+	for _, n := range nb.Documents {
+		totalDocuments += n
+	}
+
+	vocabularySize := float64(len(nb.Vocabulary))
+	logProbabilities := make(map[string]float64, len(nb.Documents))
+	best, bestLogProbability := "", math.Inf(-1)
+
+	for label, documents := range nb.Documents {
+		logProbability := math.Log(float64(documents) / float64(totalDocuments))
+
+		for _, token := range tokens {
+			if _, ok := nb.Vocabulary[token]; !ok {
+				continue
+			}
+
+			count := float64(nb.Tokens[label][token])
+			logProbability += math.Log((count + 1) / (float64(nb.TokenTotals[label]) + vocabularySize))
+		}
+
+		logProbabilities[label] = logProbability
+
+		// Ties are broken alphabetically so predictions don't depend on map order.
+		if logProbability > bestLogProbability || (logProbability == bestLogProbability && label < best) {
+			best, bestLogProbability = label, logProbability
+		}
+	}
+
+	// Normalize relative to the best label to avoid underflow.
+	sum := 0.0
+	for _, logProbability := range logProbabilities {
+		sum += math.Exp(logProbability - bestLogProbability)
+	}
+
+	return best, 1 / sum
+}
+
+// Tokenize splits text into lowercase words. Numbers and single characters, such as store numbers
+// and punctuation, are dropped.
+func Tokenize(text string) []string {
+	words := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+
+	tokens := make([]string, 0, len(words))
+
+	for _, word := range words {
+		if len([]rune(word)) < 2 || strings.IndexFunc(word, unicode.IsLetter) < 0 {
+			continue
+		}
+
+		tokens = append(tokens, word)
+	}
+
+	return tokens
+}
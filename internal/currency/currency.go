@@ -0,0 +1,156 @@
+// Package currency validates currency codes and reads exchange rates from CSV files and ECB-style
+// XML reference rate files.
+package currency
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+	"yaba/errors"
+	"yaba/internal/model"
+)
+
+// ecbBase is the base currency of the European Central Bank's reference rates.
+const ecbBase = "EUR"
+
+// Normalize returns the ISO 4217 code in upper case. An empty code is allowed and stands for the
+// user's home currency.
+func Normalize(code string) (string, error) {
+	code = strings.ToUpper(strings.TrimSpace(code))
+	if code == "" {
+		return "", nil
+	}
+
+	if len(code) != 3 || strings.ContainsFunc(code, func(r rune) bool { return r < 'A' || r > 'Z' }) {
+		return "", errors.InvalidInputError{Input: "currency " + code}
+	}
+
+	return code, nil
+}
+
+// ParseRates reads exchange rates from an ECB-style XML file, or from a CSV file with date, base,
+// quote and rate columns in any order. Dates are formatted as 2006-01-02.
+func ParseRates(r io.Reader) ([]*model.ExchangeRate, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read exchange rates: %w", err)
+	}
+
+	data = bytes.TrimSpace(bytes.TrimPrefix(data, []byte("\xef\xbb\xbf")))
+	if bytes.HasPrefix(data, []byte("<")) {
+		return parseECB(bytes.NewReader(data))
+	}
+
+	return parseCSV(bytes.NewReader(data))
+}
+
+func parseCSV(r io.Reader) ([]*model.ExchangeRate, error) {
+	reader := csv.NewReader(r)
+	reader.TrimLeadingSpace = true
+
+	rows, err := reader.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read csv: %w", err)
+	}
+
+	if len(rows) == 0 {
+		return []*model.ExchangeRate{}, nil
+	}
+
+	columns := map[string]int{}
+	for i, name := range rows[0] {
+		columns[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+
+	for _, column := range []string{"date", "base", "quote", "rate"} {
+		if _, ok := columns[column]; !ok {
+			return nil, errors.InvalidInputError{Input: "missing column " + column}
+		}
+	}
+
+	rates := make([]*model.ExchangeRate, 0, len(rows)-1)
+
+	for i, row := range rows[1:] {
+		if len(row) < len(rows[0]) {
+			return nil, fmt.Errorf("line %d: %w", i+2, errors.InvalidInputError{Input: row})
+		}
+
+		rate, err := newRate(row[columns["date"]], row[columns["base"]], row[columns["quote"]], row[columns["rate"]])
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", i+2, err)
+		}
+
+		rates = append(rates, rate)
+	}
+
+	return rates, nil
+}
+
+// ecbEnvelope is the layout of the ECB's eurofxref files, which list the value of a euro in other
+// currencies for each day.
+type ecbEnvelope struct {
+	Days []struct {
+		Time  string `xml:"time,attr"`
+		Rates []struct {
+			Currency string `xml:"currency,attr"`
+			Rate     string `xml:"rate,attr"`
+		} `xml:"Cube"`
+	} `xml:"Cube>Cube"`
+}
+
+func parseECB(r io.Reader) ([]*model.ExchangeRate, error) {
+	var envelope ecbEnvelope
+	if err := xml.NewDecoder(r).Decode(&envelope); err != nil {
+		return nil, fmt.Errorf("failed to read xml: %w", err)
+	}
+
+	rates := []*model.ExchangeRate{}
+
+	for _, day := range envelope.Days {
+		for _, rate := range day.Rates {
+			exchangeRate, err := newRate(day.Time, ecbBase, rate.Currency, rate.Rate)
+			if err != nil {
+				return nil, fmt.Errorf("%s %s: %w", day.Time, rate.Currency, err)
+			}
+
+			rates = append(rates, exchangeRate)
+		}
+	}
+
+	return rates, nil
+}
+
+func newRate(date, base, quote, rate string) (*model.ExchangeRate, error) {
+	day, err := time.ParseInLocation(time.DateOnly, strings.TrimSpace(date), time.UTC)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse date: %w", err)
+	}
+
+	if base, err = Normalize(base); err != nil {
+		return nil, err
+	}
+
+	if quote, err = Normalize(quote); err != nil {
+		return nil, err
+	}
+
+	if base == "" || quote == "" || base == quote {
+		return nil, errors.InvalidInputError{Input: base + "/" + quote}
+	}
+
+	value, err := strconv.ParseFloat(strings.TrimSpace(rate), 64)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse rate: %w", err)
+	}
+
+	if value <= 0 {
+		return nil, errors.InvalidInputError{Input: "rate " + rate}
+	}
+
+	return &model.ExchangeRate{Date: day, Base: base, Quote: quote, Rate: value}, nil
+}
@@ -0,0 +1,80 @@
+package currency_test
+
+import (
+	"os"
+	"strings"
+	"testing"
+	"time"
+	"yaba/internal/currency"
+	"yaba/internal/model"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestNormalize(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		code     string
+		expected string
+		valid    bool
+	}{
+		{code: "usd", expected: "USD", valid: true},
+		{code: " EUR ", expected: "EUR", valid: true},
+		{code: "", expected: "", valid: true},
+		{code: "US", valid: false},
+		{code: "US1", valid: false},
+		{code: "DOLLAR", valid: false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.code, func(t *testing.T) {
+			t.Parallel()
+
+			code, err := currency.Normalize(tc.code)
+			if !tc.valid {
+				require.Error(t, err)
+
+				return
+			}
+
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, code)
+		})
+	}
+}
+
+func TestParseRates(t *testing.T) {
+	t.Parallel()
+
+	day := func(d int) time.Time { return time.Date(2024, 7, d, 0, 0, 0, 0, time.UTC) }
+
+	f, err := os.Open("testdata/eurofxref.xml")
+	require.NoError(t, err)
+
+	defer func() { _ = f.Close() }()
+
+	rates, err := currency.ParseRates(f)
+	require.NoError(t, err)
+	require.Equal(t, []*model.ExchangeRate{
+		{Date: day(2), Base: "EUR", Quote: "USD", Rate: 1.0723},
+		{Date: day(2), Base: "EUR", Quote: "CAD", Rate: 1.4684},
+		{Date: day(1), Base: "EUR", Quote: "USD", Rate: 1.0745},
+		{Date: day(1), Base: "EUR", Quote: "CAD", Rate: 1.4702},
+	}, rates)
+
+	rates, err = currency.ParseRates(strings.NewReader("Quote,Base,Date,Rate\ncad,usd,2024-07-01,1.3682\n"))
+	require.NoError(t, err)
+	require.Equal(t, []*model.ExchangeRate{{Date: day(1), Base: "USD", Quote: "CAD", Rate: 1.3682}}, rates)
+
+	for _, input := range []string{
+		"date,base,rate\n2024-07-01,USD,1.3\n",
+		"date,base,quote,rate\n07/01/2024,USD,CAD,1.3\n",
+		"date,base,quote,rate\n2024-07-01,USD,USD,1\n",
+		"date,base,quote,rate\n2024-07-01,USD,CAD,-1\n",
+		"<Envelope><Cube><Cube time=\"2024-07-01\"><Cube currency=\"EURO\" rate=\"1\"/></Cube></Cube></Envelope>",
+	} {
+		_, err = currency.ParseRates(strings.NewReader(input))
+		require.Error(t, err, input)
+	}
+}
@@ -0,0 +1,127 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"time"
+	"yaba/errors"
+	"yaba/internal/ctxutil"
+	"yaba/internal/model"
+
+	"github.com/Masterminds/squirrel"
+	"github.com/georgysavva/scany/v2/pgxscan"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// createAttachment saves an attachment only if the expenditure belongs to the user.
+const createAttachment = `INSERT INTO attachment (id, owner, expenditure_id, filename, content_type, size)
+	SELECT $1, e.owner, e.id, $2, $3, $4 FROM expenditure e
+	WHERE e.id = $5 AND e.owner = $6 AND e.deleted_at IS NULL
+	RETURNING created`
+
+// CreateAttachment saves the attachment to the user's expenditure. The file must already be in the
+// blob store.
+func CreateAttachment(ctx context.Context, pool *pgxpool.Pool, attachment *model.Attachment) error {
+	user := ctxutil.GetUser(ctx)
+
+	var created []time.Time
+
+	err := pgxscan.Select(ctx, pool, &created, createAttachment, attachment.ID, attachment.Filename,
+		attachment.ContentType, attachment.Size, attachment.ExpenditureID, user)
+	if err != nil {
+		return fmt.Errorf("failed to create attachment: %w", err)
+	}
+
+	if len(created) == 0 {
+		return errors.NoSuchElementError{Element: attachment.ExpenditureID}
+	}
+
+	attachment.Owner = user
+	attachment.CreatedTime = created[0]
+
+	return nil
+}
+
+// ListAttachments returns the attachments of the user's expenditure, oldest first.
+func ListAttachments(ctx context.Context, pool *pgxpool.Pool, expenditureID int) ([]*model.Attachment, error) {
+	attachments, err := ListAttachmentsByExpenditure(ctx, pool, []int{expenditureID})
+	if err != nil {
+		return nil, err
+	}
+
+	return attachments[expenditureID], nil
+}
+
+// ListAttachmentsByExpenditure returns the attachments of several of the user's expenditures at once,
+// mapped by expenditure ID, oldest first. Expenditures without attachments are left out.
+func ListAttachmentsByExpenditure(
+	ctx context.Context,
+	pool *pgxpool.Pool,
+	expenditureIDs []int,
+) (map[int][]*model.Attachment, error) {
+	query, args, err := squirrel.Select("*").
+		From("attachment").
+		Where("owner = ? AND expenditure_id = ANY(?)", ctxutil.GetUser(ctx), expenditureIDs).
+		OrderBy("created", "id").
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("failed to build query: %w", err)
+	}
+
+	var attachments []*model.Attachment
+	if err = pgxscan.Select(ctx, pool, &attachments, query, args...); err != nil {
+		return nil, fmt.Errorf("failed to list attachments: %w", err)
+	}
+
+	byExpenditure := make(map[int][]*model.Attachment)
+	for _, attachment := range attachments {
+		byExpenditure[attachment.ExpenditureID] = append(byExpenditure[attachment.ExpenditureID], attachment)
+	}
+
+	return byExpenditure, nil
+}
+
+func GetAttachment(ctx context.Context, pool *pgxpool.Pool, id uuid.UUID) (*model.Attachment, error) {
+	query, args, err := squirrel.Select("*").
+		From("attachment").
+		Where(squirrel.Eq{"id": id, "owner": ctxutil.GetUser(ctx)}).
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("failed to build query: %w", err)
+	}
+
+	var attachments []*model.Attachment
+	if err = pgxscan.Select(ctx, pool, &attachments, query, args...); err != nil {
+		return nil, fmt.Errorf("failed to get attachment: %w", err)
+	}
+
+	if len(attachments) == 0 {
+		return nil, errors.NoSuchElementError{Element: id}
+	}
+
+	return attachments[0], nil
+}
+
+// DeleteAttachment deletes the user's attachment and returns it, so that its file can be removed
+// from the blob store.
+func DeleteAttachment(ctx context.Context, pool *pgxpool.Pool, id uuid.UUID) (*model.Attachment, error) {
+	query, args, err := squirrel.Delete("attachment").
+		Where(squirrel.Eq{"id": id, "owner": ctxutil.GetUser(ctx)}).
+		Suffix("RETURNING *").
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("failed to build query: %w", err)
+	}
+
+	var attachments []*model.Attachment
+	if err = pgxscan.Select(ctx, pool, &attachments, query, args...); err != nil {
+		return nil, fmt.Errorf("failed to delete attachment: %w", err)
+	}
+
+	if len(attachments) == 0 {
+		return nil, errors.NoSuchElementError{Element: id}
+	}
+
+	return attachments[0], nil
+}
@@ -0,0 +1,69 @@
+package database_test
+
+import (
+	"testing"
+	"time"
+	"yaba/internal/ctxutil"
+	"yaba/internal/database"
+	"yaba/internal/model"
+	"yaba/internal/test/helper"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAttachments(t *testing.T) {
+	t.Parallel()
+
+	pool := helper.GetTestPool()
+	owner := uuid.New()
+	ctx := ctxutil.WithUser(t.Context(), owner)
+	otherCtx := ctxutil.WithUser(t.Context(), uuid.New())
+
+	result, err := database.ImportExpenditures(ctx, pool, []*model.Expenditure{
+		{Owner: owner, Name: "LAPTOP", Amount: model.MoneyFromFloat(1200), Date: time.Now()},
+	})
+	require.NoError(t, err)
+
+	laptop := result.Inserted[0]
+	receipt := &model.Attachment{
+		ID:            uuid.New(),
+		ExpenditureID: laptop.ID,
+		Filename:      "receipt.pdf",
+		ContentType:   "application/pdf",
+		Size:          1024,
+	}
+
+	// Other users can't attach files to the expenditure
+	require.ErrorContains(t, database.CreateAttachment(otherCtx, pool, receipt), "no such element")
+
+	require.NoError(t, database.CreateAttachment(ctx, pool, receipt))
+	require.Equal(t, owner, receipt.Owner)
+	require.False(t, receipt.CreatedTime.IsZero())
+
+	attachments, err := database.ListAttachments(ctx, pool, laptop.ID)
+	require.NoError(t, err)
+	require.Len(t, attachments, 1)
+	require.Equal(t, receipt.Filename, attachments[0].Filename)
+	require.Equal(t, receipt.Size, attachments[0].Size)
+
+	byExpenditure, err := database.ListAttachmentsByExpenditure(ctx, pool, []int{laptop.ID, laptop.ID + 1})
+	require.NoError(t, err)
+	require.Equal(t, map[int][]*model.Attachment{laptop.ID: attachments}, byExpenditure)
+
+	attachments, err = database.ListAttachments(otherCtx, pool, laptop.ID)
+	require.NoError(t, err)
+	require.Empty(t, attachments)
+
+	_, err = database.GetAttachment(otherCtx, pool, receipt.ID)
+	require.ErrorContains(t, err, "no such element")
+	_, err = database.DeleteAttachment(otherCtx, pool, receipt.ID)
+	require.ErrorContains(t, err, "no such element")
+
+	deleted, err := database.DeleteAttachment(ctx, pool, receipt.ID)
+	require.NoError(t, err)
+	require.Equal(t, receipt.BlobKey(), deleted.BlobKey())
+
+	_, err = database.GetAttachment(ctx, pool, receipt.ID)
+	require.ErrorContains(t, err, "no such element")
+}
@@ -0,0 +1,35 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"yaba/internal/ctxutil"
+	"yaba/internal/model"
+
+	"github.com/Masterminds/squirrel"
+	"github.com/georgysavva/scany/v2/pgxscan"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// ListHistory returns the changes to the user's record with the ID, oldest first. The audit log is
+// written by triggers in the same transaction as each change, so it has no functions to add to it.
+func ListHistory(ctx context.Context, pool *pgxpool.Pool, entityID string) ([]*model.AuditEntry, error) {
+	query, args, err := squirrel.Select("*").
+		From("audit_log").
+		Where(squirrel.Eq{
+			"owner":     ctxutil.GetUser(ctx),
+			"entity_id": entityID,
+		}).
+		OrderBy("id").
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("failed to build query: %w", err)
+	}
+
+	var entries []*model.AuditEntry
+	if err = pgxscan.Select(ctx, pool, &entries, query, args...); err != nil {
+		return nil, fmt.Errorf("failed to list history: %w", err)
+	}
+
+	return entries, nil
+}
@@ -0,0 +1,108 @@
+package database_test
+
+import (
+	"encoding/json"
+	"strconv"
+	"testing"
+	"time"
+	"yaba/internal/ctxutil"
+	"yaba/internal/database"
+	"yaba/internal/model"
+	"yaba/internal/test/helper"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+func TestListHistory(t *testing.T) {
+	t.Parallel()
+
+	pool := helper.GetTestPool()
+	owner := uuid.New()
+	ctx := ctxutil.WithUser(t.Context(), owner)
+
+	actions := func(entries []*model.AuditEntry) []string {
+		ret := make([]string, len(entries))
+		for i, entry := range entries {
+			ret[i] = entry.EntityType + " " + entry.Action
+		}
+
+		return ret
+	}
+
+	field := func(row []byte, key string) any {
+		var values map[string]any
+		require.NoError(t, json.Unmarshal(row, &values))
+
+		return values[key]
+	}
+
+	budget := model.NewBudget(owner, "monthly")
+	budget.SetBudgetIncome("work", model.MoneyFromFloat(5000))
+	budget.SetFixedExpense("housing", model.MoneyFromFloat(1500))
+	budget.SetFixedExpense("food", model.MoneyFromFloat(500))
+	require.NoError(t, database.PersistBudget(ctx, pool, budget))
+
+	// Saving the budget again only records what changed.
+	food := budget.Expenses[1]
+	food.Amount = model.MoneyFromFloat(600)
+	require.NoError(t, database.PersistBudget(ctx, pool, budget))
+
+	history, err := database.ListHistory(ctx, pool, budget.ID.String())
+	require.NoError(t, err)
+	require.Equal(t, []string{"budget INSERT", "income INSERT"}, actions(history))
+	require.Nil(t, history[0].Before)
+	require.Equal(t, "monthly", field(history[0].After, "name"))
+	require.Equal(t, history[0].TransactionID, history[1].TransactionID)
+
+	history, err = database.ListHistory(ctx, pool, food.ID.String())
+	require.NoError(t, err)
+	require.Equal(t, []string{"expense INSERT", "expense UPDATE"}, actions(history))
+	require.Equal(t, owner, history[1].Owner)
+	require.InDelta(t, 500, field(history[1].Before, "amount"), 0)
+	require.InDelta(t, 600, field(history[1].After, "amount"), 0)
+
+	expenditures := []*model.Expenditure{
+		{Owner: owner, Name: "Groceries", Amount: model.MoneyFromFloat(80), Date: time.Now()},
+	}
+	require.NoError(t, database.PersistExpenditures(ctx, pool, expenditures))
+
+	expenditure := expenditures[0]
+	expenditure.BudgetCategory = "food"
+	require.NoError(t, database.UpdateExpenditure(ctx, pool, expenditure))
+
+	// Updates that change nothing aren't recorded.
+	require.NoError(t, database.UpdateExpenditure(ctx, pool, expenditure))
+
+	_, err = database.DeleteExpenditures(ctx, pool, []int{expenditure.ID})
+	require.NoError(t, err)
+
+	history, err = database.ListHistory(ctx, pool, strconv.Itoa(expenditure.ID))
+	require.NoError(t, err)
+	require.Equal(t, []string{"expenditure INSERT", "expenditure UPDATE", "expenditure UPDATE"}, actions(history))
+	require.Empty(t, field(history[1].Before, "budget_category"))
+	require.Equal(t, "food", field(history[1].After, "budget_category"))
+	require.Equal(t, food.ID.String(), field(history[1].After, "expense_id"))
+	require.NotContains(t, string(history[1].After), `"search"`)
+	require.Nil(t, field(history[2].Before, "deleted_at"))
+	require.NotNil(t, field(history[2].After, "deleted_at"))
+
+	// Permanent deletes are recorded too.
+	_, _, err = database.UndoImport(ctx, pool, expenditure.BatchID)
+	require.NoError(t, err)
+
+	history, err = database.ListHistory(ctx, pool, strconv.Itoa(expenditure.ID))
+	require.NoError(t, err)
+	require.Len(t, history, 4)
+	require.Equal(t, "DELETE", history[3].Action)
+	require.Nil(t, history[3].After)
+
+	// Other users can't see the history.
+	history, err = database.ListHistory(ctxutil.WithUser(t.Context(), uuid.New()), pool, budget.ID.String())
+	require.NoError(t, err)
+	require.Empty(t, history)
+
+	// The log is append-only.
+	_, err = pool.Exec(ctx, "DELETE FROM audit_log WHERE owner = $1", owner)
+	require.ErrorContains(t, err, "append-only")
+}
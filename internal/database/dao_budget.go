@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"yaba/errors"
 	"yaba/internal/ctxutil"
+	"yaba/internal/currency"
 	"yaba/internal/model"
 
 	"github.com/Masterminds/squirrel"
@@ -17,36 +18,52 @@ import (
 const getBudget = `
 SELECT * FROM budget
 WHERE owner = $1
-  AND id = $2;
+  AND id = $2
+  AND deleted_at IS NULL;
 `
 
 const getBudgetsByOwner = `
 SELECT * FROM budget
 WHERE owner = $1
+  AND deleted_at IS NULL
 LIMIT $2;
 `
 
-const deleteBudget = `
-DELETE FROM budget
+const trashBudget = `
+UPDATE budget
+SET deleted_at = NOW()
 WHERE owner = $1
-  AND id = $2;
+  AND id = $2
+  AND deleted_at IS NULL;
 `
 
+// reassignExpenditures moves the user's expenditures and splits from the expenses of budget $2 to the
+// expenses of budget $3 with the same categories, ignoring case. Those whose category isn't in budget
+// $3 are left unassigned.
+//
+//nolint:gochecknoglobals
+var reassignExpenditures = []string{
+	`UPDATE expenditure e
+	SET expense_id = COALESCE((SELECT t.id FROM expense t
+		WHERE t.budget_id = $3 AND LOWER(t.category) = LOWER(e.budget_category)), uuid_nil())
+	WHERE e.owner = $1 AND e.expense_id IN (SELECT id FROM expense WHERE budget_id = $2)`,
+	`UPDATE expenditure_split s
+	SET expense_id = COALESCE((SELECT t.id FROM expense t
+		WHERE t.budget_id = $3 AND LOWER(t.category) = LOWER(s.budget_category)), uuid_nil())
+	WHERE s.owner = $1 AND s.expense_id IN (SELECT id FROM expense WHERE budget_id = $2)`,
+}
+
 const getIncomesByOwner = `
 SELECT * FROM income
 WHERE owner IN ($1)
 `
 
 const upsertIncome = `
-INSERT INTO income (owner, source, amount)
-VALUES ($1, $2, $3)
+INSERT INTO income (owner, source, amount, currency)
+VALUES ($1, $2, $3, $4)
 ON CONFLICT (owner, source) DO UPDATE
-SET amount = $3
-`
-
-const deleteIncomeByOwner = `
-DELETE FROM income
-WHERE owner = $1
+SET amount = $3,
+    currency = $4
 `
 
 const getExpensesForBudget = `
@@ -55,17 +72,14 @@ WHERE budget_id = $1
 `
 
 const upsertExpense = `
-INSERT INTO expense (budget_id, category, amount, is_fixed, is_slack, id)
-VALUES ($1, $2, $3, $4, $5, $6)
+INSERT INTO expense (budget_id, category, amount, is_fixed, is_slack, id, currency, rollover)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
 ON CONFLICT (budget_id, category) DO UPDATE
 SET amount = $3,
     is_fixed = $4,
-	is_slack = $5
-`
-
-const deleteExpenseByBudget = `
-DELETE FROM expense
-WHERE budget_id = $1
+	is_slack = $5,
+	currency = $7,
+	rollover = $8
 `
 
 func GetBudget(
@@ -113,6 +127,62 @@ func GetBudgets(
 	return budgets, nil
 }
 
+// ListBudgetPage returns up to first of the user's budgets whose names contain name, ignoring case,
+// ordered by name and starting after the cursor if one is given.
+func ListBudgetPage(
+	ctx context.Context,
+	pool *pgxpool.Pool,
+	name *string,
+	first int,
+	after *model.BudgetCursor,
+) (*model.BudgetPage, error) {
+	if first < 0 {
+		return nil, fmt.Errorf("page size must not be negative: %w", errors.InvalidInputError{Input: first})
+	}
+
+	filter := squirrel.And{squirrel.Eq{"owner": ctxutil.GetUser(ctx), "deleted_at": nil}}
+	if name != nil && *name != "" {
+		filter = append(filter, squirrel.Expr("STRPOS(LOWER(name), LOWER(?)) > 0", *name))
+	}
+
+	sq := squirrel.Select("*").From("budget").Where(filter)
+	if after != nil {
+		sq = sq.Where("(name, id) > (?, ?)", after.Name, after.ID)
+	}
+
+	// Fetch one more budget than the page holds to tell whether there is a next page.
+	query, args, err := sq.OrderBy("name", "id").
+		Limit(uint64(first) + 1). //nolint:gosec
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("failed to build query: %w", err)
+	}
+
+	page := &model.BudgetPage{}
+	if err = pgxscan.Select(ctx, pool, &page.Budgets, query, args...); err != nil {
+		return nil, fmt.Errorf("failed to get budgets: %w", err)
+	}
+
+	if len(page.Budgets) > first {
+		page.Budgets = page.Budgets[:first]
+		page.HasNextPage = true
+	}
+
+	if err = populateBudgets(ctx, pool, page.Budgets); err != nil {
+		return nil, fmt.Errorf("failed to get budgets: %w", err)
+	}
+
+	if query, args, err = squirrel.Select("COUNT(*)").From("budget").Where(filter).ToSql(); err != nil {
+		return nil, fmt.Errorf("failed to build query: %w", err)
+	}
+
+	if err = pgxscan.Get(ctx, pool, &page.TotalCount, query, args...); err != nil {
+		return nil, fmt.Errorf("failed to count budgets: %w", err)
+	}
+
+	return page, nil
+}
+
 func populateBudgets(ctx context.Context, pool *pgxpool.Pool, budgets []*model.Budget) error {
 	// Batch budget loading
 	batch := &pgx.Batch{}
@@ -144,21 +214,40 @@ func populateBudgets(ctx context.Context, pool *pgxpool.Pool, budgets []*model.B
 }
 
 func PersistBudget(ctx context.Context, pool *pgxpool.Pool, budget *model.Budget) error {
+	if err := budget.ValidatePeriod(); err != nil {
+		return err
+	}
+
 	// Create batch
 	batch := &pgx.Batch{}
 
-	// Upsert budget and delete incomes/expenses
-	if err := upsertResetBudget(ctx, budget, batch); err != nil {
+	// Snapshot the periods that ended before the expenses change
+	if err := queueBudgetPeriodSnapshot(ctx, budget, batch); err != nil {
+		return err
+	}
+
+	// Upsert budget and delete removed incomes/expenses
+	if err := upsertBudget(ctx, budget, batch); err != nil {
 		return err
 	}
 
 	// Upsert incomes
 	for _, income := range budget.Incomes {
-		batch.Queue(upsertIncome, income.Owner, income.Source, income.Amount)
+		code, err := currency.Normalize(income.Currency)
+		if err != nil {
+			return err
+		}
+
+		batch.Queue(upsertIncome, income.Owner, income.Source, income.Amount, code)
 	}
 
 	// Upsert expenses
 	for _, expense := range budget.Expenses {
+		code, err := currency.Normalize(expense.Currency)
+		if err != nil {
+			return err
+		}
+
 		if expense.ID == uuid.Nil {
 			expense.ID = uuid.New()
 			if err := ClassifyExpendituresWithNewCategory(ctx, batch, expense.Category, expense.ID); err != nil {
@@ -174,6 +263,8 @@ func PersistBudget(ctx context.Context, pool *pgxpool.Pool, budget *model.Budget
 			expense.Fixed,
 			expense.Slack,
 			expense.ID,
+			code,
+			expense.Rollover,
 		)
 	}
 
@@ -194,11 +285,16 @@ func PersistBudget(ctx context.Context, pool *pgxpool.Pool, budget *model.Budget
 	return nil
 }
 
-func upsertResetBudget(ctx context.Context, budget *model.Budget, batch *pgx.Batch) error {
+// upsertBudget queues the upsert of the budget and the deletion of the incomes and expenses that are
+// no longer in it. The rest are upserted, so that only what changed is recorded in the audit log.
+func upsertBudget(ctx context.Context, budget *model.Budget, batch *pgx.Batch) error {
 	// Upsert budget
 	upsertBudgetQuery, upsertBudgetArgs, err := squirrel.Insert("budget").
-		Values(budget.ID, ctxutil.GetUser(ctx), budget.Name).
-		Suffix("ON CONFLICT (id, owner) DO UPDATE SET name = ?", budget.Name).
+		Columns("id", "owner", "name", "period", "period_start", "period_days").
+		Values(budget.ID, ctxutil.GetUser(ctx), budget.Name, budget.Period, budget.PeriodStart, budget.PeriodDays).
+		Suffix(`ON CONFLICT (id, owner) DO UPDATE
+			SET name = EXCLUDED.name, period = EXCLUDED.period, period_start = EXCLUDED.period_start,
+				period_days = EXCLUDED.period_days`).
 		ToSql()
 	if err != nil {
 		return fmt.Errorf("upsert budget SQL error: %w", err)
@@ -206,9 +302,20 @@ func upsertResetBudget(ctx context.Context, budget *model.Budget, batch *pgx.Bat
 
 	batch.Queue(upsertBudgetQuery, upsertBudgetArgs...)
 
-	// Delete incomes
+	sources := make([]string, len(budget.Incomes))
+	for i, income := range budget.Incomes {
+		sources[i] = income.Source
+	}
+
+	categories := make([]string, len(budget.Expenses))
+	for i, expense := range budget.Expenses {
+		categories[i] = expense.Category
+	}
+
+	// Delete removed incomes
 	deleteIncomes, deleteIncomesArgs, err := squirrel.Delete("income").
 		Where(squirrel.Eq{"owner": budget.ID}).
+		Where(squirrel.NotEq{"source": sources}).
 		ToSql()
 	if err != nil {
 		return fmt.Errorf("delete incomes SQL error: %w", err)
@@ -216,9 +323,10 @@ func upsertResetBudget(ctx context.Context, budget *model.Budget, batch *pgx.Bat
 
 	batch.Queue(deleteIncomes, deleteIncomesArgs...)
 
-	// Delete expenses
+	// Delete removed expenses
 	deleteExpenses, deleteExpensesArgs, err := squirrel.Delete("expense").
 		Where(squirrel.Eq{"budget_id": budget.ID}).
+		Where(squirrel.NotEq{"category": categories}).
 		ToSql()
 	if err != nil {
 		return fmt.Errorf("delete expenses SQL error: %w", err)
@@ -229,17 +337,28 @@ func upsertResetBudget(ctx context.Context, budget *model.Budget, batch *pgx.Bat
 	return nil
 }
 
-func DeleteBudget(ctx context.Context, pool *pgxpool.Pool, budget *model.Budget) error {
+// DeleteBudget moves the budget to the trash. Its incomes and expenses are kept with it until it is
+// restored or purged. If reassignTo is not nil, the expenditures counted towards the budget's expenses
+// are counted towards the expenses of reassignTo with the same categories instead.
+func DeleteBudget(ctx context.Context, pool *pgxpool.Pool, budget, reassignTo *model.Budget) error {
+	user := ctxutil.GetUser(ctx)
+
 	batch := &pgx.Batch{}
-	batch.Queue(deleteBudget, ctxutil.GetUser(ctx), budget.ID)
-	batch.Queue(deleteIncomeByOwner, budget.ID)
-	batch.Queue(deleteExpenseByBudget, budget.ID)
+	if reassignTo != nil {
+		for _, query := range reassignExpenditures {
+			batch.Queue(query, user, budget.ID, reassignTo.ID)
+		}
+	}
+
+	batch.Queue(trashBudget, user, budget.ID)
 
 	tx, err := pool.Begin(ctx)
 	if err != nil {
 		return fmt.Errorf("could not start transaction: %w", err)
 	}
 
+	defer func() { _ = tx.Rollback(ctx) }()
+
 	if err = tx.SendBatch(ctx, batch).Close(); err != nil {
 		return fmt.Errorf("batch operation failed during delete budget: %w", err)
 	}
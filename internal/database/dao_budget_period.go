@@ -0,0 +1,318 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"sort"
+	"strings"
+	"time"
+	"yaba/errors"
+	"yaba/internal/ctxutil"
+	"yaba/internal/model"
+
+	"github.com/georgysavva/scany/v2/pgxscan"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+const listBudgetPeriods = `
+SELECT id, budget_id, start_date, end_date FROM budget_period
+WHERE budget_id = $1
+  AND end_date >= $2
+ORDER BY start_date
+`
+
+const listBudgetAllocations = `
+SELECT a.* FROM budget_allocation a
+JOIN budget_period p ON p.id = a.period_id
+WHERE p.budget_id = $1
+  AND p.end_date >= $2
+ORDER BY a.category
+`
+
+// deleteStaleBudgetPeriods deletes the snapshots of budget $1 if its period settings are changing to
+// $2, $3 and $4, since they are of periods the budget no longer has.
+const deleteStaleBudgetPeriods = `
+DELETE FROM budget_period
+WHERE budget_id = $1
+  AND EXISTS (SELECT 1 FROM budget b
+              WHERE b.id = $1
+                AND (b.period != $2 OR b.period_start != $3 OR b.period_days != $4))
+`
+
+// snapshotBudgetPeriods saves the periods of budget $1 from dates $2 to dates $3 that haven't been
+// saved, with the budget's expenses, unless the budget doesn't exist yet.
+const snapshotBudgetPeriods = `
+WITH period AS (
+    INSERT INTO budget_period (id, budget_id, start_date, end_date)
+    SELECT gen_random_uuid(), b.id, p.start_date, p.end_date
+    FROM budget b, unnest($2::DATE[], $3::DATE[]) AS p(start_date, end_date)
+    WHERE b.id = $1 AND b.owner = $4
+    ON CONFLICT (budget_id, start_date, end_date) DO NOTHING
+    RETURNING id
+)
+INSERT INTO budget_allocation (period_id, expense_id, category, amount, currency, rollover)
+SELECT period.id, e.id, e.category, e.amount, e.currency, e.rollover
+FROM period JOIN expense e ON e.budget_id = $1
+`
+
+// getExchangeRates returns the rates from currencies $3 to currency $2 on dates $4 for user $1, as text
+// so that no digits are lost.
+const getExchangeRates = `
+SELECT c.currency, c.date, exchange_rate_on($1, c.currency, $2, c.date)::TEXT AS rate
+FROM unnest($3::VARCHAR[], $4::DATE[]) AS c(currency, date)
+`
+
+type exchangeRateOn struct {
+	Currency string    `db:"currency"`
+	Date     time.Time `db:"date"`
+	Rate     *string   `db:"rate"`
+}
+
+// GetBudgetPeriod returns the period of the user's budget that contains the date, with what was
+// allocated to, spent on and rolled over into each expense. A period that has ended has the expenses
+// the budget had when it ended, and the others have the budget's current expenses. Spending counts
+// towards every expense with its category, in the home currency, like aggregated expenditures.
+func GetBudgetPeriod(
+	ctx context.Context,
+	pool *pgxpool.Pool,
+	budgetID uuid.UUID,
+	date time.Time,
+) (*model.BudgetPeriod, error) {
+	budget, err := GetBudget(ctx, pool, ctxutil.GetUser(ctx), budgetID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Rollover depends on every period before this one, so they are all read.
+	periods, err := budget.Periods(date)
+	if err != nil {
+		return nil, err
+	}
+
+	first, last := periods[0], periods[len(periods)-1]
+
+	if err = allocateBudgetPeriods(ctx, pool, budget, periods); err != nil {
+		return nil, err
+	}
+
+	if err = convertBudgetAllocations(ctx, pool, periods); err != nil {
+		return nil, err
+	}
+
+	spending, err := aggregateExpenditures(ctx, pool, first.Start, last.End, model.TimespanDay,
+		model.AggregationSum, model.GroupByBudgetCategory, true, model.RefundAttributionOriginal, false)
+	if err != nil {
+		return nil, err
+	}
+
+	spent := make([]map[string]model.Money, len(periods))
+	for i := range periods {
+		spent[i] = make(map[string]model.Money)
+	}
+
+	for _, summary := range spending {
+		i := sort.Search(len(periods), func(i int) bool { return !periods[i].End.Before(summary.StartDate.UTC()) })
+		if i < len(periods) && !summary.StartDate.UTC().Before(periods[i].Start) {
+			spent[i][summary.Category] += summary.Amount
+		}
+	}
+
+	// Left is what is left of each category at the end of the previous period.
+	left := make(map[string]model.Money)
+
+	for i, period := range periods {
+		for _, allocation := range period.Allocations {
+			category := strings.ToLower(allocation.Category)
+
+			allocation.Spent = spent[i][category]
+			if allocation.Rollover {
+				allocation.RolledOver = left[category]
+			}
+		}
+
+		left = make(map[string]model.Money, len(period.Allocations))
+		for _, allocation := range period.Allocations {
+			left[strings.ToLower(allocation.Category)] = allocation.Remaining()
+		}
+	}
+
+	return last, nil
+}
+
+// allocateBudgetPeriods gives each period the expenses of the first snapshot that ended with or after
+// it, since the budget's expenses can't have changed in between, or the budget's current expenses if
+// there is none.
+func allocateBudgetPeriods(
+	ctx context.Context,
+	pool *pgxpool.Pool,
+	budget *model.Budget,
+	periods []*model.BudgetPeriod,
+) error {
+	start := periods[0].Start
+
+	var saved []*model.BudgetPeriod
+	if err := pgxscan.Select(ctx, pool, &saved, listBudgetPeriods, budget.ID, start); err != nil {
+		return fmt.Errorf("failed to get budget periods: %w", err)
+	}
+
+	var allocations []*model.BudgetAllocation
+	if err := pgxscan.Select(ctx, pool, &allocations, listBudgetAllocations, budget.ID, start); err != nil {
+		return fmt.Errorf("failed to get budget allocations: %w", err)
+	}
+
+	byID := make(map[uuid.UUID]*model.BudgetPeriod, len(saved))
+	for _, period := range saved {
+		byID[period.ID] = period
+	}
+
+	for _, allocation := range allocations {
+		if period, ok := byID[allocation.PeriodID]; ok {
+			period.Allocations = append(period.Allocations, allocation)
+		}
+	}
+
+	current := make([]*model.BudgetAllocation, len(budget.Expenses))
+	for i, expense := range budget.Expenses {
+		current[i] = &model.BudgetAllocation{
+			ExpenseID: expense.ID,
+			Category:  expense.Category,
+			Amount:    expense.Amount,
+			Currency:  expense.Currency,
+			Rollover:  expense.Rollover,
+		}
+	}
+
+	next := 0
+
+	for _, period := range periods {
+		for next < len(saved) && saved[next].End.UTC().Before(period.End) {
+			next++
+		}
+
+		snapshot := current
+		if next < len(saved) {
+			snapshot = saved[next].Allocations
+			if saved[next].Start.UTC().Equal(period.Start) {
+				period.ID = saved[next].ID
+			}
+		}
+
+		// Each period has its own copies, since what was spent differs.
+		period.Allocations = make([]*model.BudgetAllocation, len(snapshot))
+		for i, allocation := range snapshot {
+			copied := *allocation
+			copied.PeriodID = period.ID
+			period.Allocations[i] = &copied
+		}
+	}
+
+	return nil
+}
+
+// convertBudgetAllocations sets the allocations' amounts in the home currency, at the rate on the
+// first day of their periods. Amounts are multiplied exactly, like the NUMERIC conversions of
+// spending they are compared with.
+func convertBudgetAllocations(ctx context.Context, pool *pgxpool.Pool, periods []*model.BudgetPeriod) error {
+	home, err := GetHomeCurrency(ctx, pool)
+	if err != nil {
+		return err
+	}
+
+	type key struct {
+		currency string
+		date     time.Time
+	}
+
+	rates := make(map[key]*big.Rat)
+
+	var currencies []string
+
+	var dates []time.Time
+
+	for _, period := range periods {
+		for _, allocation := range period.Allocations {
+			k := key{allocation.Currency, period.Start}
+			if _, ok := rates[k]; ok || allocation.Currency == "" || allocation.Currency == home {
+				continue
+			}
+
+			rates[k] = nil
+			currencies = append(currencies, k.currency)
+			dates = append(dates, k.date)
+		}
+	}
+
+	if len(currencies) > 0 {
+		var found []*exchangeRateOn
+		if err = pgxscan.Select(ctx, pool, &found, getExchangeRates,
+			ctxutil.GetUser(ctx), home, currencies, dates); err != nil {
+			return fmt.Errorf("failed to get exchange rates: %w", err)
+		}
+
+		for _, rate := range found {
+			if rate.Rate == nil {
+				continue
+			}
+
+			parsed, ok := new(big.Rat).SetString(*rate.Rate)
+			if !ok {
+				return fmt.Errorf("failed to parse exchange rate %s", *rate.Rate)
+			}
+
+			rates[key{rate.Currency, rate.Date.UTC()}] = parsed
+		}
+	}
+
+	for _, period := range periods {
+		for _, allocation := range period.Allocations {
+			if allocation.Currency == "" || allocation.Currency == home {
+				allocation.HomeAmount = allocation.Amount
+
+				continue
+			}
+
+			rate := rates[key{allocation.Currency, period.Start}]
+			if rate == nil {
+				return fmt.Errorf("failed to convert the %s expense: %w", allocation.Category, errors.InvalidStateError{
+					Message: fmt.Sprintf("no exchange rate from %s to %s is known", allocation.Currency, home),
+				})
+			}
+
+			if allocation.HomeAmount, err = allocation.Amount.MulRat(rate); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// queueBudgetPeriodSnapshot queues the snapshots of the budget's periods that have ended and haven't
+// been saved, with the expenses the budget has before it is persisted. Snapshots of the budget's old
+// periods are deleted first if its period settings change.
+func queueBudgetPeriodSnapshot(ctx context.Context, budget *model.Budget, batch *pgx.Batch) error {
+	batch.Queue(deleteStaleBudgetPeriods, budget.ID, budget.Period, budget.PeriodStart, budget.PeriodDays)
+
+	ended, err := budget.EndedPeriods(time.Now().UTC())
+	if err != nil {
+		return err
+	}
+
+	if len(ended) == 0 {
+		return nil
+	}
+
+	starts := make([]time.Time, len(ended))
+	ends := make([]time.Time, len(ended))
+
+	for i, period := range ended {
+		starts[i], ends[i] = period.Start, period.End
+	}
+
+	batch.Queue(snapshotBudgetPeriods, budget.ID, starts, ends, ctxutil.GetUser(ctx))
+
+	return nil
+}
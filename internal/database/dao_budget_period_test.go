@@ -0,0 +1,265 @@
+package database_test
+
+import (
+	"testing"
+	"time"
+	"yaba/errors"
+	"yaba/internal/ctxutil"
+	"yaba/internal/database"
+	"yaba/internal/model"
+	"yaba/internal/test/helper"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetBudgetPeriod(t *testing.T) {
+	t.Parallel()
+
+	pool := helper.GetTestPool()
+	owner := uuid.New()
+	ctx := ctxutil.WithUser(t.Context(), owner)
+	thisMonth := model.StartOfMonth(time.Now())
+
+	budget := model.NewBudget(owner, "monthly")
+	budget.PeriodStart = thisMonth.AddDate(0, -2, 0)
+	budget.SetFixedExpense("food", model.MoneyFromFloat(500))
+	budget.SetFixedExpense("rent", model.MoneyFromFloat(1500))
+	budget.Expenses[0].Rollover = true
+	require.NoError(t, database.PersistBudget(ctx, pool, budget))
+
+	day := func(months, days int) time.Time { return thisMonth.AddDate(0, months, days) }
+	expenditures := []*model.Expenditure{
+		{Owner: owner, Name: "Groceries", Amount: model.MoneyFromFloat(450), Date: day(-2, 9), BudgetCategory: "food"},
+		{Owner: owner, Name: "Groceries", Amount: model.MoneyFromFloat(600), Date: day(-1, 9), BudgetCategory: "food"},
+		{Owner: owner, Name: "Landlord", Amount: model.MoneyFromFloat(1500), Date: day(-1, 0), BudgetCategory: "rent"},
+	}
+	require.NoError(t, database.PersistExpenditures(ctx, pool, expenditures))
+
+	type amounts struct{ allocated, spent, rolledOver, remaining float64 }
+
+	requireAmounts := func(period *model.BudgetPeriod, expected map[string]amounts) {
+		t.Helper()
+
+		actual := make(map[string]amounts, len(period.Allocations))
+		for _, allocation := range period.Allocations {
+			actual[allocation.Category] = amounts{
+				allocation.Amount.Float64(),
+				allocation.Spent.Float64(),
+				allocation.RolledOver.Float64(),
+				allocation.Remaining().Float64(),
+			}
+		}
+
+		require.Equal(t, expected, actual)
+	}
+
+	period, err := database.GetBudgetPeriod(ctx, pool, budget.ID, day(-1, 14))
+	require.NoError(t, err)
+	require.Equal(t, day(-1, 0), period.Start)
+	require.Equal(t, day(0, -1), period.End)
+	requireAmounts(period, map[string]amounts{
+		"food": {500, 600, 50, -50},
+		"rent": {1500, 1500, 0, 0},
+	})
+
+	// Overspending is carried into the next period too.
+	period, err = database.GetBudgetPeriod(ctx, pool, budget.ID, day(0, 0))
+	require.NoError(t, err)
+	requireAmounts(period, map[string]amounts{
+		"food": {500, 0, -50, 450},
+		"rent": {1500, 0, 0, 1500},
+	})
+
+	// Changing the budget only changes the periods that haven't ended.
+	budget.Expenses[0].Amount = model.MoneyFromFloat(700)
+	require.NoError(t, database.PersistBudget(ctx, pool, budget))
+
+	period, err = database.GetBudgetPeriod(ctx, pool, budget.ID, day(0, 0))
+	require.NoError(t, err)
+	requireAmounts(period, map[string]amounts{
+		"food": {700, 0, -50, 650},
+		"rent": {1500, 0, 0, 1500},
+	})
+
+	// Spending counts towards every budget with the category, whatever its case.
+	other := model.NewBudget(owner, "other")
+	other.PeriodStart = budget.PeriodStart
+	other.SetFixedExpense("FOOD", model.MoneyFromFloat(1000))
+	require.NoError(t, database.PersistBudget(ctx, pool, other))
+
+	period, err = database.GetBudgetPeriod(ctx, pool, other.ID, day(-1, 14))
+	require.NoError(t, err)
+	requireAmounts(period, map[string]amounts{
+		"FOOD": {1000, 600, 0, 400},
+	})
+
+	_, err = database.GetBudgetPeriod(ctx, pool, budget.ID, day(-3, 0))
+	require.ErrorAs(t, err, &errors.NoSuchElementError{})
+
+	// Other users can't read the budget's periods.
+	_, err = database.GetBudgetPeriod(ctxutil.WithUser(t.Context(), uuid.New()), pool, budget.ID, day(0, 0))
+	require.ErrorAs(t, err, &errors.NoSuchElementError{})
+}
+
+func TestSnapshotUnsavedBudgetPeriods(t *testing.T) {
+	t.Parallel()
+
+	pool := helper.GetTestPool()
+	owner := uuid.New()
+	ctx := ctxutil.WithUser(t.Context(), owner)
+	thisMonth := model.StartOfMonth(time.Now())
+
+	// The budget isn't saved again while its first three periods go by.
+	budget := model.NewBudget(owner, "left alone")
+	budget.PeriodStart = thisMonth.AddDate(0, -3, 0)
+	budget.SetFixedExpense("fun", model.MoneyFromFloat(100))
+	require.NoError(t, database.PersistBudget(ctx, pool, budget))
+
+	budget.Expenses[0].Amount = model.MoneyFromFloat(250)
+	require.NoError(t, database.PersistBudget(ctx, pool, budget))
+
+	// Every period that ended is saved with the expenses from before the change.
+	var saved int
+	require.NoError(t, pool.QueryRow(ctx, "SELECT COUNT(*) FROM budget_period WHERE budget_id = $1",
+		budget.ID).Scan(&saved))
+	require.Equal(t, 3, saved)
+
+	for months := -3; months < 0; months++ {
+		period, err := database.GetBudgetPeriod(ctx, pool, budget.ID, thisMonth.AddDate(0, months, 0))
+		require.NoError(t, err)
+		require.Len(t, period.Allocations, 1)
+		require.Equal(t, model.MoneyFromFloat(100), period.Allocations[0].Amount, months)
+	}
+
+	period, err := database.GetBudgetPeriod(ctx, pool, budget.ID, thisMonth)
+	require.NoError(t, err)
+	require.Len(t, period.Allocations, 1)
+	require.Equal(t, model.MoneyFromFloat(250), period.Allocations[0].Amount)
+}
+
+func TestGetCustomBudgetPeriod(t *testing.T) {
+	t.Parallel()
+
+	pool := helper.GetTestPool()
+	owner := uuid.New()
+	ctx := ctxutil.WithUser(t.Context(), owner)
+	start := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	budget := model.NewBudget(owner, "ten days")
+	budget.Period = model.BudgetPeriodCustom
+	budget.PeriodStart = start
+	budget.PeriodDays = 10
+	budget.SetBasicExpense("fun", model.MoneyFromFloat(100))
+	require.NoError(t, database.PersistBudget(ctx, pool, budget))
+
+	expenditures := []*model.Expenditure{
+		{Owner: owner, Name: "Movie", Amount: model.MoneyFromFloat(20), Date: start.AddDate(0, 0, 9), BudgetCategory: "fun"},
+		{Owner: owner, Name: "Concert", Amount: model.MoneyFromFloat(80), Date: start.AddDate(0, 0, 10), BudgetCategory: "fun"},
+	}
+	require.NoError(t, database.PersistExpenditures(ctx, pool, expenditures))
+
+	period, err := database.GetBudgetPeriod(ctx, pool, budget.ID, start.AddDate(0, 0, 15))
+	require.NoError(t, err)
+	require.Equal(t, start.AddDate(0, 0, 10), period.Start)
+	require.Equal(t, start.AddDate(0, 0, 19), period.End)
+	require.Len(t, period.Allocations, 1)
+	require.Equal(t, model.MoneyFromFloat(80), period.Allocations[0].Spent)
+	require.Equal(t, model.Money(0), period.Allocations[0].RolledOver)
+
+	budget.PeriodDays = 0
+	require.ErrorAs(t, database.PersistBudget(ctx, pool, budget), &errors.InvalidInputError{})
+}
+
+func TestConvertBudgetPeriodExactly(t *testing.T) {
+	t.Parallel()
+
+	pool := helper.GetTestPool()
+	owner := uuid.New()
+	ctx := ctxutil.WithUser(t.Context(), owner)
+	start := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	require.NoError(t, database.CreateUser(ctx, pool, &model.User{ID: owner, Username: owner.String()}))
+	_, err := database.SetHomeCurrency(ctx, pool, "XPH")
+	require.NoError(t, err)
+	_, err = database.SaveExchangeRates(ctx, pool, []*model.ExchangeRate{
+		{Date: start, Base: "XPE", Quote: "XPH", Rate: 1.10005},
+	})
+	require.NoError(t, err)
+
+	budget := model.NewBudget(owner, "fees")
+	budget.PeriodStart = start
+	budget.SetBasicExpense("fees", model.NewMoney(5, 0))
+	budget.Expenses[0].Currency = "XPE"
+	require.NoError(t, database.PersistBudget(ctx, pool, budget))
+
+	// 5.00 at 1.10005 is exactly 5.50025, which rounds up, where float64 would round down.
+	period, err := database.GetBudgetPeriod(ctx, pool, budget.ID, start)
+	require.NoError(t, err)
+	require.Len(t, period.Allocations, 1)
+	require.Equal(t, model.Money(55003), period.Allocations[0].HomeAmount)
+}
+
+func TestChangeBudgetPeriod(t *testing.T) {
+	t.Parallel()
+
+	pool := helper.GetTestPool()
+	owner := uuid.New()
+	ctx := ctxutil.WithUser(t.Context(), owner)
+	start := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	require.NoError(t, database.CreateUser(ctx, pool, &model.User{ID: owner, Username: owner.String()}))
+	_, err := database.SetHomeCurrency(ctx, pool, "XPH")
+	require.NoError(t, err)
+	_, err = database.SaveExchangeRates(ctx, pool, []*model.ExchangeRate{
+		{Date: start, Base: "XPE", Quote: "XPH", Rate: 2},
+	})
+	require.NoError(t, err)
+
+	budget := model.NewBudget(owner, "travel")
+	budget.PeriodStart = start
+	budget.SetBasicExpense("travel", model.MoneyFromFloat(100))
+	budget.Expenses[0].Currency = "XPE"
+	budget.Expenses[0].Rollover = true
+	require.NoError(t, database.PersistBudget(ctx, pool, budget))
+
+	require.NoError(t, database.PersistExpenditures(ctx, pool, []*model.Expenditure{
+		{
+			Owner: owner, Name: "Train", Amount: model.MoneyFromFloat(150), Date: start.AddDate(0, 0, 9),
+			BudgetCategory: "travel",
+		},
+	}))
+
+	// The allocation is converted to the home currency before what was spent is taken from it.
+	period, err := database.GetBudgetPeriod(ctx, pool, budget.ID, start.AddDate(0, 1, 9))
+	require.NoError(t, err)
+	require.Len(t, period.Allocations, 1)
+	require.Equal(t, model.MoneyFromFloat(100), period.Allocations[0].Amount)
+	require.Equal(t, model.MoneyFromFloat(200), period.Allocations[0].HomeAmount)
+	require.Equal(t, model.MoneyFromFloat(50), period.Allocations[0].RolledOver)
+	require.Equal(t, model.MoneyFromFloat(250), period.Allocations[0].Remaining())
+
+	// Saving the budget keeps the periods that have ended at 100.
+	budget.Expenses[0].Amount = model.MoneyFromFloat(300)
+	require.NoError(t, database.PersistBudget(ctx, pool, budget))
+
+	// Changing the period settings drops the snapshots of the old periods, and keeps the new periods
+	// that have ended at the expenses the budget had before.
+	budget.Period = model.BudgetPeriodBiweekly
+	require.NoError(t, database.PersistBudget(ctx, pool, budget))
+
+	period, err = database.GetBudgetPeriod(ctx, pool, budget.ID, start.AddDate(0, 0, 20))
+	require.NoError(t, err)
+	require.Equal(t, start.AddDate(0, 0, 14), period.Start)
+	require.Equal(t, start.AddDate(0, 0, 27), period.End)
+	require.Len(t, period.Allocations, 1)
+	require.Equal(t, model.MoneyFromFloat(300), period.Allocations[0].Amount)
+	require.Equal(t, model.MoneyFromFloat(450), period.Allocations[0].RolledOver)
+
+	// Expenses in a currency without a known rate can't be converted.
+	budget.Expenses[0].Currency = "XPZ"
+	require.NoError(t, database.PersistBudget(ctx, pool, budget))
+
+	_, err = database.GetBudgetPeriod(ctx, pool, budget.ID, time.Now())
+	require.ErrorAs(t, err, &errors.InvalidStateError{})
+}
@@ -22,11 +22,11 @@ func TestBasicBudgetOperations(t *testing.T) {
 
 	// Create and save a budget
 	b := model.NewBudget(owner, "name")
-	b.SetBudgetIncome("work", 5000)
-	b.SetBudgetIncome("gig", 1000)
-	b.SetFixedExpense("housing", 1500)
-	b.SetFixedExpense("food", 1000)
-	b.SetBasicExpense("savings", 20)
+	b.SetBudgetIncome("work", model.MoneyFromFloat(5000))
+	b.SetBudgetIncome("gig", model.MoneyFromFloat(1000))
+	b.SetFixedExpense("housing", model.MoneyFromFloat(1500))
+	b.SetFixedExpense("food", model.MoneyFromFloat(1000))
+	b.SetBasicExpense("savings", model.MoneyFromFloat(20))
 	b.SetSlackExpense("fun")
 	require.NoError(t, database.PersistBudget(ctx, pool, b))
 
@@ -51,7 +51,7 @@ func TestBasicBudgetOperations(t *testing.T) {
 
 	// Change the budget and save it
 	b.RemoveExpense("savings")
-	b.SetFixedExpense("dance", 200)
+	b.SetFixedExpense("dance", model.MoneyFromFloat(200))
 	b.RemoveBudgetIncome("gig")
 	require.NotEqual(t, budgets[0], b)
 	require.NoError(t, database.PersistBudget(ctx, pool, b))
@@ -66,7 +66,7 @@ func TestBasicBudgetOperations(t *testing.T) {
 	require.Len(t, budgets[0].Expenses, 4)
 
 	// Delete the budget
-	require.NoError(t, database.DeleteBudget(ctx, pool, b))
+	require.NoError(t, database.DeleteBudget(ctx, pool, b, nil))
 	budgets, err = database.GetBudgets(ctx, pool, owner, 10)
 	require.NoError(t, err)
 	require.Empty(t, budgets)
@@ -92,7 +92,7 @@ func TestPersistUnauthorizedBudget(t *testing.T) {
 
 	// Create initial budget with original owner
 	b := model.NewBudget(owner, "original")
-	b.SetBudgetIncome("work", 1000)
+	b.SetBudgetIncome("work", model.MoneyFromFloat(1000))
 
 	ctx := ctxutil.WithUser(t.Context(), owner)
 	require.NoError(t, database.PersistBudget(ctx, pool, b))
@@ -123,7 +123,7 @@ func TestPersistBudgetClassifiesExpenditures(t *testing.T) {
 		{
 			Owner:          owner,
 			Name:           "Walmart",
-			Amount:         50.00,
+			Amount:         model.MoneyFromFloat(50.00),
 			Date:           time.Now(),
 			BudgetCategory: "groceries",
 			ExpenseID:      existingExpenseID, // Already classified
@@ -131,14 +131,14 @@ func TestPersistBudgetClassifiesExpenditures(t *testing.T) {
 		{
 			Owner:          owner,
 			Name:           "Target",
-			Amount:         30.00,
+			Amount:         model.MoneyFromFloat(30.00),
 			Date:           time.Now(),
 			BudgetCategory: "groceries", // Unclassified
 		},
 		{
 			Owner:          owner,
 			Name:           "Netflix",
-			Amount:         15.00,
+			Amount:         model.MoneyFromFloat(15.00),
 			Date:           time.Now(),
 			BudgetCategory: "entertainment", // Unclassified
 		},
@@ -147,13 +147,13 @@ func TestPersistBudgetClassifiesExpenditures(t *testing.T) {
 
 	// Create and persist a new budget
 	budget := model.NewBudget(owner, "test budget")
-	budget.SetBasicExpense("groceries", 500)
-	budget.SetBasicExpense("entertainment", 100)
+	budget.SetBasicExpense("groceries", model.MoneyFromFloat(500))
+	budget.SetBasicExpense("entertainment", model.MoneyFromFloat(100))
 	require.NoError(t, database.PersistBudget(ctx, pool, budget))
 
 	// Verify expenditures were updated
 	fetched, err := database.ListExpenditures(ctx, pool,
-		nil, nil, nil, nil, time.Now().AddDate(0, 0, -1), time.Now().AddDate(0, 0, 1), nil, nil)
+		nil, nil, nil, nil, nil, time.Now().AddDate(0, 0, -1), time.Now().AddDate(0, 0, 1), nil, nil)
 	require.NoError(t, err)
 	require.Len(t, fetched, 3)
 
@@ -196,8 +196,8 @@ func TestPersistBudgetClassifiesExpendituresWithExistingBudget(t *testing.T) {
 		{
 			name: "new category added",
 			initialExpenditures: []*model.Expenditure{
-				{Name: "Walmart", Amount: 50.00, BudgetCategory: "groceries"},
-				{Name: "Netflix", Amount: 15.00, BudgetCategory: "entertainment"},
+				{Name: "Walmart", Amount: model.MoneyFromFloat(50.00), BudgetCategory: "groceries"},
+				{Name: "Netflix", Amount: model.MoneyFromFloat(15.00), BudgetCategory: "entertainment"},
 			},
 			existingExpenses: []string{"groceries"},
 			newExpenses:      []string{"entertainment"},
@@ -209,9 +209,9 @@ func TestPersistBudgetClassifiesExpendituresWithExistingBudget(t *testing.T) {
 		{
 			name: "multiple unclassified for same category",
 			initialExpenditures: []*model.Expenditure{
-				{Name: "Walmart", Amount: 50.00, BudgetCategory: "groceries"},
-				{Name: "Target", Amount: 30.00, BudgetCategory: "groceries"},
-				{Name: "Corner Store", Amount: 10.00, BudgetCategory: "groceries"},
+				{Name: "Walmart", Amount: model.MoneyFromFloat(50.00), BudgetCategory: "groceries"},
+				{Name: "Target", Amount: model.MoneyFromFloat(30.00), BudgetCategory: "groceries"},
+				{Name: "Corner Store", Amount: model.MoneyFromFloat(10.00), BudgetCategory: "groceries"},
 			},
 			existingExpenses: []string{"rent"},
 			newExpenses:      []string{"groceries"},
@@ -224,15 +224,15 @@ func TestPersistBudgetClassifiesExpendituresWithExistingBudget(t *testing.T) {
 			initialExpenditures: []*model.Expenditure{
 				{
 					Name:           "Walmart",
-					Amount:         50.00,
+					Amount:         model.MoneyFromFloat(50.00),
 					BudgetCategory: "groceries",
 				}, // Will be classified
 				{
 					Name:           "Netflix",
-					Amount:         15.00,
+					Amount:         model.MoneyFromFloat(15.00),
 					BudgetCategory: "entertainment",
 				}, // Will be classified
-				{Name: "Rent", Amount: 12.00, BudgetCategory: "rent"},
+				{Name: "Rent", Amount: model.MoneyFromFloat(12.00), BudgetCategory: "rent"},
 			},
 			existingExpenses: []string{"rent"},
 			newExpenses:      []string{"groceries", "entertainment"},
@@ -263,20 +263,20 @@ func TestPersistBudgetClassifiesExpendituresWithExistingBudget(t *testing.T) {
 			// Create initial budget with existing expenses
 			budget := model.NewBudget(owner, "test budget")
 			for _, category := range tc.existingExpenses {
-				budget.SetBasicExpense(category, 1000)
+				budget.SetBasicExpense(category, model.MoneyFromFloat(1000))
 			}
 
 			require.NoError(t, database.PersistBudget(ctx, pool, budget))
 
 			// Update budget with budget
 			for _, category := range tc.newExpenses {
-				budget.SetBasicExpense(category, 1000)
+				budget.SetBasicExpense(category, model.MoneyFromFloat(1000))
 			}
 
 			require.NoError(t, database.PersistBudget(ctx, pool, budget))
 
 			// Verify classifications
-			fetched, err := database.ListExpenditures(ctx, pool, nil, nil, nil, nil,
+			fetched, err := database.ListExpenditures(ctx, pool, nil, nil, nil, nil, nil,
 				time.Now().AddDate(0, 0, -1), time.Now().AddDate(0, 0, 1), nil, nil)
 			require.NoError(t, err)
 
@@ -313,3 +313,103 @@ func findExpenseByCategory(expenses []*model.Expense, category string) *model.Ex
 
 	return nil
 }
+
+func TestListBudgetPage(t *testing.T) {
+	t.Parallel()
+
+	pool := helper.GetTestPool()
+	owner := uuid.New()
+	ctx := ctxutil.WithUser(t.Context(), owner)
+
+	for _, name := range []string{"vacation", "monthly", "wedding", "monthly 2025"} {
+		require.NoError(t, database.PersistBudget(ctx, pool, model.NewBudget(owner, name)))
+	}
+
+	// Another user's budgets aren't listed.
+	other := uuid.New()
+	require.NoError(t, database.PersistBudget(ctxutil.WithUser(t.Context(), other), pool, model.NewBudget(other, "monthly")))
+
+	var names []string
+
+	var cursor *model.BudgetCursor
+
+	for {
+		page, err := database.ListBudgetPage(ctx, pool, nil, 3, cursor)
+		require.NoError(t, err)
+		require.Equal(t, 4, page.TotalCount)
+
+		for _, budget := range page.Budgets {
+			names = append(names, budget.Name)
+		}
+
+		if !page.HasNextPage {
+			break
+		}
+
+		cursor = model.NewBudgetCursor(page.Budgets[len(page.Budgets)-1])
+	}
+
+	require.Equal(t, []string{"monthly", "monthly 2025", "vacation", "wedding"}, names)
+
+	name := "MONTH"
+	page, err := database.ListBudgetPage(ctx, pool, &name, 10, nil)
+	require.NoError(t, err)
+	require.Equal(t, 2, page.TotalCount)
+	require.False(t, page.HasNextPage)
+	require.Len(t, page.Budgets, 2)
+	require.Equal(t, "monthly", page.Budgets[0].Name)
+	require.Equal(t, "monthly 2025", page.Budgets[1].Name)
+
+	_, err = database.ListBudgetPage(ctx, pool, nil, -1, nil)
+	require.Error(t, err)
+}
+
+func TestDeleteBudgetReassignsExpenditures(t *testing.T) {
+	t.Parallel()
+
+	pool := helper.GetTestPool()
+	owner := uuid.New()
+	ctx := ctxutil.WithUser(t.Context(), owner)
+
+	old := model.NewBudget(owner, "old")
+	old.SetFixedExpense("food", model.MoneyFromFloat(500))
+	old.SetFixedExpense("rent", model.MoneyFromFloat(1500))
+	require.NoError(t, database.PersistBudget(ctx, pool, old))
+
+	expenditures := []*model.Expenditure{
+		{Owner: owner, Name: "Groceries", Amount: model.MoneyFromFloat(80), Date: time.Now(), BudgetCategory: "food"},
+		{Owner: owner, Name: "Landlord", Amount: model.MoneyFromFloat(1500), Date: time.Now(), BudgetCategory: "rent"},
+	}
+	require.NoError(t, database.PersistExpenditures(ctx, pool, expenditures))
+	require.NoError(t, database.SetExpenditureSplits(ctx, pool, expenditures[1].ID, []*model.ExpenditureSplit{
+		{BudgetCategory: "rent", Amount: model.MoneyFromFloat(1000)},
+		{BudgetCategory: "Food", Amount: model.MoneyFromFloat(500)},
+	}))
+
+	// Categories are matched ignoring case.
+	replacement := model.NewBudget(owner, "new")
+	replacement.SetFixedExpense("FOOD", model.MoneyFromFloat(600))
+	require.NoError(t, database.PersistBudget(ctx, pool, replacement))
+
+	require.NoError(t, database.DeleteBudget(ctx, pool, old, replacement))
+
+	food := findExpenseByCategory(replacement.Expenses, "FOOD")
+
+	fetched, err := database.GetExpenditure(ctx, pool, expenditures[0].ID)
+	require.NoError(t, err)
+	require.Equal(t, food.ID, fetched.ExpenseID)
+
+	// Categories that the other budget doesn't have are left unassigned.
+	fetched, err = database.GetExpenditure(ctx, pool, expenditures[1].ID)
+	require.NoError(t, err)
+	require.Equal(t, uuid.Nil, fetched.ExpenseID)
+
+	splits, err := database.ListExpenditureSplits(ctx, pool, expenditures[1].ID)
+	require.NoError(t, err)
+	require.Len(t, splits, 2)
+	require.Equal(t, uuid.Nil, splits[0].ExpenseID)
+	require.Equal(t, food.ID, splits[1].ExpenseID)
+
+	_, err = database.GetBudget(ctx, pool, owner, old.ID)
+	require.ErrorContains(t, err, "no such element")
+}
@@ -0,0 +1,212 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+	"yaba/errors"
+	"yaba/internal/ctxutil"
+	"yaba/internal/model"
+	"yaba/internal/rules"
+
+	"github.com/Masterminds/squirrel"
+	"github.com/georgysavva/scany/v2/pgxscan"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// ListCategorizationRules returns the user's rules, highest priority first.
+func ListCategorizationRules(ctx context.Context, pool *pgxpool.Pool) ([]*model.CategorizationRule, error) {
+	query, args, err := squirrel.Select("*").
+		From("categorization_rule").
+		Where(squirrel.Eq{"owner": ctxutil.GetUser(ctx)}).
+		OrderBy("priority DESC", "created", "id").
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("failed to build query: %w", err)
+	}
+
+	var categorizationRules []*model.CategorizationRule
+	if err = pgxscan.Select(ctx, pool, &categorizationRules, query, args...); err != nil {
+		return nil, fmt.Errorf("failed to list categorization rules: %w", err)
+	}
+
+	return categorizationRules, nil
+}
+
+func GetCategorizationRule(
+	ctx context.Context,
+	pool *pgxpool.Pool,
+	id uuid.UUID,
+) (*model.CategorizationRule, error) {
+	query, args, err := squirrel.Select("*").
+		From("categorization_rule").
+		Where(squirrel.Eq{
+			"id":    id,
+			"owner": ctxutil.GetUser(ctx),
+		}).
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("failed to build query: %w", err)
+	}
+
+	var categorizationRules []*model.CategorizationRule
+	if err = pgxscan.Select(ctx, pool, &categorizationRules, query, args...); err != nil {
+		return nil, fmt.Errorf("failed to get categorization rule: %w", err)
+	}
+
+	if len(categorizationRules) == 0 {
+		return nil, errors.NoSuchElementError{Element: id}
+	}
+
+	return categorizationRules[0], nil
+}
+
+func CreateCategorizationRule(ctx context.Context, pool *pgxpool.Pool, rule *model.CategorizationRule) error {
+	if err := rules.Validate(rule); err != nil {
+		return err
+	}
+
+	rule.Owner = ctxutil.GetUser(ctx)
+
+	query, args, err := squirrel.Insert("categorization_rule").
+		Columns("id", "owner", "priority", "match_type", "pattern", "budget_category",
+			"reward_category", "method").
+		Values(rule.ID, rule.Owner, rule.Priority, rule.MatchType, rule.Pattern, rule.BudgetCategory,
+			rule.RewardCategory, rule.Method).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("failed to build query: %w", err)
+	}
+
+	if _, err = pool.Exec(ctx, query, args...); err != nil {
+		return fmt.Errorf("failed to create categorization rule: %w", err)
+	}
+
+	return nil
+}
+
+func UpdateCategorizationRule(ctx context.Context, pool *pgxpool.Pool, rule *model.CategorizationRule) error {
+	if err := rules.Validate(rule); err != nil {
+		return err
+	}
+
+	query, args, err := squirrel.Update("categorization_rule").
+		Set("priority", rule.Priority).
+		Set("match_type", rule.MatchType).
+		Set("pattern", rule.Pattern).
+		Set("budget_category", rule.BudgetCategory).
+		Set("reward_category", rule.RewardCategory).
+		Set("method", rule.Method).
+		Where(squirrel.Eq{
+			"id":    rule.ID,
+			"owner": ctxutil.GetUser(ctx),
+		}).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("failed to build query: %w", err)
+	}
+
+	tag, err := pool.Exec(ctx, query, args...)
+	if err != nil {
+		return fmt.Errorf("failed to update categorization rule: %w", err)
+	}
+
+	if tag.RowsAffected() == 0 {
+		return errors.NoSuchElementError{Element: rule.ID}
+	}
+
+	return nil
+}
+
+func DeleteCategorizationRule(ctx context.Context, pool *pgxpool.Pool, id uuid.UUID) (bool, error) {
+	query, args, err := squirrel.Delete("categorization_rule").
+		Where(squirrel.Eq{
+			"id":    id,
+			"owner": ctxutil.GetUser(ctx),
+		}).
+		ToSql()
+	if err != nil {
+		return false, fmt.Errorf("failed to build query: %w", err)
+	}
+
+	tag, err := pool.Exec(ctx, query, args...)
+	if err != nil {
+		return false, fmt.Errorf("failed to delete categorization rule: %w", err)
+	}
+
+	return tag.RowsAffected() > 0, nil
+}
+
+// ApplyCategorizationRules reclassifies the user's expenditures between since and until with their
+// rules, replacing existing values, and returns the changes. Nothing is saved if dryRun is true.
+func ApplyCategorizationRules(
+	ctx context.Context,
+	pool *pgxpool.Pool,
+	since, until time.Time,
+	dryRun bool,
+) ([]*model.FieldChange, error) {
+	engine, err := getRulesEngine(ctx, pool)
+	if err != nil {
+		return nil, err
+	}
+
+	expenditures, err := ListExpenditures(ctx, pool, nil, nil, nil, nil, nil, since, until, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	budgetMap, err := getExpenseIDsByCategory(ctx, pool)
+	if err != nil {
+		return nil, err
+	}
+
+	changes := []*model.FieldChange{}
+	batch := &pgx.Batch{}
+
+	for _, e := range expenditures {
+		changed := engine.Apply(e, true)
+		if len(changed) == 0 {
+			continue
+		}
+
+		changes = append(changes, changed...)
+
+		query, args, err := squirrel.Update("expenditure").
+			Set("budget_category", e.BudgetCategory).
+			Set("reward_category", e.RewardCategory).
+			Set("method", e.Method).
+			Set("expense_id", budgetMap[strings.ToLower(e.BudgetCategory)]).
+			Where(squirrel.Eq{
+				"id":    e.ID,
+				"owner": ctxutil.GetUser(ctx),
+			}).
+			ToSql()
+		if err != nil {
+			return nil, fmt.Errorf("failed to build query: %w", err)
+		}
+
+		batch.Queue(query, args...)
+	}
+
+	if dryRun || batch.Len() == 0 {
+		return changes, nil
+	}
+
+	if err = pool.SendBatch(ctx, batch).Close(); err != nil {
+		return nil, fmt.Errorf("failed to apply categorization rules: %w", err)
+	}
+
+	return changes, nil
+}
+
+func getRulesEngine(ctx context.Context, pool *pgxpool.Pool) (*rules.Engine, error) {
+	categorizationRules, err := ListCategorizationRules(ctx, pool)
+	if err != nil {
+		return nil, err
+	}
+
+	return rules.NewEngine(categorizationRules)
+}
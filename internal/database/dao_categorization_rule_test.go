@@ -0,0 +1,133 @@
+package database_test
+
+import (
+	"testing"
+	"time"
+	"yaba/internal/ctxutil"
+	"yaba/internal/database"
+	"yaba/internal/model"
+	"yaba/internal/test/helper"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCategorizationRuleCRUD(t *testing.T) {
+	t.Parallel()
+
+	pool := helper.GetTestPool()
+	ctx := ctxutil.WithUser(t.Context(), uuid.New())
+
+	low := &model.CategorizationRule{ID: uuid.New(), MatchType: model.MatchTypeContains, Pattern: "costco",
+		BudgetCategory: "Groceries"}
+	high := &model.CategorizationRule{ID: uuid.New(), Priority: 5, MatchType: model.MatchTypeRegex,
+		Pattern: "costco gas", BudgetCategory: "Gas"}
+	require.NoError(t, database.CreateCategorizationRule(ctx, pool, low))
+	require.NoError(t, database.CreateCategorizationRule(ctx, pool, high))
+
+	invalid := &model.CategorizationRule{ID: uuid.New(), MatchType: model.MatchTypeRegex, Pattern: "(",
+		BudgetCategory: "Gas"}
+	require.ErrorContains(t, database.CreateCategorizationRule(ctx, pool, invalid), "invalid rule pattern")
+
+	listed, err := database.ListCategorizationRules(ctx, pool)
+	require.NoError(t, err)
+	require.Len(t, listed, 2)
+	require.Equal(t, high.ID, listed[0].ID)
+	require.Equal(t, low.ID, listed[1].ID)
+
+	low.Priority = 10
+	low.RewardCategory = "grocery"
+	require.NoError(t, database.UpdateCategorizationRule(ctx, pool, low))
+
+	fetched, err := database.GetCategorizationRule(ctx, pool, low.ID)
+	require.NoError(t, err)
+	require.Equal(t, 10, fetched.Priority)
+	require.Equal(t, "grocery", fetched.RewardCategory)
+
+	// Other users can't see, change or delete the rule
+	otherCtx := ctxutil.WithUser(t.Context(), uuid.New())
+	_, err = database.GetCategorizationRule(otherCtx, pool, low.ID)
+	require.ErrorContains(t, err, "no such element")
+	require.ErrorContains(t, database.UpdateCategorizationRule(otherCtx, pool, low), "no such element")
+
+	deleted, err := database.DeleteCategorizationRule(otherCtx, pool, low.ID)
+	require.NoError(t, err)
+	require.False(t, deleted)
+
+	deleted, err = database.DeleteCategorizationRule(ctx, pool, low.ID)
+	require.NoError(t, err)
+	require.True(t, deleted)
+
+	listed, err = database.ListCategorizationRules(ctx, pool)
+	require.NoError(t, err)
+	require.Len(t, listed, 1)
+}
+
+func TestApplyCategorizationRules(t *testing.T) {
+	t.Parallel()
+
+	pool := helper.GetTestPool()
+	owner := uuid.New()
+	ctx := ctxutil.WithUser(t.Context(), owner)
+	card := uuid.New()
+	date := time.Date(2024, 8, 1, 0, 0, 0, 0, time.UTC)
+
+	budget := model.NewBudget(owner, "rules budget")
+	budget.SetBasicExpense("Groceries", model.MoneyFromFloat(500))
+	require.NoError(t, database.PersistBudget(ctx, pool, budget))
+
+	// Saved before there were any rules
+	require.NoError(t, database.PersistExpenditures(ctx, pool, []*model.Expenditure{
+		{Owner: owner, Name: "COSTCO #1", Amount: model.MoneyFromFloat(100), Date: date, BudgetCategory: "Household"},
+		{Owner: owner, Name: "Shell", Amount: model.MoneyFromFloat(60), Date: date},
+	}))
+
+	require.NoError(t, database.CreateCategorizationRule(ctx, pool, &model.CategorizationRule{
+		ID: uuid.New(), MatchType: model.MatchTypeContains, Pattern: "costco",
+		BudgetCategory: "Groceries", Method: card,
+	}))
+
+	// New expenditures only have blanks filled in
+	require.NoError(t, database.PersistExpenditures(ctx, pool, []*model.Expenditure{
+		{Owner: owner, Name: "Costco #2", Amount: model.MoneyFromFloat(200), Date: date.AddDate(0, 0, 1)},
+		{Owner: owner, Name: "Costco #3", Amount: model.MoneyFromFloat(300), Date: date.AddDate(0, 0, 1),
+			BudgetCategory: "Gifts"},
+	}))
+
+	stored, err := database.ListExpenditures(ctx, pool, nil, nil, nil, nil, nil, date, date.AddDate(0, 0, 1), nil, nil)
+	require.NoError(t, err)
+	require.Len(t, stored, 4)
+	require.Equal(t, "Gifts", stored[0].BudgetCategory)
+	require.Equal(t, card, stored[0].Method)
+	require.Equal(t, "Groceries", stored[1].BudgetCategory)
+	require.Equal(t, budget.Expenses[0].ID, stored[1].ExpenseID)
+	require.Equal(t, "Household", stored[3].BudgetCategory)
+
+	// A dry run reports what would change without saving it
+	changes, err := database.ApplyCategorizationRules(ctx, pool, date, date.AddDate(0, 0, 1), true)
+	require.NoError(t, err)
+	require.Equal(t, []*model.FieldChange{
+		{ExpenditureID: stored[0].ID, Field: "budget_category", Before: "Gifts", After: "Groceries"},
+		{ExpenditureID: stored[3].ID, Field: "budget_category", Before: "Household", After: "Groceries"},
+		{ExpenditureID: stored[3].ID, Field: "method", Before: uuid.Nil.String(), After: card.String()},
+	}, changes)
+
+	unchanged, err := database.GetExpenditure(ctx, pool, stored[3].ID)
+	require.NoError(t, err)
+	require.Equal(t, "Household", unchanged.BudgetCategory)
+
+	applied, err := database.ApplyCategorizationRules(ctx, pool, date, date.AddDate(0, 0, 1), false)
+	require.NoError(t, err)
+	require.Equal(t, changes, applied)
+
+	updated, err := database.GetExpenditure(ctx, pool, stored[3].ID)
+	require.NoError(t, err)
+	require.Equal(t, "Groceries", updated.BudgetCategory)
+	require.Equal(t, card, updated.Method)
+	require.Equal(t, budget.Expenses[0].ID, updated.ExpenseID)
+
+	// Applying again changes nothing
+	changes, err = database.ApplyCategorizationRules(ctx, pool, date, date.AddDate(0, 0, 1), false)
+	require.NoError(t, err)
+	require.Empty(t, changes)
+}
@@ -0,0 +1,99 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"yaba/internal/classifier"
+	"yaba/internal/ctxutil"
+	"yaba/internal/model"
+
+	"github.com/Masterminds/squirrel"
+	"github.com/georgysavva/scany/v2/pgxscan"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type categoryModel struct {
+	Model             *classifier.Model `db:"model"`
+	LastExpenditureID int               `db:"last_expenditure_id"`
+	Version           int64             `db:"version"`
+	TrainedVersion    int64             `db:"trained_version"`
+}
+
+// GetCategoryModel returns the user's category classifier after training it on the expenditures
+// saved since it was last trained. If any expenditure it already learned changed or was removed since
+// then, it is trained again on all of the user's expenditures.
+func GetCategoryModel(ctx context.Context, pool *pgxpool.Pool) (*classifier.Model, error) {
+	user := ctxutil.GetUser(ctx)
+
+	query, args, err := squirrel.Select("model", "last_expenditure_id", "version", "trained_version").
+		From("category_model").
+		Where(squirrel.Eq{"owner": user}).
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("failed to build query: %w", err)
+	}
+
+	var stored []*categoryModel
+	if err = pgxscan.Select(ctx, pool, &stored, query, args...); err != nil {
+		return nil, fmt.Errorf("failed to get category model: %w", err)
+	}
+
+	current := &categoryModel{Model: classifier.NewModel()}
+	if len(stored) > 0 {
+		current = stored[0]
+	}
+
+	outdated := current.TrainedVersion != current.Version
+	if outdated {
+		current.Model = classifier.NewModel()
+		current.LastExpenditureID = 0
+	}
+
+	query, args, err = squirrel.Select(expenditureColumns...).
+		From("expenditure").
+		Where("owner = ? AND id > ? AND deleted_at IS NULL", user, current.LastExpenditureID).
+		OrderBy("id").
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("failed to build query: %w", err)
+	}
+
+	var expenditures []*model.Expenditure
+	if err = pgxscan.Select(ctx, pool, &expenditures, query, args...); err != nil {
+		return nil, fmt.Errorf("failed to get expenditures: %w", err)
+	}
+
+	if len(expenditures) == 0 && !outdated {
+		return current.Model, nil
+	}
+
+	for _, e := range expenditures {
+		current.Model.Learn(e)
+	}
+
+	if len(expenditures) > 0 {
+		current.LastExpenditureID = expenditures[len(expenditures)-1].ID
+	}
+
+	// A concurrent request may have trained the model further; keep whichever saw more. Nothing is
+	// saved if an expenditure changed while the model was being trained.
+	query, args, err = squirrel.Insert("category_model").
+		Columns("owner", "model", "last_expenditure_id", "version", "trained_version").
+		Values(user, current.Model, current.LastExpenditureID, current.Version, current.Version).
+		Suffix(`ON CONFLICT (owner) DO UPDATE
+			SET model = EXCLUDED.model, last_expenditure_id = EXCLUDED.last_expenditure_id,
+				trained_version = EXCLUDED.trained_version, updated = NOW()
+			WHERE category_model.version = EXCLUDED.version
+			  AND (category_model.trained_version != EXCLUDED.trained_version
+				OR category_model.last_expenditure_id < EXCLUDED.last_expenditure_id)`).
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("failed to build query: %w", err)
+	}
+
+	if _, err = pool.Exec(ctx, query, args...); err != nil {
+		return nil, fmt.Errorf("failed to save category model: %w", err)
+	}
+
+	return current.Model, nil
+}
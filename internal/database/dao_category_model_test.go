@@ -0,0 +1,87 @@
+package database_test
+
+import (
+	"testing"
+	"time"
+	"yaba/internal/ctxutil"
+	"yaba/internal/database"
+	"yaba/internal/model"
+	"yaba/internal/test/helper"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetCategoryModel(t *testing.T) {
+	t.Parallel()
+
+	pool := helper.GetTestPool()
+	owner := uuid.New()
+	ctx := ctxutil.WithUser(t.Context(), owner)
+	date := time.Date(2024, 10, 1, 0, 0, 0, 0, time.UTC)
+
+	categoryModel, err := database.GetCategoryModel(ctx, pool)
+	require.NoError(t, err)
+	require.Empty(t, categoryModel.Suggest("costco").BudgetCategory)
+
+	require.NoError(t, database.PersistExpenditures(ctx, pool, []*model.Expenditure{
+		{Owner: owner, Name: "COSTCO #1", Amount: model.MoneyFromFloat(100), Date: date, BudgetCategory: "Groceries"},
+		{Owner: owner, Name: "Shell #2", Amount: model.MoneyFromFloat(60), Date: date, BudgetCategory: "Gas",
+			RewardCategory: "gas"},
+	}))
+
+	categoryModel, err = database.GetCategoryModel(ctx, pool)
+	require.NoError(t, err)
+	require.Equal(t, "groceries", categoryModel.Suggest("Costco Wholesale").BudgetCategory)
+	require.Equal(t, "gas", categoryModel.Suggest("SHELL OIL").RewardCategory)
+
+	// Only new expenditures are learned
+	require.NoError(t, database.PersistExpenditures(ctx, pool, []*model.Expenditure{
+		{Owner: owner, Name: "Costco gas", Amount: model.MoneyFromFloat(50), Date: date, BudgetCategory: "Gas"},
+	}))
+
+	categoryModel, err = database.GetCategoryModel(ctx, pool)
+	require.NoError(t, err)
+	require.Equal(t, 2, categoryModel.BudgetCategory.Documents["gas"])
+	require.Equal(t, 1, categoryModel.BudgetCategory.Documents["groceries"])
+
+	categoryModel, err = database.GetCategoryModel(ctx, pool)
+	require.NoError(t, err)
+	require.Equal(t, 2, categoryModel.BudgetCategory.Documents["gas"])
+
+	// Changes to expenditures that were already learned are learned again
+	expenditures, err := database.ListExpenditures(ctx, pool, nil, nil, nil, nil, nil, date, date, nil, nil)
+	require.NoError(t, err)
+	require.Len(t, expenditures, 3)
+
+	var costcoGas *model.Expenditure
+
+	for _, expenditure := range expenditures {
+		if expenditure.Name == "Costco gas" {
+			costcoGas = expenditure
+		}
+	}
+
+	require.NotNil(t, costcoGas)
+
+	costcoGas.BudgetCategory = "Groceries"
+	require.NoError(t, database.UpdateExpenditure(ctx, pool, costcoGas))
+
+	categoryModel, err = database.GetCategoryModel(ctx, pool)
+	require.NoError(t, err)
+	require.Equal(t, 1, categoryModel.BudgetCategory.Documents["gas"])
+	require.Equal(t, 2, categoryModel.BudgetCategory.Documents["groceries"])
+
+	// So are expenditures moved to the trash
+	_, err = database.DeleteExpenditures(ctx, pool, []int{costcoGas.ID})
+	require.NoError(t, err)
+
+	categoryModel, err = database.GetCategoryModel(ctx, pool)
+	require.NoError(t, err)
+	require.Equal(t, 1, categoryModel.BudgetCategory.Documents["groceries"])
+
+	// Models are per user
+	otherModel, err := database.GetCategoryModel(ctxutil.WithUser(t.Context(), uuid.New()), pool)
+	require.NoError(t, err)
+	require.Empty(t, otherModel.BudgetCategory.Documents)
+}
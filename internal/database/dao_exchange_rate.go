@@ -0,0 +1,183 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"yaba/errors"
+	"yaba/internal/ctxutil"
+	"yaba/internal/currency"
+	"yaba/internal/model"
+
+	"github.com/Masterminds/squirrel"
+	"github.com/georgysavva/scany/v2/pgxscan"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+const upsertExchangeRate = `
+INSERT INTO exchange_rate (owner, date, base, quote, rate)
+VALUES ($1, $2, $3, $4, $5)
+ON CONFLICT (owner, base, quote, date) DO UPDATE
+SET rate = $5
+`
+
+// getHomeAmounts converts the amounts of expenditures $1 to their owner's home currency at the rate
+// on their dates. An amount is NULL if no rate is known.
+const getHomeAmounts = `
+SELECT e.id, e.amount * exchange_rate_on(e.owner, e.currency, COALESCE(u.home_currency, $3), e.date) AS amount
+FROM expenditure e
+LEFT JOIN user_profile u ON u.id = e.owner
+WHERE e.id = ANY($1)
+  AND e.owner = $2
+  AND e.deleted_at IS NULL
+`
+
+type homeAmount struct {
+	ID     int          `db:"id"`
+	Amount *model.Money `db:"amount"`
+}
+
+// SaveExchangeRates saves the user's rates, replacing any the user saved for the same currencies and
+// date, and returns the number saved. Rates only convert the amounts of the user who saved them.
+func SaveExchangeRates(ctx context.Context, pool *pgxpool.Pool, rates []*model.ExchangeRate) (int, error) {
+	user := ctxutil.GetUser(ctx)
+
+	batch := &pgx.Batch{}
+	for _, rate := range rates {
+		batch.Queue(upsertExchangeRate, user, rate.Date, rate.Base, rate.Quote, rate.Rate)
+	}
+
+	tx, err := pool.Begin(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("could not start transaction: %w", err)
+	}
+
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	if err = tx.SendBatch(ctx, batch).Close(); err != nil {
+		return 0, fmt.Errorf("failed to save exchange rates: %w", err)
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return 0, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return len(rates), nil
+}
+
+// ListExchangeRates returns the user's saved rates between the two currencies, most recent first.
+func ListExchangeRates(ctx context.Context, pool *pgxpool.Pool, base, quote string) ([]*model.ExchangeRate, error) {
+	base, err := currency.Normalize(base)
+	if err != nil {
+		return nil, err
+	}
+
+	if quote, err = currency.Normalize(quote); err != nil {
+		return nil, err
+	}
+
+	query, args, err := squirrel.Select("date", "base", "quote", "rate").
+		From("exchange_rate").
+		Where(squirrel.Eq{"owner": ctxutil.GetUser(ctx), "base": base, "quote": quote}).
+		OrderBy("date DESC").
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("failed to build query: %w", err)
+	}
+
+	var rates []*model.ExchangeRate
+	if err = pgxscan.Select(ctx, pool, &rates, query, args...); err != nil {
+		return nil, fmt.Errorf("failed to get exchange rates: %w", err)
+	}
+
+	return rates, nil
+}
+
+// GetHomeCurrency returns the user's home currency.
+func GetHomeCurrency(ctx context.Context, pool *pgxpool.Pool) (string, error) {
+	query, args, err := squirrel.Select("home_currency").
+		From("user_profile").
+		Where(squirrel.Eq{"id": ctxutil.GetUser(ctx)}).
+		ToSql()
+	if err != nil {
+		return "", fmt.Errorf("failed to build query: %w", err)
+	}
+
+	var codes []string
+	if err = pgxscan.Select(ctx, pool, &codes, query, args...); err != nil {
+		return "", fmt.Errorf("failed to get home currency: %w", err)
+	}
+
+	if len(codes) == 0 {
+		return model.DefaultCurrency, nil
+	}
+
+	return codes[0], nil
+}
+
+// SetHomeCurrency changes the currency that the user's amounts are totalled in.
+func SetHomeCurrency(ctx context.Context, pool *pgxpool.Pool, code string) (string, error) {
+	code, err := currency.Normalize(code)
+	if err != nil {
+		return "", err
+	}
+
+	if code == "" {
+		return "", errors.InvalidInputError{Input: "empty home currency"}
+	}
+
+	user := ctxutil.GetUser(ctx)
+
+	query, args, err := squirrel.Update("user_profile").
+		Set("home_currency", code).
+		Where(squirrel.Eq{"id": user}).
+		ToSql()
+	if err != nil {
+		return "", fmt.Errorf("failed to build query: %w", err)
+	}
+
+	tag, err := pool.Exec(ctx, query, args...)
+	if err != nil {
+		return "", fmt.Errorf("failed to set home currency: %w", err)
+	}
+
+	if tag.RowsAffected() == 0 {
+		return "", errors.NoSuchElementError{Element: user}
+	}
+
+	return code, nil
+}
+
+// GetHomeAmount returns the expenditure's amount in the user's home currency, converted at the rate
+// on the day it was charged, or nil if no exchange rate for its currency is known.
+func GetHomeAmount(ctx context.Context, pool *pgxpool.Pool, id int) (*model.Money, error) {
+	amounts, err := GetHomeAmounts(ctx, pool, []int{id})
+	if err != nil {
+		return nil, err
+	}
+
+	amount, ok := amounts[id]
+	if !ok {
+		return nil, errors.NoSuchElementError{Element: id}
+	}
+
+	return amount, nil
+}
+
+// GetHomeAmounts is GetHomeAmount for several expenditures at once, mapped by ID. Expenditures that
+// don't exist are left out.
+func GetHomeAmounts(ctx context.Context, pool *pgxpool.Pool, ids []int) (map[int]*model.Money, error) {
+	var amounts []*homeAmount
+	if err := pgxscan.Select(
+		ctx, pool, &amounts, getHomeAmounts, ids, ctxutil.GetUser(ctx), model.DefaultCurrency,
+	); err != nil {
+		return nil, fmt.Errorf("failed to convert amounts: %w", err)
+	}
+
+	byID := make(map[int]*model.Money, len(amounts))
+	for _, amount := range amounts {
+		byID[amount.ID] = amount.Amount
+	}
+
+	return byID, nil
+}
@@ -0,0 +1,115 @@
+package database_test
+
+import (
+	"testing"
+	"time"
+	"yaba/internal/ctxutil"
+	"yaba/internal/database"
+	"yaba/internal/model"
+	"yaba/internal/test/helper"
+
+	"github.com/brianvoe/gofakeit/v7"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExchangeRates(t *testing.T) {
+	t.Parallel()
+
+	pool := helper.GetTestPool()
+	owner := uuid.New()
+	ctx := ctxutil.WithUser(t.Context(), owner)
+	day := func(d int) time.Time { return time.Date(2024, 9, d, 0, 0, 0, 0, time.UTC) }
+
+	require.NoError(t, database.CreateUser(ctx, pool, &model.User{ID: owner, Username: gofakeit.Username()}))
+
+	home, err := database.GetHomeCurrency(ctx, pool)
+	require.NoError(t, err)
+	require.Equal(t, model.DefaultCurrency, home)
+
+	_, err = database.SetHomeCurrency(ctx, pool, "XHH")
+	require.NoError(t, err)
+	_, err = database.SetHomeCurrency(ctx, pool, "dollars")
+	require.Error(t, err)
+
+	home, err = database.GetHomeCurrency(ctx, pool)
+	require.NoError(t, err)
+	require.Equal(t, "XHH", home)
+
+	saved, err := database.SaveExchangeRates(ctx, pool, []*model.ExchangeRate{
+		{Date: day(1), Base: "XHE", Quote: "XHH", Rate: 2},
+		{Date: day(10), Base: "XHE", Quote: "XHH", Rate: 3},
+		{Date: day(1), Base: "XHH", Quote: "XHU", Rate: 0.5},
+		{Date: day(1), Base: "XHB", Quote: "XHC", Rate: 4},
+		{Date: day(1), Base: "XHB", Quote: "XHH", Rate: 8},
+	})
+	require.NoError(t, err)
+	require.Equal(t, 5, saved)
+
+	rates, err := database.ListExchangeRates(ctx, pool, "xhe", "xhh")
+	require.NoError(t, err)
+	require.Len(t, rates, 2)
+	require.Equal(t, day(10), rates[0].Date.UTC())
+	require.InDelta(t, 3, rates[0].Rate, 0.0001)
+
+	result, err := database.ImportExpenditures(ctx, pool, []*model.Expenditure{
+		{Owner: owner, Name: "HOME", Amount: model.MoneyFromFloat(10), Date: day(5)},
+		{Owner: owner, Name: "DIRECT", Amount: model.MoneyFromFloat(10), Date: day(5), Currency: "xhe"},
+		{Owner: owner, Name: "LATER RATE", Amount: model.MoneyFromFloat(10), Date: day(12), Currency: "XHE"},
+		{Owner: owner, Name: "INVERSE", Amount: model.MoneyFromFloat(10), Date: day(5), Currency: "XHU"},
+		{Owner: owner, Name: "CROSS", Amount: model.MoneyFromFloat(10), Date: day(5), Currency: "XHC"},
+		{Owner: owner, Name: "UNKNOWN", Amount: model.MoneyFromFloat(10), Date: day(5), Currency: "XHZ"},
+	})
+	require.NoError(t, err)
+	require.Len(t, result.Inserted, 6)
+
+	// The original amount and currency are kept
+	direct, err := database.GetExpenditure(ctx, pool, result.Inserted[1].ID)
+	require.NoError(t, err)
+	require.Equal(t, "XHE", direct.Currency)
+	require.InDelta(t, 10, direct.Amount.Float64(), 0.0001)
+
+	for i, expected := range []float64{10, 20, 30, 20, 20} {
+		amount, err := database.GetHomeAmount(ctx, pool, result.Inserted[i].ID)
+		require.NoError(t, err)
+		require.NotNil(t, amount)
+		require.InDelta(t, expected, amount.Float64(), 0.0001, result.Inserted[i].Name)
+	}
+
+	amount, err := database.GetHomeAmount(ctx, pool, result.Inserted[5].ID)
+	require.NoError(t, err)
+	require.Nil(t, amount)
+
+	// Expenditures without a known rate are left out of the total and counted as unconverted
+	summaries, err := database.AggregateExpenditures(ctx, pool, day(1), day(30), model.TimespanMonth,
+		model.AggregationSum, model.GroupByNone, model.RefundAttributionOriginal, false)
+	require.NoError(t, err)
+	require.Len(t, summaries, 1)
+	require.InDelta(t, 100, summaries[0].Amount.Float64(), 0.0001)
+	require.Equal(t, 1, summaries[0].Unconverted)
+
+	// Rates only convert the amounts of the user who saved them
+	other := uuid.New()
+	otherCtx := ctxutil.WithUser(t.Context(), other)
+	require.NoError(t, database.CreateUser(otherCtx, pool, &model.User{ID: other, Username: gofakeit.Username()}))
+	_, err = database.SetHomeCurrency(otherCtx, pool, "XHH")
+	require.NoError(t, err)
+
+	rates, err = database.ListExchangeRates(otherCtx, pool, "XHE", "XHH")
+	require.NoError(t, err)
+	require.Empty(t, rates)
+
+	result, err = database.ImportExpenditures(otherCtx, pool, []*model.Expenditure{
+		{Owner: other, Name: "DIRECT", Amount: model.MoneyFromFloat(10), Date: day(5), Currency: "XHE"},
+	})
+	require.NoError(t, err)
+
+	amount, err = database.GetHomeAmount(otherCtx, pool, result.Inserted[0].ID)
+	require.NoError(t, err)
+	require.Nil(t, amount)
+
+	_, err = database.ImportExpenditures(ctx, pool, []*model.Expenditure{
+		{Owner: owner, Name: "BAD", Amount: model.MoneyFromFloat(10), Date: day(5), Currency: "EURO"},
+	})
+	require.Error(t, err)
+}
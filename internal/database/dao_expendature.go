@@ -2,11 +2,22 @@ package database
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
+	"log"
+	"slices"
+	"strconv"
 	"strings"
 	"time"
+	"yaba/config"
+	"yaba/errors"
+	"yaba/internal/classifier"
 	"yaba/internal/ctxutil"
+	"yaba/internal/currency"
 	"yaba/internal/model"
+	"yaba/internal/rules"
+	"yaba/internal/search"
 
 	"github.com/google/uuid"
 
@@ -16,37 +27,49 @@ import (
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
+// expenditureColumns are the columns of model.Expenditure. Queries name them rather than selecting
+// everything, which would include the search column.
+var expenditureColumns = []string{ //nolint:gochecknoglobals
+	"id", "owner", "name", "amount", "date", "method", "budget_category", "reward_category", "comment",
+	"created", "source", "expense_id", "external_id", "fingerprint", "batch_id", "merchant_id", "refund_of",
+	"kind", "transfer_id", "currency",
+}
+
+// qualifiedExpenditureColumns returns the expenditure columns of the table alias.
+func qualifiedExpenditureColumns(alias string) []string {
+	columns := make([]string, len(expenditureColumns))
+	for i, column := range expenditureColumns {
+		columns[i] = alias + "." + column
+	}
+
+	return columns
+}
+
+// ListExpenditures returns the user's expenditures between the dates, newest first. The filter is a
+// search in the syntax of the search package. Expenditures must have all of the tags, if any are given.
 func ListExpenditures(
 	ctx context.Context,
 	pool *pgxpool.Pool,
 	filter, category, paymentMethod, source *string,
+	tags []string,
 	since, until time.Time,
 	limit, cursor *int,
 ) ([]*model.Expenditure, error) {
-	sq := squirrel.Select("*").
-		From("expenditure").
-		Where(`owner = ? AND date >= ? AND date <= ?`, ctxutil.GetUser(ctx), since.UTC(), until.UTC()).
-		OrderBy("date DESC, id DESC").
-		PlaceholderFormat(squirrel.Dollar)
-
-	if filter != nil {
-		sq = sq.Where(squirrel.Or{
-			squirrel.ILike{"name": "%" + *filter + "%"},
-			squirrel.ILike{"comment": "%" + *filter + "%"},
+	sq, err := filterExpenditures(ctx, squirrel.Select(expenditureColumns...).From("expenditure"),
+		&model.ExpenditureFilter{
+			Search:        filter,
+			Category:      category,
+			PaymentMethod: paymentMethod,
+			Source:        source,
+			Tags:          tags,
+			Since:         since,
+			Until:         until,
 		})
+	if err != nil {
+		return nil, err
 	}
 
-	if category != nil {
-		sq = sq.Where(squirrel.Eq{"budget_category": *category})
-	}
-
-	if paymentMethod != nil {
-		sq = sq.Where(squirrel.Eq{"method": *paymentMethod})
-	}
-
-	if source != nil {
-		sq = sq.Where(squirrel.Eq{"source": *source})
-	}
+	sq = sq.OrderBy("date DESC, id DESC")
 
 	if limit != nil {
 		sq = sq.Limit(uint64(*limit)) //nolint:gosec
@@ -59,7 +82,6 @@ func ListExpenditures(
 	var expenditures []*model.Expenditure
 	var query string
 	var args []interface{}
-	var err error
 
 	if query, args, err = sq.ToSql(); err == nil {
 		err = pgxscan.Select(ctx, pool, &expenditures, query, args...)
@@ -72,6 +94,187 @@ func ListExpenditures(
 	return expenditures, nil
 }
 
+// StreamExpenditures calls fn with each of the user's expenditures that match the filter, oldest first,
+// without loading them all into memory. If byAccount is true, the expenditures are grouped by payment
+// method and currency first, with those of bank accounts before those of credit cards. The connection
+// is held until fn returns for the last expenditure.
+func StreamExpenditures(
+	ctx context.Context,
+	pool *pgxpool.Pool,
+	filter *model.ExpenditureFilter,
+	byAccount bool,
+	fn func(*model.Expenditure) error,
+) error {
+	sq, err := filterExpenditures(ctx, squirrel.Select(expenditureColumns...).From("expenditure"), filter)
+	if err != nil {
+		return err
+	}
+
+	if byAccount {
+		// Bank accounts come before credit cards, the payment methods with a card type.
+		sq = sq.OrderBy(fmt.Sprintf(`EXISTS (SELECT 1 FROM payment_method p
+			WHERE p.id = expenditure.method AND p.card_type IS NOT NULL AND p.card_type != '%s')`, uuid.Nil),
+			"method", "currency")
+	}
+
+	query, args, err := sq.OrderBy("date", "id").ToSql()
+	if err != nil {
+		return fmt.Errorf("failed to build query: %w", err)
+	}
+
+	rows, err := pool.Query(ctx, query, args...)
+	if err != nil {
+		return fmt.Errorf("failed to get expenditures: %w", err)
+	}
+
+	defer rows.Close()
+
+	scanner := pgxscan.NewRowScanner(rows)
+
+	for rows.Next() {
+		var expenditure model.Expenditure
+		if err = scanner.Scan(&expenditure); err != nil {
+			return fmt.Errorf("failed to scan expenditure: %w", err)
+		}
+
+		if err = fn(&expenditure); err != nil {
+			return err
+		}
+	}
+
+	if err = rows.Err(); err != nil {
+		return fmt.Errorf("failed to get expenditures: %w", err)
+	}
+
+	return nil
+}
+
+// sortColumns are the expressions that the sort orders of expenditures sort by, and the types of
+// their values.
+var sortColumns = map[model.ExpenditureSort][2]string{ //nolint:gochecknoglobals
+	model.ExpenditureSortDate:    {"date", "DATE"},
+	model.ExpenditureSortAmount:  {"amount", "NUMERIC"},
+	model.ExpenditureSortName:    {"COALESCE(name, '')", "TEXT"},
+	model.ExpenditureSortCreated: {"created", "TIMESTAMPTZ"},
+}
+
+// ListExpenditurePage returns up to first of the user's expenditures that match the filter, in the
+// sort order, starting after the cursor if one is given.
+func ListExpenditurePage(
+	ctx context.Context,
+	pool *pgxpool.Pool,
+	filter *model.ExpenditureFilter,
+	sort model.ExpenditureSort,
+	descending bool,
+	first int,
+	after *model.ExpenditureCursor,
+) (*model.ExpenditurePage, error) {
+	column, ok := sortColumns[sort]
+	if !ok {
+		return nil, errors.InvalidInputError{Input: sort}
+	}
+
+	if first < 0 {
+		return nil, fmt.Errorf("page size must not be negative: %w", errors.InvalidInputError{Input: first})
+	}
+
+	countQuery, err := filterExpenditures(ctx, squirrel.Select("COUNT(*)").From("expenditure"), filter)
+	if err != nil {
+		return nil, err
+	}
+
+	sq, err := filterExpenditures(ctx, squirrel.Select(expenditureColumns...).From("expenditure"), filter)
+	if err != nil {
+		return nil, err
+	}
+
+	direction, comparison := "ASC", ">"
+	if descending {
+		direction, comparison = "DESC", "<"
+	}
+
+	if after != nil {
+		if after.Sort != sort {
+			return nil, fmt.Errorf("cursor is for sorting by %s: %w", after.Sort, errors.InvalidInputError{Input: sort})
+		}
+
+		sq = sq.Where(fmt.Sprintf("(%s, id) %s (?::%s, ?)", column[0], comparison, column[1]), after.Value, after.ID)
+	}
+
+	// Fetch one more expenditure than the page holds to tell whether there is a next page.
+	query, args, err := sq.OrderBy(column[0]+" "+direction, "id "+direction).
+		Limit(uint64(first) + 1). //nolint:gosec
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("failed to build query: %w", err)
+	}
+
+	page := &model.ExpenditurePage{}
+	if err = pgxscan.Select(ctx, pool, &page.Expenditures, query, args...); err != nil {
+		return nil, fmt.Errorf("failed to get expenditures: %w", err)
+	}
+
+	if len(page.Expenditures) > first {
+		page.Expenditures = page.Expenditures[:first]
+		page.HasNextPage = true
+	}
+
+	if query, args, err = countQuery.ToSql(); err != nil {
+		return nil, fmt.Errorf("failed to build query: %w", err)
+	}
+
+	if err = pgxscan.Get(ctx, pool, &page.TotalCount, query, args...); err != nil {
+		return nil, fmt.Errorf("failed to count expenditures: %w", err)
+	}
+
+	return page, nil
+}
+
+// filterExpenditures adds the conditions of the filter to a query of the user's expenditures.
+func filterExpenditures(
+	ctx context.Context,
+	sq squirrel.SelectBuilder,
+	filter *model.ExpenditureFilter,
+) (squirrel.SelectBuilder, error) {
+	user := ctxutil.GetUser(ctx)
+	sq = sq.Where(`owner = ? AND date >= ? AND date <= ? AND deleted_at IS NULL`, user, filter.Since.UTC(),
+		filter.Until.UTC())
+
+	if filter.Search != nil {
+		conditions, err := search.Parse(*filter.Search, user)
+		if err != nil {
+			return sq, err
+		}
+
+		sq = sq.Where(conditions)
+	}
+
+	if filter.Category != nil {
+		sq = sq.Where(squirrel.Eq{"budget_category": *filter.Category})
+	}
+
+	if filter.PaymentMethod != nil {
+		sq = sq.Where(squirrel.Eq{"method": *filter.PaymentMethod})
+	}
+
+	if filter.Source != nil {
+		sq = sq.Where(squirrel.Eq{"source": *filter.Source})
+	}
+
+	if len(filter.Tags) > 0 {
+		names, err := tagNames(filter.Tags)
+		if err != nil {
+			return sq, err
+		}
+
+		sq = sq.Where(`id IN (SELECT et.expenditure_id FROM expenditure_tag et JOIN tag t ON t.id = et.tag_id
+			WHERE t.owner = ? AND t.name = ANY(?::CITEXT[]) GROUP BY et.expenditure_id HAVING COUNT(*) = ?)`,
+			user, names, len(names))
+	}
+
+	return sq, nil
+}
+
 func AggregateExpenditures(
 	ctx context.Context,
 	pool *pgxpool.Pool,
@@ -79,6 +282,26 @@ func AggregateExpenditures(
 	timespan model.Timespan,
 	aggregation model.Aggregation,
 	groupBy model.GroupBy,
+	refunds model.RefundAttribution,
+	includeTransfers bool,
+) ([]*model.ExpenditureSummary, error) {
+	return aggregateExpenditures(ctx, pool, startDate, endDate, timespan, aggregation, groupBy, false,
+		refunds, includeTransfers)
+}
+
+// aggregateExpenditures is AggregateExpenditures, except that if byCategoryName is set, grouping by
+// budget category groups by the lower-cased category name rather than by the expense it counts
+// towards, so that every budget with the category sees the spending.
+func aggregateExpenditures(
+	ctx context.Context,
+	pool *pgxpool.Pool,
+	startDate, endDate time.Time,
+	timespan model.Timespan,
+	aggregation model.Aggregation,
+	groupBy model.GroupBy,
+	byCategoryName bool,
+	refunds model.RefundAttribution,
+	includeTransfers bool,
 ) ([]*model.ExpenditureSummary, error) {
 	var category string
 	var categoryDefault string
@@ -89,10 +312,69 @@ func AggregateExpenditures(
 	case model.GroupByBudgetCategory:
 		category = "expense_id"
 		categoryDefault = uuid.Nil.String()
+
+		if byCategoryName {
+			category = "budget_category"
+			categoryDefault = ""
+		}
 	case model.GroupByRewardCategory:
 		category = "reward_category"
+	case model.GroupByMerchant:
+		category = "merchant_id"
+		categoryDefault = uuid.Nil.String()
+	case model.GroupByTag:
+		category = "tag_id"
+		categoryDefault = uuid.Nil.String()
+	}
+
+	// Refunds count towards the category of the purchase they reverse, and by default its period.
+	spendDate := "COALESCE(o.date, e.date)"
+	if refunds == model.RefundAttributionRefundDate {
+		spendDate = "e.date"
+	}
+
+	expenseID := "COALESCE(o.expense_id, e.expense_id)"
+	budgetCategory := "LOWER(COALESCE(o.budget_category, e.budget_category))"
+	amount := "e.amount"
+	joins := ""
+	tagID := "NULL::UUID"
+
+	if groupBy == model.GroupByBudgetCategory {
+		// Split expenditures count once per split, under the split's category. Refunds of them are
+		// divided in the same proportions.
+		expenseID = "COALESCE(s.expense_id, o.expense_id, e.expense_id)"
+		budgetCategory = "LOWER(COALESCE(s.budget_category, o.budget_category, e.budget_category))"
+		amount = `CASE WHEN s.id IS NULL THEN e.amount
+			WHEN o.id IS NULL THEN s.amount
+			ELSE e.amount * s.amount / o.amount END`
+		joins = "LEFT JOIN expenditure_split s ON s.expenditure_id = COALESCE(o.id, e.id)"
 	}
 
+	if groupBy == model.GroupByTag {
+		// Expenditures count once under each of their tags, and refunds under the purchase's tags.
+		tagID = "t.tag_id"
+		joins = "LEFT JOIN expenditure_tag t ON t.expenditure_id = COALESCE(o.id, e.id)"
+	}
+
+	// Amounts are converted to the owner's home currency at the rate on the day they were charged.
+	// Expenditures in a currency without any known rate are left out and counted as unconverted.
+	amount = fmt.Sprintf(
+		"(%s) * exchange_rate_on(e.owner, e.currency, COALESCE(u.home_currency, '%s'), e.date)",
+		amount, model.DefaultCurrency)
+
+	// Moving money between the user's own accounts isn't spending.
+	kinds := fmt.Sprintf("WHERE e.deleted_at IS NULL AND e.kind != '%s'", model.KindTransfer)
+	if includeTransfers {
+		kinds = "WHERE e.deleted_at IS NULL"
+	}
+
+	from := fmt.Sprintf(`(SELECT e.owner, %s AS date, %s AS expense_id, %s AS budget_category,
+			COALESCE(o.reward_category, e.reward_category) AS reward_category,
+			COALESCE(o.merchant_id, e.merchant_id) AS merchant_id, %s AS tag_id, %s AS amount
+		FROM expenditure e LEFT JOIN expenditure o ON o.id = e.refund_of
+			LEFT JOIN user_profile u ON u.id = e.owner %s %s) AS expenditure`,
+		spendDate, expenseID, budgetCategory, tagID, amount, joins, kinds)
+
 	date := "date"
 	if timespan != model.TimespanDay {
 		// This group-by will cause postgres to do a sequential scan if the timespan is not "DAY".
@@ -104,8 +386,9 @@ func AggregateExpenditures(
 
 	sq := squirrel.Select(date+" as date",
 		fmt.Sprintf("COALESCE(%s::text, '%s') as category", category, categoryDefault),
-		string(aggregation)+"(amount) as amount").
-		From("expenditure").
+		"COALESCE("+string(aggregation)+"(amount), 0) as amount",
+		"COUNT(*) FILTER (WHERE amount IS NULL) as unconverted").
+		From(from).
 		Where("owner = $1 AND date >= $2 AND date <= $3", ctxutil.GetUser(ctx), startDate, endDate).
 		GroupBy(date).
 		OrderBy("date ASC")
@@ -135,54 +418,621 @@ func AggregateExpenditures(
 	return expenditures, nil
 }
 
+// nearDuplicateDays is how far apart the dates of two expenditures can be for them to be reported
+// as possible duplicates.
+const nearDuplicateDays = 3
+
 func PersistExpenditures(
 	ctx context.Context,
 	pool *pgxpool.Pool,
 	expenditures []*model.Expenditure,
 ) error {
+	_, err := ImportExpenditures(ctx, pool, expenditures)
+
+	return err
+}
+
+// ImportExpenditures saves the expenditures that haven't been saved before as a new import batch
+// and sets their IDs. Blank categories and payment methods are filled in by the user's
+// categorization rules, then by learned suggestions if auto-categorization is enabled.
+// Expenditures with the same fingerprint or external ID as a saved one are skipped. Inserted
+// expenditures with the same amount as a saved one a few days apart, on the same payment method or
+// under the same name, are reported as possible duplicates. Credits are linked to the purchases
+// they refund, and transfers to their other side. Recurring series are detected again afterwards.
+func ImportExpenditures(
+	ctx context.Context,
+	pool *pgxpool.Pool,
+	expenditures []*model.Expenditure,
+) (*model.ImportResult, error) {
+	engine, err := getRulesEngine(ctx, pool)
+	if err != nil {
+		return nil, err
+	}
+
 	// If budget exists, map the expense ID to the expenditure's expense_id
-	budgets, err := GetBudgets(ctx, pool, ctxutil.GetUser(ctx), 1)
+	budgetMap, err := getExpenseIDsByCategory(ctx, pool)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	budgetMap := make(map[string]uuid.UUID)
+	importBatch := &model.ImportBatch{
+		ID:     uuid.New(),
+		Owner:  ctxutil.GetUser(ctx),
+		Source: batchSource(expenditures),
+	}
 
-	for _, budget := range budgets {
-		for _, expense := range budget.Expenses {
-			budgetMap[strings.ToLower(expense.Category)] = expense.ID
+	// Categories learned from the user's history fill in what rules didn't
+	var categoryModel *classifier.Model
+
+	threshold := config.AutoCategorizeThreshold()
+	if threshold > 0 {
+		if categoryModel, err = GetCategoryModel(ctx, pool); err != nil {
+			return nil, err
 		}
 	}
 
 	for _, expenditure := range expenditures {
+		if expenditure.Currency, err = currency.Normalize(expenditure.Currency); err != nil {
+			return nil, err
+		}
+
+		// Rules only fill in what the statement left blank
+		engine.Apply(expenditure, false)
+
+		if categoryModel != nil {
+			categoryModel.Fill(expenditure, threshold)
+		}
+
 		if expenditure.BudgetCategory != "" && expenditure.ExpenseID == uuid.Nil {
 			expenditure.ExpenseID = budgetMap[strings.ToLower(expenditure.BudgetCategory)]
 		}
+
+		expenditure.BatchID = importBatch.ID
+	}
+
+	fingerprint(expenditures)
+
+	tx, err := pool.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("could not start transaction: %w", err)
+	}
+
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	if err = linkMerchants(ctx, tx, expenditures); err != nil {
+		return nil, err
 	}
 
 	batch := &pgx.Batch{}
 
 	for _, e := range expenditures {
+		if e.Kind == "" {
+			e.Kind = model.KindExpense
+		}
+
 		query, args, err := squirrel.Insert("expenditure").
-			Columns("owner", "name", "amount", "date",
-				"method", "budget_category", "reward_category", "comment", "source", "expense_id").
-			Values(e.Owner, e.Name, e.Amount, e.Date,
-				e.Method, e.BudgetCategory, e.RewardCategory, e.Comment, e.Source, e.ExpenseID).
+			Columns("owner", "name", "amount", "date", "method", "budget_category", "reward_category",
+				"comment", "source", "expense_id", "external_id", "fingerprint", "batch_id", "merchant_id",
+				"kind", "currency").
+			Values(e.Owner, e.Name, e.Amount, e.Date, e.Method, e.BudgetCategory, e.RewardCategory,
+				e.Comment, e.Source, e.ExpenseID, e.ExternalID, e.Fingerprint, e.BatchID, e.MerchantID,
+				e.Kind, e.Currency).
+			// Rows that were already saved are skipped.
+			Suffix("ON CONFLICT DO NOTHING RETURNING id").
 			ToSql()
 		if err != nil {
-			return fmt.Errorf("failed to build query: %w", err)
+			return nil, fmt.Errorf("failed to build query: %w", err)
 		}
 
 		batch.Queue(query, args...)
 	}
 
-	if err = pool.SendBatch(ctx, batch).Close(); err != nil {
-		return fmt.Errorf("failed to save batch of expenditures: %w", err)
+	result := &model.ImportResult{
+		Batch:              importBatch,
+		Inserted:           make([]*model.Expenditure, 0, len(expenditures)),
+		PossibleDuplicates: []*model.PossibleDuplicate{},
+	}
+
+	results := tx.SendBatch(ctx, batch)
+
+	for _, e := range expenditures {
+		rows, err := results.Query()
+		if err != nil {
+			_ = results.Close()
+
+			return nil, fmt.Errorf("failed to save expenditure: %w", err)
+		}
+
+		ids, err := pgx.CollectRows(rows, pgx.RowTo[int])
+		if err != nil {
+			_ = results.Close()
+
+			return nil, fmt.Errorf("failed to save expenditure: %w", err)
+		}
+
+		if len(ids) == 0 {
+			result.Skipped++
+
+			continue
+		}
+
+		e.ID = ids[0]
+		result.Inserted = append(result.Inserted, e)
+	}
+
+	if err = results.Close(); err != nil {
+		return nil, fmt.Errorf("failed to save batch of expenditures: %w", err)
+	}
+
+	if err = createImportBatch(ctx, tx, importBatch, result.Inserted); err != nil {
+		return nil, err
+	}
+
+	if err = linkRefunds(ctx, tx, result.Inserted); err != nil {
+		return nil, err
+	}
+
+	if err = pairTransfers(ctx, tx, result.Inserted); err != nil {
+		return nil, err
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	if result.PossibleDuplicates, err = getPossibleDuplicates(ctx, pool, result.Inserted); err != nil {
+		return nil, err
+	}
+
+	// The expenditures are already saved, so failing to detect recurring series again is only logged.
+	// They are detected again on the next import or when asked to.
+	if len(result.Inserted) > 0 {
+		if _, err = DetectRecurringSeries(ctx, pool); err != nil {
+			log.Println("failed to detect recurring expenditures after import:", err)
+		}
+	}
+
+	return result, nil
+}
+
+// batchSource returns the source shared by all the expenditures, or an empty string if they come
+// from different sources.
+func batchSource(expenditures []*model.Expenditure) string {
+	if len(expenditures) == 0 {
+		return ""
+	}
+
+	for _, e := range expenditures[1:] {
+		if e.Source != expenditures[0].Source {
+			return ""
+		}
+	}
+
+	return expenditures[0].Source
+}
+
+// fingerprint sets the fingerprint of each expenditure from its date, amount, normalized name,
+// payment method and external ID. Identical expenditures in the same batch are numbered so that
+// repeated purchases are kept. Migration 000014 computes the same value for existing rows.
+func fingerprint(expenditures []*model.Expenditure) {
+	seen := make(map[string]int, len(expenditures))
+
+	for _, e := range expenditures {
+		fields := strings.Join([]string{
+			e.Date.Format(time.DateOnly),
+			e.Amount.Round().String(),
+			strings.ToLower(strings.Join(strings.Fields(e.Name), " ")),
+			e.Method.String(),
+			e.ExternalID,
+		}, "|")
+
+		ordinal := seen[fields]
+		seen[fields]++
+
+		if ordinal > 0 {
+			fields += "#" + strconv.Itoa(ordinal)
+		}
+
+		sum := sha256.Sum256([]byte(fields))
+		e.Fingerprint = hex.EncodeToString(sum[:])
+	}
+}
+
+// getPossibleDuplicates finds the expenditures saved before the inserted ones that look like the
+// same transaction.
+func getPossibleDuplicates(
+	ctx context.Context,
+	pool *pgxpool.Pool,
+	inserted []*model.Expenditure,
+) ([]*model.PossibleDuplicate, error) {
+	duplicates := []*model.PossibleDuplicate{}
+	if len(inserted) == 0 {
+		return duplicates, nil
+	}
+
+	ids := make([]int, len(inserted))
+	for i, e := range inserted {
+		ids[i] = e.ID
+	}
+
+	query, args, err := squirrel.Select(append([]string{"n.id AS new_id"}, qualifiedExpenditureColumns("e")...)...).
+		From("expenditure n").
+		Join(fmt.Sprintf(`expenditure e ON e.owner = n.owner
+			AND e.amount = n.amount
+			AND e.date BETWEEN n.date - %[1]d AND n.date + %[1]d
+			AND (e.method = n.method OR lower(e.name) = lower(n.name))
+			AND e.deleted_at IS NULL`, nearDuplicateDays)).
+		Where("n.owner = ? AND n.id = ANY(?) AND e.id != ALL(?)", ctxutil.GetUser(ctx), ids, ids).
+		OrderBy("n.id", "e.date", "e.id").
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("failed to build query: %w", err)
+	}
+
+	var matches []*struct {
+		NewID int `db:"new_id"`
+		model.Expenditure
+	}
+	if err = pgxscan.Select(ctx, pool, &matches, query, args...); err != nil {
+		return nil, fmt.Errorf("failed to get possible duplicates: %w", err)
+	}
+
+	byID := make(map[int]*model.PossibleDuplicate)
+
+	for _, match := range matches {
+		duplicate, ok := byID[match.NewID]
+		if !ok {
+			duplicate = &model.PossibleDuplicate{}
+			byID[match.NewID] = duplicate
+		}
+
+		duplicate.Matches = append(duplicate.Matches, &match.Expenditure)
+	}
+
+	for _, e := range inserted {
+		if duplicate, ok := byID[e.ID]; ok {
+			duplicate.Expenditure = e
+			duplicates = append(duplicates, duplicate)
+		}
+	}
+
+	return duplicates, nil
+}
+
+func GetExpenditure(ctx context.Context, pool *pgxpool.Pool, id int) (*model.Expenditure, error) {
+	query, args, err := squirrel.Select(expenditureColumns...).
+		From("expenditure").
+		Where(squirrel.Eq{
+			"id":         id,
+			"owner":      ctxutil.GetUser(ctx),
+			"deleted_at": nil,
+		}).
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("failed to build query: %w", err)
+	}
+
+	var expenditures []*model.Expenditure
+	if err = pgxscan.Select(ctx, pool, &expenditures, query, args...); err != nil {
+		return nil, fmt.Errorf("failed to get expenditure: %w", err)
+	}
+
+	if len(expenditures) == 0 {
+		return nil, errors.NoSuchElementError{Element: id}
+	}
+
+	return expenditures[0], nil
+}
+
+// UpdateExpenditure overwrites the stored expenditure with the same ID. The expense_id and merchant
+// are remapped from the budget category and name the same way PersistExpenditures does on insert.
+// Splits that no longer add up to the amount are removed, and so is the pairing of a transfer that
+// is no longer a transfer.
+func UpdateExpenditure(ctx context.Context, pool *pgxpool.Pool, expenditure *model.Expenditure) error {
+	code, err := currency.Normalize(expenditure.Currency)
+	if err != nil {
+		return err
+	}
+
+	expenditure.Currency = code
+
+	budgetMap, err := getExpenseIDsByCategory(ctx, pool)
+	if err != nil {
+		return err
+	}
+
+	expenditure.ExpenseID = budgetMap[strings.ToLower(expenditure.BudgetCategory)]
+
+	tx, err := pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("could not start transaction: %w", err)
+	}
+
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	if err = linkMerchants(ctx, tx, []*model.Expenditure{expenditure}); err != nil {
+		return err
+	}
+
+	query, args, err := squirrel.Update("expenditure").
+		Set("name", expenditure.Name).
+		Set("amount", expenditure.Amount).
+		Set("date", expenditure.Date).
+		Set("method", expenditure.Method).
+		Set("budget_category", expenditure.BudgetCategory).
+		Set("reward_category", expenditure.RewardCategory).
+		Set("comment", expenditure.Comment).
+		Set("source", expenditure.Source).
+		Set("expense_id", expenditure.ExpenseID).
+		Set("merchant_id", expenditure.MerchantID).
+		Set("kind", expenditure.Kind).
+		Set("currency", expenditure.Currency).
+		Where(squirrel.Eq{
+			"id":         expenditure.ID,
+			"owner":      ctxutil.GetUser(ctx),
+			"deleted_at": nil,
+		}).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("failed to build query: %w", err)
+	}
+
+	tag, err := tx.Exec(ctx, query, args...)
+	if err != nil {
+		return fmt.Errorf("failed to update expenditure: %w", err)
+	}
+
+	if tag.RowsAffected() == 0 {
+		return errors.NoSuchElementError{Element: expenditure.ID}
+	}
+
+	query, args, err = squirrel.Delete("expenditure_split").
+		Where(squirrel.Eq{
+			"expenditure_id": expenditure.ID,
+			"owner":          ctxutil.GetUser(ctx),
+		}).
+		Where(`(SELECT ROUND(SUM(amount), 2) FROM expenditure_split WHERE expenditure_id = ?)
+			!= ROUND(?::NUMERIC, 2)`, expenditure.ID, expenditure.Amount).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("failed to build query: %w", err)
+	}
+
+	if _, err = tx.Exec(ctx, query, args...); err != nil {
+		return fmt.Errorf("failed to delete expenditure splits: %w", err)
+	}
+
+	if expenditure.Kind != model.KindTransfer && expenditure.TransferID.Valid {
+		if _, err = tx.Exec(ctx, unpairTransfer, ctxutil.GetUser(ctx), expenditure.ID); err != nil {
+			return fmt.Errorf("failed to unpair transfer: %w", err)
+		}
+
+		expenditure.TransferID.Valid = false
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
 	}
 
 	return nil
 }
 
+// BulkUpdateExpenditures applies the patch to all of the user's expenditures that match the filter in
+// a single transaction. It returns the number of expenditures that changed and the changes. The
+// expense_id follows the new budget category the same way UpdateExpenditure does. Nothing is saved if
+// dryRun is true.
+func BulkUpdateExpenditures(
+	ctx context.Context,
+	pool *pgxpool.Pool,
+	filter *model.ExpenditureFilter,
+	patch *model.ExpenditurePatch,
+	dryRun bool,
+) (int, []*model.FieldChange, error) {
+	var names []string
+
+	if patch.Tags != nil {
+		var err error
+		if names, err = tagNames(patch.Tags); err != nil {
+			return 0, nil, err
+		}
+
+		slices.SortFunc(names, func(a, b string) int {
+			return strings.Compare(strings.ToLower(a), strings.ToLower(b))
+		})
+	}
+
+	budgetMap, err := getExpenseIDsByCategory(ctx, pool)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	tx, err := pool.Begin(ctx)
+	if err != nil {
+		return 0, nil, fmt.Errorf("could not start transaction: %w", err)
+	}
+
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	sq, err := filterExpenditures(ctx, squirrel.Select(expenditureColumns...).From("expenditure"), filter)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	query, args, err := sq.OrderBy("id").Suffix("FOR UPDATE").ToSql()
+	if err != nil {
+		return 0, nil, fmt.Errorf("failed to build query: %w", err)
+	}
+
+	var expenditures []*model.Expenditure
+	if err = pgxscan.Select(ctx, tx, &expenditures, query, args...); err != nil {
+		return 0, nil, fmt.Errorf("failed to get expenditures: %w", err)
+	}
+
+	var tags map[int][]string
+	if names != nil {
+		if tags, err = listTagNames(ctx, tx, expenditures); err != nil {
+			return 0, nil, err
+		}
+	}
+
+	changes := []*model.FieldChange{}
+	changed := []int{}
+
+	for _, e := range expenditures {
+		before := len(changes)
+		change := func(field, current, value string) {
+			if current != value {
+				changes = append(changes, &model.FieldChange{ExpenditureID: e.ID, Field: field, Before: current, After: value})
+			}
+		}
+
+		if patch.BudgetCategory != nil {
+			change(rules.FieldBudgetCategory, e.BudgetCategory, *patch.BudgetCategory)
+		}
+
+		if patch.RewardCategory != nil {
+			change(rules.FieldRewardCategory, e.RewardCategory, *patch.RewardCategory)
+		}
+
+		if patch.Method != nil {
+			change(rules.FieldMethod, e.Method.String(), patch.Method.String())
+		}
+
+		if patch.Comment != nil {
+			change("comment", e.Comment, *patch.Comment)
+		}
+
+		// Tag names are compared ignoring case, like the tags themselves.
+		if current := strings.Join(tags[e.ID], ", "); names != nil && !strings.EqualFold(current, strings.Join(names, ", ")) {
+			change("tags", current, strings.Join(names, ", "))
+		}
+
+		if len(changes) > before {
+			changed = append(changed, e.ID)
+		}
+	}
+
+	if dryRun || len(changed) == 0 {
+		return len(changed), changes, nil
+	}
+
+	if err = patchExpenditures(ctx, tx, changed, patch, budgetMap); err != nil {
+		return 0, nil, err
+	}
+
+	if names != nil {
+		if _, err = tx.Exec(ctx, `DELETE FROM expenditure_tag WHERE expenditure_id = ANY($1)`, changed); err != nil {
+			return 0, nil, fmt.Errorf("failed to untag expenditures: %w", err)
+		}
+
+		if _, err = linkTags(ctx, tx, changed, names); err != nil {
+			return 0, nil, err
+		}
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return 0, nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return len(changed), changes, nil
+}
+
+// patchExpenditures sets the columns of the patch on the user's expenditures. Tags are not columns
+// and are left to the caller.
+func patchExpenditures(
+	ctx context.Context,
+	tx pgx.Tx,
+	ids []int,
+	patch *model.ExpenditurePatch,
+	budgetMap map[string]uuid.UUID,
+) error {
+	values := map[string]any{}
+
+	if patch.BudgetCategory != nil {
+		values["budget_category"] = *patch.BudgetCategory
+		values["expense_id"] = budgetMap[strings.ToLower(*patch.BudgetCategory)]
+	}
+
+	if patch.RewardCategory != nil {
+		values["reward_category"] = *patch.RewardCategory
+	}
+
+	if patch.Method != nil {
+		values["method"] = *patch.Method
+	}
+
+	if patch.Comment != nil {
+		values["comment"] = *patch.Comment
+	}
+
+	if len(values) == 0 {
+		return nil
+	}
+
+	query, args, err := squirrel.Update("expenditure").
+		SetMap(values).
+		Where(squirrel.Eq{
+			"id":    ids,
+			"owner": ctxutil.GetUser(ctx),
+		}).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("failed to build query: %w", err)
+	}
+
+	if _, err = tx.Exec(ctx, query, args...); err != nil {
+		return fmt.Errorf("failed to update expenditures: %w", err)
+	}
+
+	return nil
+}
+
+// DeleteExpenditures moves the user's expenditures with the given IDs to the trash and returns the
+// number of rows moved. IDs belonging to other users are ignored.
+func DeleteExpenditures(ctx context.Context, pool *pgxpool.Pool, ids []int) (int64, error) {
+	if len(ids) == 0 {
+		return 0, nil
+	}
+
+	query, args, err := squirrel.Update("expenditure").
+		Set("deleted_at", squirrel.Expr("NOW()")).
+		Where(squirrel.Eq{
+			"id":         ids,
+			"owner":      ctxutil.GetUser(ctx),
+			"deleted_at": nil,
+		}).
+		ToSql()
+	if err != nil {
+		return 0, fmt.Errorf("failed to build query: %w", err)
+	}
+
+	tag, err := pool.Exec(ctx, query, args...)
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete expenditures: %w", err)
+	}
+
+	return tag.RowsAffected(), nil
+}
+
+// getExpenseIDsByCategory maps the lowercase category of each of the user's budget expenses to
+// the expense ID.
+func getExpenseIDsByCategory(ctx context.Context, pool *pgxpool.Pool) (map[string]uuid.UUID, error) {
+	budgets, err := GetBudgets(ctx, pool, ctxutil.GetUser(ctx), 1)
+	if err != nil {
+		return nil, err
+	}
+
+	budgetMap := make(map[string]uuid.UUID)
+
+	for _, budget := range budgets {
+		for _, expense := range budget.Expenses {
+			budgetMap[strings.ToLower(expense.Category)] = expense.ID
+		}
+	}
+
+	return budgetMap, nil
+}
+
 func ClassifyExpendituresWithNewCategory(
 	ctx context.Context,
 	batch *pgx.Batch,
@@ -204,5 +1054,19 @@ func ClassifyExpendituresWithNewCategory(
 
 	batch.Queue(query, args...)
 
+	query, args, err = squirrel.Update("expenditure_split").
+		Where(map[string]interface{}{
+			"owner":           ctxutil.GetUser(ctx),
+			"budget_category": category,
+			"expense_id":      uuid.Nil,
+		}).
+		Set("expense_id", expenseID).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("failed to build query: %w", err)
+	}
+
+	batch.Queue(query, args...)
+
 	return nil
 }
@@ -1,11 +1,14 @@
 package database_test
 
 import (
+	"cmp"
 	"slices"
 	"sort"
 	"strings"
 	"testing"
 	"time"
+	"unicode"
+	"yaba/errors"
 	"yaba/internal/ctxutil"
 	"yaba/internal/database"
 	"yaba/internal/model"
@@ -55,6 +58,7 @@ func TestListAllExpenditures(t *testing.T) {
 		nil,
 		nil,
 		nil,
+		nil,
 		startDate,
 		endDate,
 		nil,
@@ -66,7 +70,7 @@ func TestListAllExpenditures(t *testing.T) {
 	for i, exp := range fetched {
 		require.Equal(t, exp.Owner, owner)
 		require.Equal(t, exp.Name, expenditures[i].Name)
-		require.InDelta(t, exp.Amount, expenditures[i].Amount, .001)
+		require.InDelta(t, exp.Amount.Float64(), expenditures[i].Amount.Float64(), .001)
 		require.Equal(t, expenditures[i].Date.Format(time.DateOnly), exp.Date.Format(time.DateOnly))
 		require.Equal(t, exp.BudgetCategory, expenditures[i].BudgetCategory)
 		require.Equal(t, exp.Method, expenditures[i].Method)
@@ -111,6 +115,7 @@ func TestListExpenditures(t *testing.T) {
 		nil,
 		nil,
 		nil,
+		nil,
 		startDate,
 		endDate,
 		nil,
@@ -184,7 +189,7 @@ func TestListExpenditures(t *testing.T) {
 			expected: func() []*model.Expenditure {
 				var expected []*model.Expenditure
 				for _, e := range expenditures {
-					if strings.Contains(strings.ToLower(e.Comment), "denim") {
+					if hasWord(e.Comment, "denim") {
 						expected = append(expected, e)
 					}
 				}
@@ -201,11 +206,27 @@ func TestListExpenditures(t *testing.T) {
 			expected: func() []*model.Expenditure {
 				var expected []*model.Expenditure
 				for _, e := range expenditures {
-					if strings.Contains(strings.ToLower(e.Name), "ale") ||
-						strings.Contains(strings.ToLower(e.Comment), "ale") {
+					if hasWord(e.Name, "ale") || hasWord(e.Comment, "ale") {
+						expected = append(expected, e)
+					}
+				}
+
+				return expected
+			},
+		},
+		{
+			name:       "fetch_with_search_operators",
+			queryStart: startDate,
+			queryEnd:   endDate,
+			filter:     pointer("-ale amount:>=50"),
+			expected: func() []*model.Expenditure {
+				var expected []*model.Expenditure
+				for _, e := range expenditures {
+					if !hasWord(e.Name, "ale") && !hasWord(e.Comment, "ale") && e.Amount >= model.NewMoney(50, 0) {
 						expected = append(expected, e)
 					}
 				}
+				require.NotEmpty(t, expected, "expenditures should not be empty")
 
 				return expected
 			},
@@ -285,6 +306,7 @@ func TestListExpenditures(t *testing.T) {
 				tc.category,
 				nil,
 				tc.source,
+				nil,
 				tc.queryStart,
 				tc.queryEnd,
 				tc.limit,
@@ -300,7 +322,7 @@ func TestListExpenditures(t *testing.T) {
 				exp := expected[i]
 				require.Equal(t, exp.Owner, actual.Owner)
 				require.Equal(t, exp.Name, actual.Name)
-				require.InDelta(t, exp.Amount, actual.Amount, .001)
+				require.InDelta(t, exp.Amount.Float64(), actual.Amount.Float64(), .001)
 				require.Equal(t, exp.Date.Format(time.DateOnly), actual.Date.Format(time.DateOnly))
 				require.Equal(t, exp.BudgetCategory, actual.BudgetCategory)
 				require.Equal(t, exp.Method, actual.Method)
@@ -310,6 +332,153 @@ func TestListExpenditures(t *testing.T) {
 	}
 }
 
+func TestListExpenditurePage(t *testing.T) {
+	t.Parallel()
+
+	pool := helper.GetTestPool()
+	owner := uuid.New()
+	ctx := ctxutil.WithUser(t.Context(), owner)
+	endDate := time.Now().UTC().Truncate(24 * time.Hour)
+	startDate := endDate.AddDate(0, 0, -30)
+	generator := helper.NewTestDataGenerator(owner, 9321)
+	generator.GenerateExpenditures(50, owner, startDate, endDate)
+	require.NoError(t, generator.PersistAll(ctx, pool))
+
+	filter := &model.ExpenditureFilter{Since: startDate, Until: endDate}
+
+	for _, sort := range []model.ExpenditureSort{
+		model.ExpenditureSortDate,
+		model.ExpenditureSortAmount,
+		model.ExpenditureSortName,
+		model.ExpenditureSortCreated,
+	} {
+		for _, descending := range []bool{false, true} {
+			var fetched []*model.Expenditure
+			var after *model.ExpenditureCursor
+
+			for {
+				page, err := database.ListExpenditurePage(ctx, pool, filter, sort, descending, 7, after)
+				require.NoError(t, err)
+				require.Equal(t, 50, page.TotalCount)
+				require.LessOrEqual(t, len(page.Expenditures), 7)
+
+				fetched = append(fetched, page.Expenditures...)
+				if !page.HasNextPage {
+					break
+				}
+
+				after = model.NewExpenditureCursor(sort, page.Expenditures[len(page.Expenditures)-1])
+			}
+
+			require.Len(t, fetched, 50, sort)
+
+			ids := make(map[int]bool)
+			for _, e := range fetched {
+				ids[e.ID] = true
+			}
+
+			require.Len(t, ids, 50, sort)
+
+			// Names are ordered by the database's collation, so only the others are checked here.
+			if sort == model.ExpenditureSortDate || sort == model.ExpenditureSortAmount {
+				require.True(t, slices.IsSortedFunc(fetched, func(a, b *model.Expenditure) int {
+					order := cmp.Compare(a.Amount, b.Amount)
+					if sort == model.ExpenditureSortDate {
+						order = a.Date.Compare(b.Date)
+					}
+
+					if order == 0 {
+						order = cmp.Compare(a.ID, b.ID)
+					}
+
+					if descending {
+						return -order
+					}
+
+					return order
+				}), sort)
+			}
+		}
+	}
+
+	// Pages continue from the cursor even after newer expenditures are added.
+	first, err := database.ListExpenditurePage(ctx, pool, filter, model.ExpenditureSortDate, true, 10, nil)
+	require.NoError(t, err)
+
+	newer := helper.NewTestDataGenerator(owner, 9322)
+	newer.GenerateExpenditures(5, owner, endDate, endDate)
+	require.NoError(t, newer.PersistAll(ctx, pool))
+
+	cursor := model.NewExpenditureCursor(model.ExpenditureSortDate, first.Expenditures[9])
+	second, err := database.ListExpenditurePage(ctx, pool, filter, model.ExpenditureSortDate, true, 10, cursor)
+	require.NoError(t, err)
+	require.Equal(t, 55, second.TotalCount)
+
+	all, err := database.ListExpenditures(ctx, pool, nil, nil, nil, nil, nil, startDate, endDate, nil, nil)
+	require.NoError(t, err)
+
+	index := slices.IndexFunc(all, func(e *model.Expenditure) bool { return e.ID == first.Expenditures[9].ID })
+	require.Equal(t, all[index+1].ID, second.Expenditures[0].ID)
+
+	_, err = database.ListExpenditurePage(ctx, pool, filter, model.ExpenditureSortAmount, true, 10, cursor)
+	require.ErrorAs(t, err, &errors.InvalidInputError{})
+}
+
+// hasWord reports whether the text has a word starting with the prefix, ignoring case, as full-text
+// search splits words.
+func hasWord(text, prefix string) bool {
+	return slices.ContainsFunc(strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	}), func(word string) bool { return strings.HasPrefix(word, prefix) })
+}
+
+func TestSearchExpendituresByWordPrefix(t *testing.T) {
+	t.Parallel()
+
+	pool := helper.GetTestPool()
+	owner := uuid.New()
+	ctx := ctxutil.WithUser(t.Context(), owner)
+
+	date := time.Date(2024, 7, 1, 0, 0, 0, 0, time.UTC)
+	require.NoError(t, database.PersistExpenditures(ctx, pool, []*model.Expenditure{
+		{Owner: owner, Name: "COFFEEHOUSE #12", Amount: model.MoneyFromFloat(5), Date: date},
+		{Owner: owner, Name: "Joe's Coffee Shop", Amount: model.MoneyFromFloat(4), Date: date},
+		{Owner: owner, Name: "Tea House", Amount: model.MoneyFromFloat(3), Date: date, Comment: "decoffeinated"},
+	}))
+
+	testCases := []struct {
+		search   string
+		expected []string
+	}{
+		// The last word matches the start of words, as the substring search used to.
+		{search: "coffee", expected: []string{"COFFEEHOUSE #12", "Joe's Coffee Shop"}},
+		{search: "coff*", expected: []string{"COFFEEHOUSE #12", "Joe's Coffee Shop"}},
+		{search: "joe's sho", expected: []string{"Joe's Coffee Shop"}},
+		// Phrases still match whole words, and words don't match in the middle of others.
+		{search: `"coffee"`, expected: []string{"Joe's Coffee Shop"}},
+		{search: "feinated", expected: nil},
+		{search: "-coffee", expected: []string{"Tea House"}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.search, func(t *testing.T) {
+			t.Parallel()
+
+			filter := &model.ExpenditureFilter{Search: pointer(tc.search), Since: date, Until: date}
+
+			page, err := database.ListExpenditurePage(ctx, pool, filter, model.ExpenditureSortAmount, true, 10, nil)
+			require.NoError(t, err)
+
+			var names []string
+			for _, e := range page.Expenditures {
+				names = append(names, e.Name)
+			}
+
+			require.Equal(t, tc.expected, names)
+		})
+	}
+}
+
 func pointer[T any](v T) *T {
 	return &v
 }
@@ -380,7 +549,7 @@ func TestAggregateExpenditures(t *testing.T) {
 			expectedAmounts: func(expenditures []*model.Expenditure) []float64 {
 				out := make([]float64, 10)
 				for _, e := range expenditures {
-					out[e.Date.Day()-1] += e.Amount
+					out[e.Date.Day()-1] += e.Amount.Float64()
 				}
 
 				return out
@@ -400,7 +569,7 @@ func TestAggregateExpenditures(t *testing.T) {
 				out := make([]float64, 2)
 
 				for _, e := range expenditures {
-					out[e.Date.Month()-1] += e.Amount
+					out[e.Date.Month()-1] += e.Amount.Float64()
 				}
 
 				return out
@@ -420,7 +589,7 @@ func TestAggregateExpenditures(t *testing.T) {
 				sum := 0.
 
 				for _, e := range expenditures {
-					sum += e.Amount
+					sum += e.Amount.Float64()
 				}
 
 				return []float64{sum / float64(len(expenditures))}
@@ -474,7 +643,7 @@ func TestAggregateExpenditures(t *testing.T) {
 			categories := make(map[string]bool)
 			for i, e := range generator.Expenditures {
 				if e.BudgetCategory != "" && !categories[e.BudgetCategory] {
-					budget.SetBasicExpense(e.BudgetCategory, float64(i*100))
+					budget.SetBasicExpense(e.BudgetCategory, model.NewMoney(int64(i*100), 0))
 
 					categories[e.BudgetCategory] = true
 				}
@@ -496,6 +665,8 @@ func TestAggregateExpenditures(t *testing.T) {
 				tc.span,
 				tc.aggregate,
 				tc.groupBy,
+				model.RefundAttributionOriginal,
+				false,
 			)
 			require.NoError(t, err)
 
@@ -507,6 +678,7 @@ func TestAggregateExpenditures(t *testing.T) {
 				nil,
 				nil,
 				nil,
+				nil,
 				startDate,
 				endDate,
 				pointer(300),
@@ -518,7 +690,7 @@ func TestAggregateExpenditures(t *testing.T) {
 			require.Len(t, aggregate, len(expected))
 
 			for i := range aggregate {
-				require.InDelta(t, expected[i], aggregate[i].Amount, .001)
+				require.InDelta(t, expected[i], aggregate[i].Amount.Float64(), .001)
 			}
 		})
 	}
@@ -541,7 +713,7 @@ func janFebGroupBy(groupBy model.GroupBy) func(expenditures []*model.Expenditure
 				category = e.RewardCategory
 			}
 
-			buckets[e.Date.Month()-1][category] += e.Amount
+			buckets[e.Date.Month()-1][category] += e.Amount.Float64()
 
 			if _, ok := exists[category]; !ok {
 				categories = append(categories, category)
@@ -572,12 +744,276 @@ func twoWeeksInAugust() func(expenditures []*model.Expenditure) []float64 {
 
 		for _, e := range expenditures {
 			if e.Date.Before(monday) {
-				out[0] += e.Amount
+				out[0] += e.Amount.Float64()
 			} else {
-				out[1] += e.Amount
+				out[1] += e.Amount.Float64()
 			}
 		}
 
 		return out
 	}
 }
+
+func TestAggregateExpendituresExact(t *testing.T) {
+	t.Parallel()
+
+	pool := helper.GetTestPool()
+	owner := uuid.New()
+	ctx := ctxutil.WithUser(t.Context(), owner)
+	date := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+
+	// Ten cents can't be represented exactly as a float, but thousands of them still add up exactly
+	expenditures := make([]*model.Expenditure, 3000)
+	for i := range expenditures {
+		expenditures[i] = &model.Expenditure{Owner: owner, Name: "gum", Amount: model.NewMoney(0, 10), Date: date}
+	}
+
+	expenditures[0].Amount = model.NewMoney(0, 13)
+	require.NoError(t, database.PersistExpenditures(ctx, pool, expenditures))
+
+	summaries, err := database.AggregateExpenditures(ctx, pool, date, date, model.TimespanDay,
+		model.AggregationSum, model.GroupByNone, model.RefundAttributionOriginal, false)
+	require.NoError(t, err)
+	require.Len(t, summaries, 1)
+	require.Equal(t, model.NewMoney(300, 3), summaries[0].Amount)
+	require.Equal(t, "300.03", summaries[0].Amount.String())
+}
+
+func TestUpdateExpenditure(t *testing.T) {
+	t.Parallel()
+
+	pool := helper.GetTestPool()
+	owner := uuid.New()
+	ctx := ctxutil.WithUser(t.Context(), owner)
+
+	budget := model.NewBudget(owner, "update budget")
+	budget.SetBasicExpense("Groceries", model.MoneyFromFloat(100))
+	budget.SetBasicExpense("Travel", model.MoneyFromFloat(100))
+	require.NoError(t, database.PersistBudget(ctx, pool, budget))
+
+	date := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+	expenditure := &model.Expenditure{
+		Owner:          owner,
+		Name:           "costco",
+		Amount:         model.MoneyFromFloat(12.34),
+		Date:           date,
+		BudgetCategory: "Groceries",
+	}
+	require.NoError(t, database.PersistExpenditures(ctx, pool, []*model.Expenditure{expenditure}))
+
+	stored, err := database.ListExpenditures(ctx, pool, nil, nil, nil, nil, nil, date, date, nil, nil)
+	require.NoError(t, err)
+	require.Len(t, stored, 1)
+	require.Equal(t, budget.Expenses[0].ID, stored[0].ExpenseID)
+
+	updated := stored[0]
+	updated.Amount = model.MoneyFromFloat(43.21)
+	updated.BudgetCategory = "travel"
+	require.NoError(t, database.UpdateExpenditure(ctx, pool, updated))
+
+	fetched, err := database.GetExpenditure(ctx, pool, updated.ID)
+	require.NoError(t, err)
+	require.InDelta(t, 43.21, fetched.Amount.Float64(), .001)
+	require.Equal(t, "travel", fetched.BudgetCategory)
+	require.Equal(t, budget.Expenses[1].ID, fetched.ExpenseID)
+
+	// Uncategorized expenditures lose their expense
+	fetched.BudgetCategory = ""
+	require.NoError(t, database.UpdateExpenditure(ctx, pool, fetched))
+
+	fetched, err = database.GetExpenditure(ctx, pool, updated.ID)
+	require.NoError(t, err)
+	require.Equal(t, uuid.Nil, fetched.ExpenseID)
+
+	// Other users cannot see or update the expenditure
+	otherCtx := ctxutil.WithUser(t.Context(), uuid.New())
+	_, err = database.GetExpenditure(otherCtx, pool, updated.ID)
+	require.ErrorContains(t, err, "no such element")
+	require.ErrorContains(t, database.UpdateExpenditure(otherCtx, pool, fetched), "no such element")
+}
+
+func TestBulkUpdateExpenditures(t *testing.T) {
+	t.Parallel()
+
+	pool := helper.GetTestPool()
+	owner := uuid.New()
+	ctx := ctxutil.WithUser(t.Context(), owner)
+
+	budget := model.NewBudget(owner, "bulk budget")
+	budget.SetBasicExpense("Travel", model.MoneyFromFloat(100))
+	require.NoError(t, database.PersistBudget(ctx, pool, budget))
+
+	date := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+	require.NoError(t, database.PersistExpenditures(ctx, pool, []*model.Expenditure{
+		{Owner: owner, Name: "uber airport", Amount: model.MoneyFromFloat(40), Date: date},
+		{Owner: owner, Name: "uber home", Amount: model.MoneyFromFloat(15), Date: date, BudgetCategory: "Travel"},
+		{Owner: owner, Name: "groceries", Amount: model.MoneyFromFloat(80), Date: date},
+	}))
+
+	stored, err := database.ListExpenditures(ctx, pool, nil, nil, nil, nil, nil, date, date, nil, nil)
+	require.NoError(t, err)
+
+	groceries := slices.IndexFunc(stored, func(e *model.Expenditure) bool { return e.Name == "groceries" })
+	_, err = database.TagExpenditures(ctx, pool, []int{stored[groceries].ID}, []string{"personal"})
+	require.NoError(t, err)
+
+	filter := &model.ExpenditureFilter{Search: pointer("uber"), Since: date, Until: date}
+	patch := &model.ExpenditurePatch{
+		BudgetCategory: pointer("travel"),
+		Comment:        pointer("trip"),
+		Tags:           []string{"Work", "reimbursable"},
+	}
+
+	affected, changes, err := database.BulkUpdateExpenditures(ctx, pool, filter, patch, true)
+	require.NoError(t, err)
+	require.Equal(t, 2, affected)
+	require.Len(t, changes, 6)
+
+	// Dry runs don't save anything.
+	unchanged, err := database.ListExpenditures(ctx, pool, nil, nil, nil, nil, nil, date, date, nil, nil)
+	require.NoError(t, err)
+	require.Equal(t, stored, unchanged)
+
+	affected, _, err = database.BulkUpdateExpenditures(ctx, pool, filter, patch, false)
+	require.NoError(t, err)
+	require.Equal(t, 2, affected)
+
+	for _, e := range stored {
+		updated, err := database.GetExpenditure(ctx, pool, e.ID)
+		require.NoError(t, err)
+
+		tags, err := database.ListExpenditureTags(ctx, pool, e.ID)
+		require.NoError(t, err)
+
+		if e.Name == "groceries" {
+			require.Equal(t, e, updated)
+			require.Len(t, tags, 1)
+
+			continue
+		}
+
+		require.Equal(t, "travel", updated.BudgetCategory)
+		require.Equal(t, budget.Expenses[0].ID, updated.ExpenseID)
+		require.Equal(t, "trip", updated.Comment)
+		require.Len(t, tags, 2)
+		require.Equal(t, "reimbursable", tags[0].Name)
+		require.Equal(t, "Work", tags[1].Name)
+	}
+
+	// Applying the same patch again changes nothing.
+	affected, changes, err = database.BulkUpdateExpenditures(ctx, pool, filter, patch, false)
+	require.NoError(t, err)
+	require.Zero(t, affected)
+	require.Empty(t, changes)
+
+	// Other users' expenditures are not matched.
+	otherCtx := ctxutil.WithUser(t.Context(), uuid.New())
+	affected, _, err = database.BulkUpdateExpenditures(otherCtx, pool, filter, &model.ExpenditurePatch{
+		Comment: pointer("mine"),
+	}, false)
+	require.NoError(t, err)
+	require.Zero(t, affected)
+}
+
+func TestDeleteExpenditures(t *testing.T) {
+	t.Parallel()
+
+	pool := helper.GetTestPool()
+	owner := uuid.New()
+	ctx := ctxutil.WithUser(t.Context(), owner)
+	endDate := time.Now().UTC().Truncate(24 * time.Hour)
+	startDate := endDate.AddDate(0, 0, -30)
+
+	generator := helper.NewTestDataGenerator(owner, 9001)
+	generator.GenerateExpenditures(5, owner, startDate, endDate)
+	require.NoError(t, generator.PersistAll(ctx, pool))
+
+	stored, err := database.ListExpenditures(ctx, pool, nil, nil, nil, nil, nil, startDate, endDate, nil, nil)
+	require.NoError(t, err)
+	require.Len(t, stored, 5)
+
+	// Another user can't delete the expenditures
+	deleted, err := database.DeleteExpenditures(
+		ctxutil.WithUser(t.Context(), uuid.New()), pool, []int{stored[0].ID})
+	require.NoError(t, err)
+	require.Zero(t, deleted)
+
+	deleted, err = database.DeleteExpenditures(ctx, pool, []int{stored[0].ID, stored[1].ID})
+	require.NoError(t, err)
+	require.Equal(t, int64(2), deleted)
+
+	remaining, err := database.ListExpenditures(ctx, pool, nil, nil, nil, nil, nil, startDate, endDate, nil, nil)
+	require.NoError(t, err)
+	require.Len(t, remaining, 3)
+	require.Equal(t, stored[2:], remaining)
+
+	deleted, err = database.DeleteExpenditures(ctx, pool, nil)
+	require.NoError(t, err)
+	require.Zero(t, deleted)
+}
+
+func TestImportExpenditures(t *testing.T) {
+	t.Parallel()
+
+	pool := helper.GetTestPool()
+	owner := uuid.New()
+	ctx := ctxutil.WithUser(t.Context(), owner)
+	method := uuid.New()
+	date := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+
+	newExpenditures := func() []*model.Expenditure {
+		return []*model.Expenditure{
+			// The same coffee bought twice is kept twice
+			{Owner: owner, Name: "Coffee", Amount: model.MoneyFromFloat(4.5), Date: date, Method: method},
+			{Owner: owner, Name: "Coffee", Amount: model.MoneyFromFloat(4.5), Date: date, Method: method},
+			{Owner: owner, Name: "Bookstore", Amount: model.MoneyFromFloat(20), Date: date, Method: method, ExternalID: "1"},
+		}
+	}
+
+	result, err := database.ImportExpenditures(ctx, pool, newExpenditures())
+	require.NoError(t, err)
+	require.Len(t, result.Inserted, 3)
+	require.Zero(t, result.Skipped)
+	require.Empty(t, result.PossibleDuplicates)
+
+	for _, e := range result.Inserted {
+		require.NotZero(t, e.ID)
+		require.Len(t, e.Fingerprint, 64)
+	}
+
+	require.NotEqual(t, result.Inserted[0].Fingerprint, result.Inserted[1].Fingerprint)
+
+	// Re-importing skips everything, regardless of spacing and case in names
+	reimported := newExpenditures()
+	reimported[0].Name = "  COFFEE "
+	result, err = database.ImportExpenditures(ctx, pool, reimported)
+	require.NoError(t, err)
+	require.Empty(t, result.Inserted)
+	require.Equal(t, 3, result.Skipped)
+
+	// A coffee the next day is new, but might be a duplicate. A differently named charge with a
+	// known external ID is skipped. The same amount at the bookstore two days later on another card
+	// might be a duplicate.
+	result, err = database.ImportExpenditures(ctx, pool, []*model.Expenditure{
+		{Owner: owner, Name: "Coffee", Amount: model.MoneyFromFloat(4.5), Date: date.AddDate(0, 0, 1), Method: method},
+		{Owner: owner, Name: "BOOKSTORE #12", Amount: model.MoneyFromFloat(20), Date: date, Method: method, ExternalID: "1"},
+		{Owner: owner, Name: "Bookstore", Amount: model.MoneyFromFloat(20), Date: date.AddDate(0, 0, 2), Method: uuid.New()},
+	})
+	require.NoError(t, err)
+	require.Len(t, result.Inserted, 2)
+	require.Equal(t, 1, result.Skipped)
+	require.Len(t, result.PossibleDuplicates, 2)
+
+	coffees := result.PossibleDuplicates[0]
+	require.Equal(t, result.Inserted[0], coffees.Expenditure)
+	require.Len(t, coffees.Matches, 2)
+
+	books := result.PossibleDuplicates[1]
+	require.Equal(t, result.Inserted[1], books.Expenditure)
+	require.Len(t, books.Matches, 1)
+	require.Equal(t, "1", books.Matches[0].ExternalID)
+
+	stored, err := database.ListExpenditures(ctx, pool, nil, nil, nil, nil, nil, date, date.AddDate(0, 0, 2), nil, nil)
+	require.NoError(t, err)
+	require.Len(t, stored, 5)
+}
@@ -0,0 +1,147 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"yaba/errors"
+	"yaba/internal/ctxutil"
+	"yaba/internal/model"
+
+	"github.com/Masterminds/squirrel"
+	"github.com/georgysavva/scany/v2/pgxscan"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// ListExpenditureSplits returns the splits of the user's expenditure in the order they were set.
+func ListExpenditureSplits(
+	ctx context.Context,
+	pool *pgxpool.Pool,
+	expenditureID int,
+) ([]*model.ExpenditureSplit, error) {
+	splits, err := ListSplitsByExpenditure(ctx, pool, []int{expenditureID})
+	if err != nil {
+		return nil, err
+	}
+
+	return splits[expenditureID], nil
+}
+
+// ListSplitsByExpenditure returns the splits of several of the user's expenditures at once, mapped by
+// expenditure ID. Expenditures without splits are left out.
+func ListSplitsByExpenditure(
+	ctx context.Context,
+	pool *pgxpool.Pool,
+	expenditureIDs []int,
+) (map[int][]*model.ExpenditureSplit, error) {
+	query, args, err := squirrel.Select("*").
+		From("expenditure_split").
+		Where("owner = ? AND expenditure_id = ANY(?)", ctxutil.GetUser(ctx), expenditureIDs).
+		OrderBy("id").
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("failed to build query: %w", err)
+	}
+
+	var splits []*model.ExpenditureSplit
+	if err = pgxscan.Select(ctx, pool, &splits, query, args...); err != nil {
+		return nil, fmt.Errorf("failed to list expenditure splits: %w", err)
+	}
+
+	byExpenditure := make(map[int][]*model.ExpenditureSplit)
+	for _, split := range splits {
+		byExpenditure[split.ExpenditureID] = append(byExpenditure[split.ExpenditureID], split)
+	}
+
+	return byExpenditure, nil
+}
+
+// SetExpenditureSplits replaces the splits of the user's expenditure. The split amounts must add up
+// to the expenditure's amount, to the cent. No splits removes them, so the whole amount counts
+// towards the expenditure's own category again.
+func SetExpenditureSplits(
+	ctx context.Context,
+	pool *pgxpool.Pool,
+	expenditureID int,
+	splits []*model.ExpenditureSplit,
+) error {
+	expenditure, err := GetExpenditure(ctx, pool, expenditureID)
+	if err != nil {
+		return err
+	}
+
+	var total model.Money
+	for _, split := range splits {
+		total += split.Amount
+	}
+
+	if len(splits) > 0 && total.Round() != expenditure.Amount.Round() {
+		return fmt.Errorf("splits add up to %s instead of %s: %w",
+			total, expenditure.Amount, errors.InvalidInputError{Input: splits})
+	}
+
+	budgetMap, err := getExpenseIDsByCategory(ctx, pool)
+	if err != nil {
+		return err
+	}
+
+	user := ctxutil.GetUser(ctx)
+	insert := squirrel.Insert("expenditure_split").
+		Columns("expenditure_id", "owner", "budget_category", "expense_id", "amount").
+		Suffix("RETURNING id")
+
+	for _, split := range splits {
+		split.ExpenditureID = expenditureID
+		split.Owner = user
+		split.ExpenseID = budgetMap[strings.ToLower(split.BudgetCategory)]
+		insert = insert.Values(split.ExpenditureID, split.Owner, split.BudgetCategory, split.ExpenseID, split.Amount)
+	}
+
+	tx, err := pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("could not start transaction: %w", err)
+	}
+
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	query, args, err := squirrel.Delete("expenditure_split").
+		Where(squirrel.Eq{
+			"expenditure_id": expenditureID,
+			"owner":          user,
+		}).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("failed to build query: %w", err)
+	}
+
+	if _, err = tx.Exec(ctx, query, args...); err != nil {
+		return fmt.Errorf("failed to delete expenditure splits: %w", err)
+	}
+
+	if len(splits) > 0 {
+		if query, args, err = insert.ToSql(); err != nil {
+			return fmt.Errorf("failed to build query: %w", err)
+		}
+
+		rows, err := tx.Query(ctx, query, args...)
+		if err != nil {
+			return fmt.Errorf("failed to insert expenditure splits: %w", err)
+		}
+
+		ids, err := pgx.CollectRows(rows, pgx.RowTo[int])
+		if err != nil {
+			return fmt.Errorf("failed to insert expenditure splits: %w", err)
+		}
+
+		for i, id := range ids {
+			splits[i].ID = id
+		}
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,102 @@
+package database_test
+
+import (
+	"testing"
+	"time"
+	"yaba/internal/ctxutil"
+	"yaba/internal/database"
+	"yaba/internal/model"
+	"yaba/internal/test/helper"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExpenditureSplits(t *testing.T) {
+	t.Parallel()
+
+	pool := helper.GetTestPool()
+	owner := uuid.New()
+	ctx := ctxutil.WithUser(t.Context(), owner)
+
+	budget := model.NewBudget(owner, "split budget")
+	budget.SetBasicExpense("Groceries", model.MoneyFromFloat(300))
+	budget.SetBasicExpense("Household", model.MoneyFromFloat(100))
+	require.NoError(t, database.PersistBudget(ctx, pool, budget))
+
+	groceries, household := budget.Expenses[0].ID, budget.Expenses[1].ID
+	date := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+
+	result, err := database.ImportExpenditures(ctx, pool, []*model.Expenditure{
+		{Owner: owner, Name: "costco", Amount: model.MoneyFromFloat(150), Date: date, BudgetCategory: "Groceries"},
+		{Owner: owner, Name: "loblaws", Amount: model.MoneyFromFloat(40), Date: date, BudgetCategory: "Groceries"},
+	})
+	require.NoError(t, err)
+
+	costco := result.Inserted[0]
+
+	err = database.SetExpenditureSplits(ctx, pool, costco.ID, []*model.ExpenditureSplit{
+		{BudgetCategory: "Groceries", Amount: model.MoneyFromFloat(100)},
+		{BudgetCategory: "Household", Amount: model.MoneyFromFloat(40)},
+	})
+	require.ErrorContains(t, err, "splits add up to 140.00 instead of 150.00")
+
+	splits := []*model.ExpenditureSplit{
+		{BudgetCategory: "Groceries", Amount: model.MoneyFromFloat(100)},
+		{BudgetCategory: "household", Amount: model.MoneyFromFloat(30)},
+		{BudgetCategory: "Clothing", Amount: model.MoneyFromFloat(20)},
+	}
+	require.NoError(t, database.SetExpenditureSplits(ctx, pool, costco.ID, splits))
+	require.Equal(t, household, splits[1].ExpenseID)
+	require.Equal(t, uuid.Nil, splits[2].ExpenseID)
+
+	stored, err := database.ListExpenditureSplits(ctx, pool, costco.ID)
+	require.NoError(t, err)
+	require.Equal(t, splits, stored)
+
+	// Splits of several expenditures are listed together, without the unsplit ones
+	byExpenditure, err := database.ListSplitsByExpenditure(ctx, pool, []int{costco.ID, result.Inserted[1].ID})
+	require.NoError(t, err)
+	require.Equal(t, map[int][]*model.ExpenditureSplit{costco.ID: splits}, byExpenditure)
+
+	// Other users can't see or change the splits
+	otherCtx := ctxutil.WithUser(t.Context(), uuid.New())
+	stored, err = database.ListExpenditureSplits(otherCtx, pool, costco.ID)
+	require.NoError(t, err)
+	require.Empty(t, stored)
+	require.ErrorContains(t, database.SetExpenditureSplits(otherCtx, pool, costco.ID, nil), "no such element")
+
+	aggregate := func() map[string]float64 {
+		summaries, err := database.AggregateExpenditures(ctx, pool, date, date,
+			model.TimespanDay, model.AggregationSum, model.GroupByBudgetCategory, model.RefundAttributionOriginal, false)
+		require.NoError(t, err)
+
+		totals := make(map[string]float64)
+		for _, summary := range summaries {
+			totals[summary.Category] = summary.Amount.Float64()
+		}
+
+		return totals
+	}
+
+	require.Equal(t, map[string]float64{
+		groceries.String(): 140,
+		household.String(): 30,
+		uuid.Nil.String():  20,
+	}, aggregate())
+
+	// Splits are kept when the amount doesn't change, and removed when they no longer add up
+	costco.Comment = "membership renewal"
+	require.NoError(t, database.UpdateExpenditure(ctx, pool, costco))
+	stored, err = database.ListExpenditureSplits(ctx, pool, costco.ID)
+	require.NoError(t, err)
+	require.Len(t, stored, 3)
+
+	costco.Amount = model.MoneyFromFloat(160)
+	require.NoError(t, database.UpdateExpenditure(ctx, pool, costco))
+	stored, err = database.ListExpenditureSplits(ctx, pool, costco.ID)
+	require.NoError(t, err)
+	require.Empty(t, stored)
+
+	require.Equal(t, map[string]float64{groceries.String(): 200}, aggregate())
+}
@@ -0,0 +1,192 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+	"yaba/errors"
+	"yaba/internal/ctxutil"
+	"yaba/internal/model"
+
+	"github.com/Masterminds/squirrel"
+	"github.com/georgysavva/scany/v2/pgxscan"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// listBatchAttachments lists the attachments of the expenditures of import batch $1 of user $2.
+const listBatchAttachments = `
+SELECT a.*
+FROM attachment a
+JOIN expenditure e ON e.id = a.expenditure_id
+WHERE e.batch_id = $1
+  AND e.owner = $2
+`
+
+// createImportBatch saves the import batch with the row count and date range of the expenditures
+// inserted in it.
+func createImportBatch(
+	ctx context.Context,
+	tx pgx.Tx,
+	batch *model.ImportBatch,
+	inserted []*model.Expenditure,
+) error {
+	batch.RowCount = len(inserted)
+
+	for _, e := range inserted {
+		if !batch.StartDate.Valid || e.Date.Before(batch.StartDate.Time) {
+			batch.StartDate = sql.NullTime{Time: e.Date, Valid: true}
+		}
+
+		if !batch.EndDate.Valid || e.Date.After(batch.EndDate.Time) {
+			batch.EndDate = sql.NullTime{Time: e.Date, Valid: true}
+		}
+	}
+
+	query, args, err := squirrel.Insert("import_batch").
+		Columns("id", "owner", "source", "row_count", "start_date", "end_date").
+		Values(batch.ID, batch.Owner, batch.Source, batch.RowCount, batch.StartDate, batch.EndDate).
+		Suffix("RETURNING created").
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("failed to build query: %w", err)
+	}
+
+	if err = tx.QueryRow(ctx, query, args...).Scan(&batch.CreatedTime); err != nil {
+		return fmt.Errorf("failed to create import batch: %w", err)
+	}
+
+	return nil
+}
+
+// ListImportBatches returns the user's import batches, most recent first.
+func ListImportBatches(ctx context.Context, pool *pgxpool.Pool) ([]*model.ImportBatch, error) {
+	query, args, err := squirrel.Select("*").
+		From("import_batch").
+		Where(squirrel.Eq{"owner": ctxutil.GetUser(ctx)}).
+		OrderBy("created DESC", "id").
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("failed to build query: %w", err)
+	}
+
+	var batches []*model.ImportBatch
+	if err = pgxscan.Select(ctx, pool, &batches, query, args...); err != nil {
+		return nil, fmt.Errorf("failed to get import batches: %w", err)
+	}
+
+	return batches, nil
+}
+
+func GetImportBatch(ctx context.Context, pool *pgxpool.Pool, id uuid.UUID) (*model.ImportBatch, error) {
+	query, args, err := squirrel.Select("*").
+		From("import_batch").
+		Where(squirrel.Eq{
+			"id":    id,
+			"owner": ctxutil.GetUser(ctx),
+		}).
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("failed to build query: %w", err)
+	}
+
+	var batches []*model.ImportBatch
+	if err = pgxscan.Select(ctx, pool, &batches, query, args...); err != nil {
+		return nil, fmt.Errorf("failed to get import batch: %w", err)
+	}
+
+	if len(batches) == 0 {
+		return nil, errors.NoSuchElementError{Element: id}
+	}
+
+	return batches[0], nil
+}
+
+// ListImportBatchExpenditures returns the expenditures saved in the import batch.
+func ListImportBatchExpenditures(
+	ctx context.Context,
+	pool *pgxpool.Pool,
+	id uuid.UUID,
+) ([]*model.Expenditure, error) {
+	query, args, err := squirrel.Select(expenditureColumns...).
+		From("expenditure").
+		Where(squirrel.Eq{
+			"batch_id":   id,
+			"owner":      ctxutil.GetUser(ctx),
+			"deleted_at": nil,
+		}).
+		OrderBy("date DESC, id DESC").
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("failed to build query: %w", err)
+	}
+
+	var expenditures []*model.Expenditure
+	if err = pgxscan.Select(ctx, pool, &expenditures, query, args...); err != nil {
+		return nil, fmt.Errorf("failed to get expenditures: %w", err)
+	}
+
+	return expenditures, nil
+}
+
+// UndoImport deletes the import batch and the expenditures saved in it, and returns the number of
+// expenditures removed. Recurring series are detected again without them. The expenditures are
+// deleted permanently rather than moved to the trash, so that the statement can be imported again.
+// It also returns the attachments of the deleted expenditures, whose files must be removed from the
+// blob store.
+func UndoImport(ctx context.Context, pool *pgxpool.Pool, id uuid.UUID) (int64, []*model.Attachment, error) {
+	user := ctxutil.GetUser(ctx)
+
+	deleteBatch, deleteBatchArgs, err := squirrel.Delete("import_batch").
+		Where(squirrel.Eq{"id": id, "owner": user}).
+		ToSql()
+	if err != nil {
+		return 0, nil, fmt.Errorf("failed to build query: %w", err)
+	}
+
+	deleteExpenditures, deleteExpendituresArgs, err := squirrel.Delete("expenditure").
+		Where(squirrel.Eq{"batch_id": id, "owner": user}).
+		ToSql()
+	if err != nil {
+		return 0, nil, fmt.Errorf("failed to build query: %w", err)
+	}
+
+	tx, err := pool.Begin(ctx)
+	if err != nil {
+		return 0, nil, fmt.Errorf("could not start transaction: %w", err)
+	}
+
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	// The attachments are deleted with the expenditures, so they are listed first.
+	var attachments []*model.Attachment
+	if err = pgxscan.Select(ctx, tx, &attachments, listBatchAttachments, id, user); err != nil {
+		return 0, nil, fmt.Errorf("failed to list attachments: %w", err)
+	}
+
+	tag, err := tx.Exec(ctx, deleteBatch, deleteBatchArgs...)
+	if err != nil {
+		return 0, nil, fmt.Errorf("failed to delete import batch: %w", err)
+	}
+
+	if tag.RowsAffected() == 0 {
+		return 0, nil, errors.NoSuchElementError{Element: id}
+	}
+
+	if tag, err = tx.Exec(ctx, deleteExpenditures, deleteExpendituresArgs...); err != nil {
+		return 0, nil, fmt.Errorf("failed to delete expenditures: %w", err)
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return 0, nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	// The import is already undone, so failing to detect recurring series again is only logged.
+	if _, err = DetectRecurringSeries(ctx, pool); err != nil {
+		log.Println("failed to detect recurring expenditures after undoing import:", err)
+	}
+
+	return tag.RowsAffected(), attachments, nil
+}
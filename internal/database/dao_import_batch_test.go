@@ -0,0 +1,77 @@
+package database_test
+
+import (
+	"testing"
+	"time"
+	"yaba/internal/ctxutil"
+	"yaba/internal/database"
+	"yaba/internal/model"
+	"yaba/internal/test/helper"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+func TestImportBatches(t *testing.T) {
+	t.Parallel()
+
+	pool := helper.GetTestPool()
+	owner := uuid.New()
+	ctx := ctxutil.WithUser(t.Context(), owner)
+	startDate := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	endDate := time.Date(2024, 1, 31, 0, 0, 0, 0, time.UTC)
+
+	generator := helper.NewTestDataGenerator(owner, 4242)
+	generator.GenerateExpenditures(10, owner, startDate, endDate)
+	require.NoError(t, generator.PersistAll(ctx, pool))
+
+	second, err := database.ImportExpenditures(ctx, pool, []*model.Expenditure{
+		{Owner: owner, Name: "late fee", Amount: model.MoneyFromFloat(35), Date: endDate, Source: "fees.csv"},
+		{Owner: owner, Name: "interest", Amount: model.MoneyFromFloat(12.5), Date: endDate.AddDate(0, 0, -3),
+			Source: "fees.csv"},
+	})
+	require.NoError(t, err)
+	require.Equal(t, "fees.csv", second.Batch.Source)
+	require.Equal(t, 2, second.Batch.RowCount)
+	require.Equal(t, endDate.AddDate(0, 0, -3), second.Batch.StartDate.Time.UTC())
+	require.Equal(t, endDate, second.Batch.EndDate.Time.UTC())
+
+	batches, err := database.ListImportBatches(ctx, pool)
+	require.NoError(t, err)
+	require.Len(t, batches, 2)
+	require.Equal(t, second.Batch.ID, batches[0].ID)
+	require.Equal(t, 10, batches[1].RowCount)
+
+	batch, err := database.GetImportBatch(ctx, pool, second.Batch.ID)
+	require.NoError(t, err)
+	require.Equal(t, batches[0], batch)
+
+	expenditures, err := database.ListImportBatchExpenditures(ctx, pool, batch.ID)
+	require.NoError(t, err)
+	require.Len(t, expenditures, 2)
+	require.Equal(t, "late fee", expenditures[0].Name)
+
+	// Other users can't see or undo the batch
+	otherCtx := ctxutil.WithUser(t.Context(), uuid.New())
+	_, err = database.GetImportBatch(otherCtx, pool, batch.ID)
+	require.ErrorContains(t, err, "no such element")
+	_, _, err = database.UndoImport(otherCtx, pool, batch.ID)
+	require.ErrorContains(t, err, "no such element")
+
+	deleted, _, err := database.UndoImport(ctx, pool, batch.ID)
+	require.NoError(t, err)
+	require.Equal(t, int64(2), deleted)
+
+	remaining, err := database.ListExpenditures(
+		ctx, pool, nil, nil, nil, nil, nil, startDate, endDate.AddDate(0, 0, 1), nil, nil)
+	require.NoError(t, err)
+	require.Len(t, remaining, 10)
+
+	_, err = database.GetImportBatch(ctx, pool, batch.ID)
+	require.ErrorContains(t, err, "no such element")
+
+	// Undone expenditures can be imported again
+	second, err = database.ImportExpenditures(ctx, pool, expenditures)
+	require.NoError(t, err)
+	require.Len(t, second.Inserted, 2)
+}
@@ -0,0 +1,241 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"yaba/errors"
+	"yaba/internal/ctxutil"
+	"yaba/internal/merchant"
+	"yaba/internal/model"
+
+	"github.com/Masterminds/squirrel"
+	"github.com/georgysavva/scany/v2/pgxscan"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type merchantAlias struct {
+	Pattern    string    `db:"pattern"`
+	MerchantID uuid.UUID `db:"merchant_id"`
+}
+
+// linkMerchants sets the merchant of each expenditure from its normalized name, creating merchants
+// for names that haven't been seen before.
+func linkMerchants(ctx context.Context, tx pgx.Tx, expenditures []*model.Expenditure) error {
+	user := ctxutil.GetUser(ctx)
+	merchantIDs := make(map[string]uuid.UUID)
+
+	for _, e := range expenditures {
+		if key := merchant.Normalize(e.Name); key != "" {
+			merchantIDs[key] = uuid.Nil
+		}
+	}
+
+	if len(merchantIDs) == 0 {
+		return nil
+	}
+
+	keys := make([]string, 0, len(merchantIDs))
+	for key := range merchantIDs {
+		keys = append(keys, key)
+	}
+
+	slices.Sort(keys)
+
+	query, args, err := squirrel.Select("pattern", "merchant_id").
+		From("merchant_alias").
+		Where("owner = ? AND pattern = ANY(?)", user, keys).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("failed to build query: %w", err)
+	}
+
+	var aliases []*merchantAlias
+	if err = pgxscan.Select(ctx, tx, &aliases, query, args...); err != nil {
+		return fmt.Errorf("failed to get merchant aliases: %w", err)
+	}
+
+	for _, alias := range aliases {
+		merchantIDs[alias.Pattern] = alias.MerchantID
+	}
+
+	for _, key := range keys {
+		if merchantIDs[key] != uuid.Nil {
+			continue
+		}
+
+		if merchantIDs[key], err = createMerchant(ctx, tx, key); err != nil {
+			return err
+		}
+	}
+
+	for _, e := range expenditures {
+		e.MerchantID = merchantIDs[merchant.Normalize(e.Name)]
+	}
+
+	return nil
+}
+
+// createMerchant adds the normalized name as an alias of the merchant with the matching display
+// name, creating the merchant if needed.
+func createMerchant(ctx context.Context, tx pgx.Tx, key string) (uuid.UUID, error) {
+	user := ctxutil.GetUser(ctx)
+
+	query, args, err := squirrel.Insert("merchant").
+		Columns("owner", "name").
+		Values(user, merchant.DisplayName(key)).
+		Suffix("ON CONFLICT (owner, name) DO UPDATE SET name = EXCLUDED.name RETURNING id").
+		ToSql()
+	if err != nil {
+		return uuid.Nil, fmt.Errorf("failed to build query: %w", err)
+	}
+
+	var id uuid.UUID
+	if err = tx.QueryRow(ctx, query, args...).Scan(&id); err != nil {
+		return uuid.Nil, fmt.Errorf("failed to create merchant: %w", err)
+	}
+
+	query, args, err = squirrel.Insert("merchant_alias").
+		Columns("owner", "pattern", "merchant_id").
+		Values(user, key, id).
+		Suffix("ON CONFLICT DO NOTHING").
+		ToSql()
+	if err != nil {
+		return uuid.Nil, fmt.Errorf("failed to build query: %w", err)
+	}
+
+	if _, err = tx.Exec(ctx, query, args...); err != nil {
+		return uuid.Nil, fmt.Errorf("failed to create merchant alias: %w", err)
+	}
+
+	return id, nil
+}
+
+// ListMerchants returns the user's merchants and their aliases, ordered by name.
+func ListMerchants(ctx context.Context, pool *pgxpool.Pool) ([]*model.Merchant, error) {
+	return listMerchants(ctx, pool, squirrel.Eq{"owner": ctxutil.GetUser(ctx)})
+}
+
+func GetMerchant(ctx context.Context, pool *pgxpool.Pool, id uuid.UUID) (*model.Merchant, error) {
+	merchants, err := listMerchants(ctx, pool, squirrel.Eq{"owner": ctxutil.GetUser(ctx), "id": id})
+	if err != nil {
+		return nil, err
+	}
+
+	if len(merchants) == 0 {
+		return nil, errors.NoSuchElementError{Element: id}
+	}
+
+	return merchants[0], nil
+}
+
+func listMerchants(ctx context.Context, pool *pgxpool.Pool, where squirrel.Eq) ([]*model.Merchant, error) {
+	query, args, err := squirrel.Select("*").
+		From("merchant").
+		Where(where).
+		OrderBy("name").
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("failed to build query: %w", err)
+	}
+
+	var merchants []*model.Merchant
+	if err = pgxscan.Select(ctx, pool, &merchants, query, args...); err != nil {
+		return nil, fmt.Errorf("failed to list merchants: %w", err)
+	}
+
+	if len(merchants) == 0 {
+		return merchants, nil
+	}
+
+	byID := make(map[uuid.UUID]*model.Merchant, len(merchants))
+	ids := make([]uuid.UUID, len(merchants))
+
+	for i, m := range merchants {
+		m.Aliases = []string{}
+		byID[m.ID] = m
+		ids[i] = m.ID
+	}
+
+	query, args, err = squirrel.Select("pattern", "merchant_id").
+		From("merchant_alias").
+		Where("owner = ? AND merchant_id = ANY(?)", ctxutil.GetUser(ctx), ids).
+		OrderBy("pattern").
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("failed to build query: %w", err)
+	}
+
+	var aliases []*merchantAlias
+	if err = pgxscan.Select(ctx, pool, &aliases, query, args...); err != nil {
+		return nil, fmt.Errorf("failed to list merchant aliases: %w", err)
+	}
+
+	for _, alias := range aliases {
+		byID[alias.MerchantID].Aliases = append(byID[alias.MerchantID].Aliases, alias.Pattern)
+	}
+
+	return merchants, nil
+}
+
+// MergeMerchants moves the aliases and expenditures of one merchant to another and deletes it.
+func MergeMerchants(ctx context.Context, pool *pgxpool.Pool, from, into uuid.UUID) error {
+	if from == into {
+		return fmt.Errorf("cannot merge a merchant into itself: %w", errors.InvalidInputError{Input: from})
+	}
+
+	if _, err := GetMerchant(ctx, pool, into); err != nil {
+		return err
+	}
+
+	user := ctxutil.GetUser(ctx)
+	batch := &pgx.Batch{}
+
+	for _, table := range []string{"merchant_alias", "expenditure"} {
+		query, args, err := squirrel.Update(table).
+			Set("merchant_id", into).
+			Where(squirrel.Eq{"merchant_id": from, "owner": user}).
+			ToSql()
+		if err != nil {
+			return fmt.Errorf("failed to build query: %w", err)
+		}
+
+		batch.Queue(query, args...)
+	}
+
+	tx, err := pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("could not start transaction: %w", err)
+	}
+
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	query, args, err := squirrel.Delete("merchant").
+		Where(squirrel.Eq{"id": from, "owner": user}).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("failed to build query: %w", err)
+	}
+
+	// Aliases must be moved before the merchant is deleted, or they are deleted with it.
+	if err = tx.SendBatch(ctx, batch).Close(); err != nil {
+		return fmt.Errorf("failed to merge merchants: %w", err)
+	}
+
+	tag, err := tx.Exec(ctx, query, args...)
+	if err != nil {
+		return fmt.Errorf("failed to delete merchant: %w", err)
+	}
+
+	if tag.RowsAffected() == 0 {
+		return errors.NoSuchElementError{Element: from}
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,78 @@
+package database_test
+
+import (
+	"testing"
+	"time"
+	"yaba/internal/ctxutil"
+	"yaba/internal/database"
+	"yaba/internal/model"
+	"yaba/internal/test/helper"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMerchants(t *testing.T) {
+	t.Parallel()
+
+	pool := helper.GetTestPool()
+	owner := uuid.New()
+	ctx := ctxutil.WithUser(t.Context(), owner)
+	date := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+
+	result, err := database.ImportExpenditures(ctx, pool, []*model.Expenditure{
+		{Owner: owner, Name: "SQ *BLUE BOTTLE 1234", Amount: model.MoneyFromFloat(5), Date: date},
+		{Owner: owner, Name: "Blue Bottle #55", Amount: model.MoneyFromFloat(6), Date: date.AddDate(0, 0, 1)},
+		{Owner: owner, Name: "BLUEBOTTLE COFFEE", Amount: model.MoneyFromFloat(7), Date: date.AddDate(0, 0, 2)},
+		{Owner: owner, Name: "", Amount: model.MoneyFromFloat(8), Date: date.AddDate(0, 0, 3)},
+	})
+	require.NoError(t, err)
+	require.Len(t, result.Inserted, 4)
+
+	blueBottle := result.Inserted[0].MerchantID
+	require.NotEqual(t, uuid.Nil, blueBottle)
+	require.Equal(t, blueBottle, result.Inserted[1].MerchantID)
+	require.NotEqual(t, blueBottle, result.Inserted[2].MerchantID)
+	require.Equal(t, uuid.Nil, result.Inserted[3].MerchantID)
+
+	merchants, err := database.ListMerchants(ctx, pool)
+	require.NoError(t, err)
+	require.Len(t, merchants, 2)
+	require.Equal(t, "Blue Bottle", merchants[0].Name)
+	require.Equal(t, []string{"BLUE BOTTLE"}, merchants[0].Aliases)
+	require.Equal(t, "Bluebottle Coffee", merchants[1].Name)
+
+	// Other users have their own merchants
+	otherCtx := ctxutil.WithUser(t.Context(), uuid.New())
+	_, err = database.GetMerchant(otherCtx, pool, blueBottle)
+	require.ErrorContains(t, err, "no such element")
+	require.Error(t, database.MergeMerchants(otherCtx, pool, merchants[1].ID, blueBottle))
+
+	require.Error(t, database.MergeMerchants(ctx, pool, blueBottle, blueBottle))
+	require.NoError(t, database.MergeMerchants(ctx, pool, merchants[1].ID, blueBottle))
+
+	merged, err := database.GetMerchant(ctx, pool, blueBottle)
+	require.NoError(t, err)
+	require.Equal(t, []string{"BLUE BOTTLE", "BLUEBOTTLE COFFEE"}, merged.Aliases)
+
+	_, err = database.GetMerchant(ctx, pool, merchants[1].ID)
+	require.ErrorContains(t, err, "no such element")
+
+	// New expenditures use the merged alias
+	result, err = database.ImportExpenditures(ctx, pool, []*model.Expenditure{
+		{Owner: owner, Name: "BLUEBOTTLE COFFEE", Amount: model.MoneyFromFloat(9), Date: date.AddDate(0, 0, 4)},
+	})
+	require.NoError(t, err)
+	require.Equal(t, blueBottle, result.Inserted[0].MerchantID)
+
+	summaries, err := database.AggregateExpenditures(ctx, pool, date, date.AddDate(0, 1, 0),
+		model.TimespanMonth, model.AggregationSum, model.GroupByMerchant, model.RefundAttributionOriginal, false)
+	require.NoError(t, err)
+
+	totals := make(map[string]float64)
+	for _, summary := range summaries {
+		totals[summary.Category] += summary.Amount.Float64()
+	}
+
+	require.Equal(t, map[string]float64{blueBottle.String(): 27, uuid.Nil.String(): 8}, totals)
+}
@@ -20,8 +20,9 @@ func GetPaymentMethod(
 	pmQuery, pmArgs, err := squirrel.Select("*").
 		From("payment_method").
 		Where(squirrel.Eq{
-			"id":    id,
-			"owner": ctxutil.GetUser(ctx),
+			"id":         id,
+			"owner":      ctxutil.GetUser(ctx),
+			"deleted_at": nil,
 		}).
 		ToSql()
 	if err != nil {
@@ -50,6 +51,7 @@ func GetPaymentMethodByName(
 		Where(squirrel.Eq{
 			"display_name": name,
 			"owner":        ctxutil.GetUser(ctx),
+			"deleted_at":   nil,
 		}).
 		PlaceholderFormat(squirrel.Dollar).
 		ToSql()
@@ -68,7 +70,7 @@ func GetPaymentMethodByName(
 func ListPaymentMethods(ctx context.Context, pool *pgxpool.Pool) ([]*model.PaymentMethod, error) {
 	query, args, err := squirrel.Select("*").
 		From("payment_method").
-		Where(squirrel.Eq{"owner": ctxutil.GetUser(ctx)}).
+		Where(squirrel.Eq{"owner": ctxutil.GetUser(ctx), "deleted_at": nil}).
 		OrderBy("display_name").
 		PlaceholderFormat(squirrel.Dollar).
 		ToSql()
@@ -98,9 +100,10 @@ func CreatePaymentMethod(
 	method.Owner = ctxutil.GetUser(ctx)
 
 	query, args, err := squirrel.Insert("payment_method").
-		Columns("id", "owner", "display_name", "card_type", "acquired_date", "cancel_by_date").
+		Columns("id", "owner", "display_name", "card_type", "acquired_date", "cancel_by_date",
+			"account_id").
 		Values(method.ID, method.Owner, method.DisplayName, method.CardType,
-			method.AcquiredDate, method.CancelByDate).
+			method.AcquiredDate, method.CancelByDate, method.AccountID).
 		PlaceholderFormat(squirrel.Dollar).
 		ToSql()
 	if err != nil {
@@ -124,9 +127,11 @@ func UpdatePaymentMethod(
 		Set("acquired_date", method.AcquiredDate).
 		Set("cancel_by_date", method.CancelByDate).
 		Set("card_type", method.CardType).
+		Set("account_id", method.AccountID).
 		Where(squirrel.Eq{
-			"id":    method.ID,
-			"owner": ctxutil.GetUser(ctx),
+			"id":         method.ID,
+			"owner":      ctxutil.GetUser(ctx),
+			"deleted_at": nil,
 		}).
 		PlaceholderFormat(squirrel.Dollar).
 		ToSql()
@@ -141,11 +146,15 @@ func UpdatePaymentMethod(
 	return nil
 }
 
+// DeletePaymentMethod moves the payment method to the trash. Its expenditures keep it until it is
+// purged.
 func DeletePaymentMethod(ctx context.Context, pool *pgxpool.Pool, id uuid.UUID) (bool, error) {
-	query, args, err := squirrel.Delete("payment_method").
+	query, args, err := squirrel.Update("payment_method").
+		Set("deleted_at", squirrel.Expr("NOW()")).
 		Where(squirrel.Eq{
-			"id":    id,
-			"owner": ctxutil.GetUser(ctx),
+			"id":         id,
+			"owner":      ctxutil.GetUser(ctx),
+			"deleted_at": nil,
 		}).
 		PlaceholderFormat(squirrel.Dollar).
 		ToSql()
@@ -0,0 +1,137 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"time"
+	"yaba/errors"
+	"yaba/internal/ctxutil"
+	"yaba/internal/model"
+	"yaba/internal/recurring"
+
+	"github.com/Masterminds/squirrel"
+	"github.com/georgysavva/scany/v2/pgxscan"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// recurringHistoryYears is how far back expenditures are scanned for recurring series, enough to
+// see a yearly charge twice.
+const recurringHistoryYears = 3
+
+// DetectRecurringSeries replaces the user's recurring series with the ones detected in their recent
+// expenditures.
+func DetectRecurringSeries(ctx context.Context, pool *pgxpool.Pool) ([]*model.RecurringSeries, error) {
+	user := ctxutil.GetUser(ctx)
+	today := time.Now().UTC().Truncate(24 * time.Hour)
+
+	query, args, err := squirrel.Select(expenditureColumns...).
+		From("expenditure").
+		Where(squirrel.Eq{"owner": user, "kind": model.KindExpense, "refund_of": nil, "deleted_at": nil}).
+		Where("merchant_id != ? AND amount > 0 AND date >= ?", uuid.Nil, today.AddDate(-recurringHistoryYears, 0, 0)).
+		OrderBy("date", "id").
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("failed to build query: %w", err)
+	}
+
+	var expenditures []*model.Expenditure
+	if err = pgxscan.Select(ctx, pool, &expenditures, query, args...); err != nil {
+		return nil, fmt.Errorf("failed to get expenditures: %w", err)
+	}
+
+	series := recurring.Detect(expenditures, today)
+
+	tx, err := pool.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("could not start transaction: %w", err)
+	}
+
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	query, args, err = squirrel.Delete("recurring_series").
+		Where(squirrel.Eq{"owner": user}).
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("failed to build query: %w", err)
+	}
+
+	if _, err = tx.Exec(ctx, query, args...); err != nil {
+		return nil, fmt.Errorf("failed to delete recurring series: %w", err)
+	}
+
+	if len(series) > 0 {
+		insert := squirrel.Insert("recurring_series").
+			Columns("id", "owner", "merchant_id", "name", "cadence", "amount", "previous_amount", "occurrences",
+				"last_date", "next_date", "price_changed")
+
+		for _, s := range series {
+			s.ID = uuid.New()
+			s.Owner = user
+			insert = insert.Values(s.ID, s.Owner, s.MerchantID, s.Name, s.Cadence, s.Amount, s.PreviousAmount,
+				s.Occurrences, s.LastDate, s.NextDate, s.PriceChanged)
+		}
+
+		if query, args, err = insert.ToSql(); err != nil {
+			return nil, fmt.Errorf("failed to build query: %w", err)
+		}
+
+		if _, err = tx.Exec(ctx, query, args...); err != nil {
+			return nil, fmt.Errorf("failed to save recurring series: %w", err)
+		}
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return series, nil
+}
+
+// ListRecurringSeries returns the user's recurring series ordered by the next expected charge.
+func ListRecurringSeries(ctx context.Context, pool *pgxpool.Pool) ([]*model.RecurringSeries, error) {
+	return listRecurringSeries(ctx, pool, squirrel.Eq{"owner": ctxutil.GetUser(ctx)})
+}
+
+// ListUpcomingCharges returns the user's recurring series that are expected to charge again in the
+// next days. Overdue charges are left out.
+func ListUpcomingCharges(ctx context.Context, pool *pgxpool.Pool, days int) ([]*model.RecurringSeries, error) {
+	if days < 0 {
+		return nil, fmt.Errorf("days must not be negative: %w", errors.InvalidInputError{Input: days})
+	}
+
+	today := time.Now().UTC().Truncate(24 * time.Hour)
+
+	return listRecurringSeries(ctx, pool, squirrel.And{
+		squirrel.Eq{"owner": ctxutil.GetUser(ctx)},
+		squirrel.GtOrEq{"next_date": today},
+		squirrel.LtOrEq{"next_date": today.AddDate(0, 0, days)},
+	})
+}
+
+func listRecurringSeries(
+	ctx context.Context,
+	pool *pgxpool.Pool,
+	where squirrel.Sqlizer,
+) ([]*model.RecurringSeries, error) {
+	query, args, err := squirrel.Select("*").
+		From("recurring_series").
+		Where(where).
+		OrderBy("next_date", "name").
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("failed to build query: %w", err)
+	}
+
+	var series []*model.RecurringSeries
+	if err = pgxscan.Select(ctx, pool, &series, query, args...); err != nil {
+		return nil, fmt.Errorf("failed to list recurring series: %w", err)
+	}
+
+	today := time.Now().UTC().Truncate(24 * time.Hour)
+	for _, s := range series {
+		s.Missed = recurring.Missed(s, today)
+	}
+
+	return series, nil
+}
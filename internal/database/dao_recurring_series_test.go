@@ -0,0 +1,79 @@
+package database_test
+
+import (
+	"testing"
+	"time"
+	"yaba/internal/ctxutil"
+	"yaba/internal/database"
+	"yaba/internal/model"
+	"yaba/internal/test/helper"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRecurringSeries(t *testing.T) {
+	t.Parallel()
+
+	pool := helper.GetTestPool()
+	owner := uuid.New()
+	ctx := ctxutil.WithUser(t.Context(), owner)
+	today := time.Now().UTC().Truncate(24 * time.Hour)
+
+	result, err := database.ImportExpenditures(ctx, pool, []*model.Expenditure{
+		{Owner: owner, Name: "SPOTIFY", Amount: model.MoneyFromFloat(10.99), Date: today.AddDate(0, 0, -80)},
+		{Owner: owner, Name: "SPOTIFY", Amount: model.MoneyFromFloat(10.99), Date: today.AddDate(0, 0, -50)},
+		{Owner: owner, Name: "SPOTIFY", Amount: model.MoneyFromFloat(11.99), Date: today.AddDate(0, 0, -20)},
+		{Owner: owner, Name: "YOGA STUDIO", Amount: model.MoneyFromFloat(20), Date: today.AddDate(0, 0, -30)},
+		{Owner: owner, Name: "YOGA STUDIO", Amount: model.MoneyFromFloat(20), Date: today.AddDate(0, 0, -23)},
+		{Owner: owner, Name: "YOGA STUDIO", Amount: model.MoneyFromFloat(20), Date: today.AddDate(0, 0, -16)},
+		{Owner: owner, Name: "HARDWARE STORE", Amount: model.MoneyFromFloat(45), Date: today.AddDate(0, 0, -40)},
+		{Owner: owner, Name: "HARDWARE STORE", Amount: model.MoneyFromFloat(12), Date: today.AddDate(0, 0, -3)},
+	})
+	require.NoError(t, err)
+	require.Len(t, result.Inserted, 8)
+
+	// Series are detected on import
+	series, err := database.ListRecurringSeries(ctx, pool)
+	require.NoError(t, err)
+	require.Len(t, series, 2)
+
+	yoga, spotify := series[0], series[1]
+	require.Equal(t, "YOGA STUDIO", yoga.Name)
+	require.Equal(t, model.CadenceWeekly, yoga.Cadence)
+	require.Equal(t, today.AddDate(0, 0, -9), yoga.NextDate.UTC())
+	require.True(t, yoga.Missed)
+
+	require.Equal(t, model.CadenceMonthly, spotify.Cadence)
+	require.Equal(t, result.Inserted[2].MerchantID, spotify.MerchantID)
+	require.Equal(t, today.AddDate(0, 0, -20).AddDate(0, 1, 0), spotify.NextDate.UTC())
+	require.InDelta(t, 11.99, spotify.Amount.Float64(), 0.001)
+	require.InDelta(t, 10.99, spotify.PreviousAmount.Float64(), 0.001)
+	require.True(t, spotify.PriceChanged)
+	require.False(t, spotify.Missed)
+
+	upcoming, err := database.ListUpcomingCharges(ctx, pool, 31)
+	require.NoError(t, err)
+	require.Len(t, upcoming, 1)
+	require.Equal(t, spotify.ID, upcoming[0].ID)
+
+	upcoming, err = database.ListUpcomingCharges(ctx, pool, 0)
+	require.NoError(t, err)
+	require.Empty(t, upcoming)
+
+	_, err = database.ListUpcomingCharges(ctx, pool, -1)
+	require.Error(t, err)
+
+	// Other users have their own series
+	series, err = database.ListRecurringSeries(ctxutil.WithUser(t.Context(), uuid.New()), pool)
+	require.NoError(t, err)
+	require.Empty(t, series)
+
+	// Undoing the import removes its series
+	_, _, err = database.UndoImport(ctx, pool, result.Batch.ID)
+	require.NoError(t, err)
+
+	series, err = database.ListRecurringSeries(ctx, pool)
+	require.NoError(t, err)
+	require.Empty(t, series)
+}
@@ -0,0 +1,120 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"yaba/errors"
+	"yaba/internal/ctxutil"
+	"yaba/internal/model"
+
+	"github.com/Masterminds/squirrel"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// refundMatchDays is how long after a purchase a credit for the same amount from the same merchant
+// is taken to be its refund.
+const refundMatchDays = 90
+
+// matchRefund links a credit to the most recent purchase for the same amount from the same merchant
+// in the refundMatchDays before it that hasn't been refunded yet.
+const matchRefund = `UPDATE expenditure r
+SET refund_of = (SELECT o.id
+                 FROM expenditure o
+                 WHERE o.owner = r.owner
+                   AND o.merchant_id = r.merchant_id
+                   AND o.amount = -r.amount
+                   AND o.date BETWEEN r.date - $2::INT AND r.date
+                   AND o.deleted_at IS NULL
+                   AND NOT EXISTS (SELECT 1 FROM expenditure x WHERE x.refund_of = o.id)
+                 ORDER BY o.date DESC, o.id DESC
+                 LIMIT 1)
+WHERE r.id = $1
+  AND r.owner = $3
+RETURNING r.refund_of`
+
+// linkRefunds links the credits among newly saved expenditures to the purchases they refund. Credits
+// without a merchant are left alone.
+func linkRefunds(ctx context.Context, tx pgx.Tx, expenditures []*model.Expenditure) error {
+	batch := &pgx.Batch{}
+
+	for _, e := range expenditures {
+		if e.Amount >= 0 || e.MerchantID == uuid.Nil || e.RefundOf.Valid {
+			continue
+		}
+
+		batch.Queue(matchRefund, e.ID, refundMatchDays, ctxutil.GetUser(ctx)).QueryRow(func(row pgx.Row) error {
+			return row.Scan(&e.RefundOf)
+		})
+	}
+
+	if batch.Len() == 0 {
+		return nil
+	}
+
+	// Queries run in order, so two identical refunds don't match the same purchase.
+	if err := tx.SendBatch(ctx, batch).Close(); err != nil {
+		return fmt.Errorf("failed to link refunds: %w", err)
+	}
+
+	return nil
+}
+
+// LinkRefund marks the user's credit as a refund of the purchase with the given ID. Unlike
+// automatic matching, the amounts don't have to match, so partial refunds can be linked.
+func LinkRefund(ctx context.Context, pool *pgxpool.Pool, refundID, originalID int) error {
+	if refundID == originalID {
+		return fmt.Errorf("an expenditure cannot refund itself: %w", errors.InvalidInputError{Input: refundID})
+	}
+
+	refund, err := GetExpenditure(ctx, pool, refundID)
+	if err != nil {
+		return err
+	}
+
+	if refund.Amount >= 0 {
+		return fmt.Errorf("refund must have a negative amount: %w", errors.InvalidInputError{Input: refundID})
+	}
+
+	original, err := GetExpenditure(ctx, pool, originalID)
+	if err != nil {
+		return err
+	}
+
+	if original.Amount <= 0 {
+		return fmt.Errorf("refunded purchase must have a positive amount: %w",
+			errors.InvalidInputError{Input: originalID})
+	}
+
+	return setRefundOf(ctx, pool, refundID, originalID)
+}
+
+// UnlinkRefund removes the link from the user's credit to the purchase it refunds.
+func UnlinkRefund(ctx context.Context, pool *pgxpool.Pool, refundID int) error {
+	return setRefundOf(ctx, pool, refundID, nil)
+}
+
+func setRefundOf(ctx context.Context, pool *pgxpool.Pool, refundID int, originalID any) error {
+	query, args, err := squirrel.Update("expenditure").
+		Set("refund_of", originalID).
+		Where(squirrel.Eq{
+			"id":    refundID,
+			"owner": ctxutil.GetUser(ctx),
+		}).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("failed to build query: %w", err)
+	}
+
+	tag, err := pool.Exec(ctx, query, args...)
+	if err != nil {
+		return fmt.Errorf("failed to update refund: %w", err)
+	}
+
+	if tag.RowsAffected() == 0 {
+		return errors.NoSuchElementError{Element: refundID}
+	}
+
+	return nil
+}
@@ -0,0 +1,104 @@
+package database_test
+
+import (
+	"testing"
+	"time"
+	"yaba/internal/ctxutil"
+	"yaba/internal/database"
+	"yaba/internal/model"
+	"yaba/internal/test/helper"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRefunds(t *testing.T) {
+	t.Parallel()
+
+	pool := helper.GetTestPool()
+	owner := uuid.New()
+	ctx := ctxutil.WithUser(t.Context(), owner)
+
+	budget := model.NewBudget(owner, "refund budget")
+	budget.SetBasicExpense("Electronics", model.MoneyFromFloat(500))
+	require.NoError(t, database.PersistBudget(ctx, pool, budget))
+
+	electronics := budget.Expenses[0].ID
+	june := time.Date(2024, 6, 10, 0, 0, 0, 0, time.UTC)
+	july := time.Date(2024, 7, 5, 0, 0, 0, 0, time.UTC)
+
+	purchases, err := database.ImportExpenditures(ctx, pool, []*model.Expenditure{
+		{Owner: owner, Name: "BEST BUY #123", Amount: model.MoneyFromFloat(100), Date: june, BudgetCategory: "Electronics"},
+		{Owner: owner, Name: "BEST BUY #123", Amount: model.MoneyFromFloat(140), Date: june, BudgetCategory: "Electronics"},
+		{Owner: owner, Name: "STAPLES", Amount: model.MoneyFromFloat(25), Date: june},
+	})
+	require.NoError(t, err)
+
+	bestBuy, cable, staples := purchases.Inserted[0], purchases.Inserted[1], purchases.Inserted[2]
+
+	credits, err := database.ImportExpenditures(ctx, pool, []*model.Expenditure{
+		{Owner: owner, Name: "BEST BUY #456", Amount: model.MoneyFromFloat(-100), Date: july},
+		// Only one purchase of this amount to refund
+		{Owner: owner, Name: "BEST BUY #789", Amount: model.MoneyFromFloat(-100), Date: july.AddDate(0, 0, 1)},
+		// Different merchant
+		{Owner: owner, Name: "AMAZON", Amount: model.MoneyFromFloat(-25), Date: july},
+	})
+	require.NoError(t, err)
+	require.Len(t, credits.Inserted, 3)
+
+	refund, second, amazon := credits.Inserted[0], credits.Inserted[1], credits.Inserted[2]
+	require.Equal(t, int64(bestBuy.ID), refund.RefundOf.Int64)
+	require.False(t, second.RefundOf.Valid)
+	require.False(t, amazon.RefundOf.Valid)
+
+	stored, err := database.GetExpenditure(ctx, pool, refund.ID)
+	require.NoError(t, err)
+	require.Equal(t, refund.RefundOf, stored.RefundOf)
+
+	// The second credit is a partial refund of the more expensive purchase
+	require.Error(t, database.LinkRefund(ctx, pool, second.ID, second.ID))
+	require.Error(t, database.LinkRefund(ctx, pool, cable.ID, bestBuy.ID))
+	require.Error(t, database.LinkRefund(ctx, pool, second.ID, amazon.ID))
+	require.ErrorContains(t,
+		database.LinkRefund(ctxutil.WithUser(t.Context(), uuid.New()), pool, second.ID, cable.ID), "no such element")
+	require.NoError(t, database.LinkRefund(ctx, pool, second.ID, cable.ID))
+	require.NoError(t, database.LinkRefund(ctx, pool, amazon.ID, staples.ID))
+	require.NoError(t, database.UnlinkRefund(ctx, pool, amazon.ID))
+
+	aggregate := func(refunds model.RefundAttribution) map[string]float64 {
+		summaries, err := database.AggregateExpenditures(ctx, pool, june, july.AddDate(0, 1, 0),
+			model.TimespanMonth, model.AggregationSum, model.GroupByBudgetCategory, refunds, false)
+		require.NoError(t, err)
+
+		totals := make(map[string]float64)
+		for _, summary := range summaries {
+			totals[summary.StartDate.Format("2006-01")+" "+summary.Category] = summary.Amount.Float64()
+		}
+
+		return totals
+	}
+
+	require.Equal(t, map[string]float64{
+		"2024-06 " + electronics.String(): 40,
+		"2024-06 " + uuid.Nil.String():    25,
+		"2024-07 " + uuid.Nil.String():    -25,
+	}, aggregate(model.RefundAttributionOriginal))
+
+	require.Equal(t, map[string]float64{
+		"2024-06 " + electronics.String(): 240,
+		"2024-06 " + uuid.Nil.String():    25,
+		"2024-07 " + electronics.String(): -200,
+		"2024-07 " + uuid.Nil.String():    -25,
+	}, aggregate(model.RefundAttributionRefundDate))
+
+	// Refunds of split purchases are divided like the purchase
+	require.NoError(t, database.SetExpenditureSplits(ctx, pool, bestBuy.ID, []*model.ExpenditureSplit{
+		{BudgetCategory: "Electronics", Amount: model.MoneyFromFloat(75)},
+		{BudgetCategory: "Warranty", Amount: model.MoneyFromFloat(25)},
+	}))
+	require.Equal(t, map[string]float64{
+		"2024-06 " + electronics.String(): 40,
+		"2024-06 " + uuid.Nil.String():    25,
+		"2024-07 " + uuid.Nil.String():    -25,
+	}, aggregate(model.RefundAttributionOriginal))
+}
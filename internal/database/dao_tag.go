@@ -0,0 +1,284 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"yaba/errors"
+	"yaba/internal/ctxutil"
+	"yaba/internal/model"
+
+	"github.com/Masterminds/squirrel"
+	"github.com/georgysavva/scany/v2/pgxscan"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// upsertTag returns the user's tag with the name, creating it if needed. An existing tag keeps the
+// case of its name.
+const upsertTag = `INSERT INTO tag (owner, name) VALUES ($1, $2)
+	ON CONFLICT (owner, name) DO UPDATE SET name = tag.name
+	RETURNING *`
+
+// tagExpenditures links each of the user's expenditures to each tag. Links that already exist are
+// not counted.
+const tagExpenditures = `INSERT INTO expenditure_tag (expenditure_id, tag_id)
+	SELECT e.id, t.id FROM expenditure e CROSS JOIN tag t
+	WHERE e.owner = $1 AND e.id = ANY($2) AND e.deleted_at IS NULL AND t.owner = $1 AND t.id = ANY($3)
+	ON CONFLICT DO NOTHING`
+
+const untagExpenditures = `DELETE FROM expenditure_tag et USING tag t
+	WHERE t.id = et.tag_id AND t.owner = $1 AND et.expenditure_id = ANY($2) AND t.name = ANY($3::CITEXT[])`
+
+// tagNames trims the names and removes names that only differ by case.
+func tagNames(names []string) ([]string, error) {
+	seen := make(map[string]bool, len(names))
+	ret := make([]string, 0, len(names))
+
+	for _, name := range names {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			return nil, fmt.Errorf("tag name cannot be empty: %w", errors.InvalidInputError{Input: names})
+		}
+
+		if key := strings.ToLower(name); !seen[key] {
+			seen[key] = true
+
+			ret = append(ret, name)
+		}
+	}
+
+	return ret, nil
+}
+
+// ListTags returns the user's tags, ordered by name.
+func ListTags(ctx context.Context, pool *pgxpool.Pool) ([]*model.Tag, error) {
+	query, args, err := squirrel.Select("*").
+		From("tag").
+		Where(squirrel.Eq{"owner": ctxutil.GetUser(ctx)}).
+		OrderBy("name").
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("failed to build query: %w", err)
+	}
+
+	var tags []*model.Tag
+	if err = pgxscan.Select(ctx, pool, &tags, query, args...); err != nil {
+		return nil, fmt.Errorf("failed to list tags: %w", err)
+	}
+
+	return tags, nil
+}
+
+// ListExpenditureTags returns the tags of the user's expenditure, ordered by name.
+func ListExpenditureTags(ctx context.Context, pool *pgxpool.Pool, expenditureID int) ([]*model.Tag, error) {
+	tags, err := ListTagsByExpenditure(ctx, pool, []int{expenditureID})
+	if err != nil {
+		return nil, err
+	}
+
+	return tags[expenditureID], nil
+}
+
+type expenditureTag struct {
+	ExpenditureID int `db:"expenditure_id"`
+	model.Tag
+}
+
+// ListTagsByExpenditure returns the tags of several of the user's expenditures at once, mapped by
+// expenditure ID and ordered by name. Expenditures without tags are left out.
+func ListTagsByExpenditure(
+	ctx context.Context,
+	pool *pgxpool.Pool,
+	expenditureIDs []int,
+) (map[int][]*model.Tag, error) {
+	query, args, err := squirrel.Select("et.expenditure_id", "t.*").
+		From("tag t").
+		Join("expenditure_tag et ON et.tag_id = t.id").
+		Where("t.owner = ? AND et.expenditure_id = ANY(?)", ctxutil.GetUser(ctx), expenditureIDs).
+		OrderBy("t.name").
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("failed to build query: %w", err)
+	}
+
+	var tags []*expenditureTag
+	if err = pgxscan.Select(ctx, pool, &tags, query, args...); err != nil {
+		return nil, fmt.Errorf("failed to list expenditure tags: %w", err)
+	}
+
+	byExpenditure := make(map[int][]*model.Tag)
+	for _, tag := range tags {
+		byExpenditure[tag.ExpenditureID] = append(byExpenditure[tag.ExpenditureID], &tag.Tag)
+	}
+
+	return byExpenditure, nil
+}
+
+// listTagNames maps the IDs of the expenditures to the names of their tags, ordered by name.
+func listTagNames(ctx context.Context, tx pgx.Tx, expenditures []*model.Expenditure) (map[int][]string, error) {
+	ids := make([]int, len(expenditures))
+	for i, e := range expenditures {
+		ids[i] = e.ID
+	}
+
+	query, args, err := squirrel.Select("et.expenditure_id", "t.name").
+		From("expenditure_tag et").
+		Join("tag t ON t.id = et.tag_id").
+		Where(squirrel.Eq{"et.expenditure_id": ids, "t.owner": ctxutil.GetUser(ctx)}).
+		OrderBy("t.name").
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("failed to build query: %w", err)
+	}
+
+	var rows []*struct {
+		ExpenditureID int    `db:"expenditure_id"`
+		Name          string `db:"name"`
+	}
+	if err = pgxscan.Select(ctx, tx, &rows, query, args...); err != nil {
+		return nil, fmt.Errorf("failed to list expenditure tags: %w", err)
+	}
+
+	names := make(map[int][]string)
+	for _, row := range rows {
+		names[row.ExpenditureID] = append(names[row.ExpenditureID], row.Name)
+	}
+
+	return names, nil
+}
+
+// CreateTag creates a tag for the user, or returns the existing tag with the same name.
+func CreateTag(ctx context.Context, pool *pgxpool.Pool, name string) (*model.Tag, error) {
+	names, err := tagNames([]string{name})
+	if err != nil {
+		return nil, err
+	}
+
+	var tag model.Tag
+	if err = pgxscan.Get(ctx, pool, &tag, upsertTag, ctxutil.GetUser(ctx), names[0]); err != nil {
+		return nil, fmt.Errorf("failed to create tag: %w", err)
+	}
+
+	return &tag, nil
+}
+
+// RenameTag renames the user's tag. The new name can't be the name of another tag.
+func RenameTag(ctx context.Context, pool *pgxpool.Pool, id uuid.UUID, name string) (*model.Tag, error) {
+	names, err := tagNames([]string{name})
+	if err != nil {
+		return nil, err
+	}
+
+	query, args, err := squirrel.Update("tag").
+		Set("name", names[0]).
+		Where(squirrel.Eq{"id": id, "owner": ctxutil.GetUser(ctx)}).
+		Suffix("RETURNING *").
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("failed to build query: %w", err)
+	}
+
+	var tags []*model.Tag
+	if err = pgxscan.Select(ctx, pool, &tags, query, args...); err != nil {
+		return nil, fmt.Errorf("failed to rename tag: %w", err)
+	}
+
+	if len(tags) == 0 {
+		return nil, errors.NoSuchElementError{Element: id}
+	}
+
+	return tags[0], nil
+}
+
+// DeleteTag deletes the user's tag and removes it from all expenditures.
+func DeleteTag(ctx context.Context, pool *pgxpool.Pool, id uuid.UUID) error {
+	query, args, err := squirrel.Delete("tag").
+		Where(squirrel.Eq{"id": id, "owner": ctxutil.GetUser(ctx)}).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("failed to build query: %w", err)
+	}
+
+	tag, err := pool.Exec(ctx, query, args...)
+	if err != nil {
+		return fmt.Errorf("failed to delete tag: %w", err)
+	}
+
+	if tag.RowsAffected() == 0 {
+		return errors.NoSuchElementError{Element: id}
+	}
+
+	return nil
+}
+
+// TagExpenditures adds the named tags to the user's expenditures, creating tags that don't exist
+// yet. It returns the number of tags added. IDs belonging to other users are ignored.
+func TagExpenditures(ctx context.Context, pool *pgxpool.Pool, ids []int, names []string) (int64, error) {
+	names, err := tagNames(names)
+	if err != nil {
+		return 0, err
+	}
+
+	if len(ids) == 0 || len(names) == 0 {
+		return 0, nil
+	}
+
+	tx, err := pool.Begin(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("could not start transaction: %w", err)
+	}
+
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	added, err := linkTags(ctx, tx, ids, names)
+	if err != nil {
+		return 0, err
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return 0, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return added, nil
+}
+
+// linkTags creates any of the named tags that the user doesn't have and adds them to the user's
+// expenditures. The names must already be cleaned by tagNames.
+func linkTags(ctx context.Context, tx pgx.Tx, ids []int, names []string) (int64, error) {
+	user := ctxutil.GetUser(ctx)
+	tagIDs := make([]uuid.UUID, len(names))
+
+	for i, name := range names {
+		var tag model.Tag
+		if err := pgxscan.Get(ctx, tx, &tag, upsertTag, user, name); err != nil {
+			return 0, fmt.Errorf("failed to create tag: %w", err)
+		}
+
+		tagIDs[i] = tag.ID
+	}
+
+	result, err := tx.Exec(ctx, tagExpenditures, user, ids, tagIDs)
+	if err != nil {
+		return 0, fmt.Errorf("failed to tag expenditures: %w", err)
+	}
+
+	return result.RowsAffected(), nil
+}
+
+// UntagExpenditures removes the named tags from the user's expenditures and returns the number of
+// tags removed. The tags themselves are kept.
+func UntagExpenditures(ctx context.Context, pool *pgxpool.Pool, ids []int, names []string) (int64, error) {
+	names, err := tagNames(names)
+	if err != nil {
+		return 0, err
+	}
+
+	result, err := pool.Exec(ctx, untagExpenditures, ctxutil.GetUser(ctx), ids, names)
+	if err != nil {
+		return 0, fmt.Errorf("failed to untag expenditures: %w", err)
+	}
+
+	return result.RowsAffected(), nil
+}
@@ -0,0 +1,142 @@
+package database_test
+
+import (
+	"testing"
+	"time"
+	"yaba/internal/ctxutil"
+	"yaba/internal/database"
+	"yaba/internal/model"
+	"yaba/internal/test/helper"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTags(t *testing.T) {
+	t.Parallel()
+
+	pool := helper.GetTestPool()
+	owner := uuid.New()
+	ctx := ctxutil.WithUser(t.Context(), owner)
+	date := time.Date(2024, 10, 1, 0, 0, 0, 0, time.UTC)
+
+	budget := model.NewBudget(owner, "tag budget")
+	budget.SetBasicExpense("Travel", model.MoneyFromFloat(1000))
+	budget.SetBasicExpense("Food", model.MoneyFromFloat(500))
+	require.NoError(t, database.PersistBudget(ctx, pool, budget))
+
+	result, err := database.ImportExpenditures(ctx, pool, []*model.Expenditure{
+		{Owner: owner, Name: "FLIGHT", Amount: model.MoneyFromFloat(900), Date: date, BudgetCategory: "Travel"},
+		{Owner: owner, Name: "RAMEN", Amount: model.MoneyFromFloat(15), Date: date.AddDate(0, 0, 3),
+			BudgetCategory: "Food", Method: uuid.New()},
+		{Owner: owner, Name: "GROCER", Amount: model.MoneyFromFloat(60), Date: date, BudgetCategory: "Food"},
+		{Owner: owner, Name: "FLIGHT REFUND", Amount: model.MoneyFromFloat(-900), Date: date.AddDate(0, 0, 5)},
+	})
+	require.NoError(t, err)
+
+	flight, ramen, grocer := result.Inserted[0], result.Inserted[1], result.Inserted[2]
+	require.NoError(t, database.LinkRefund(ctx, pool, result.Inserted[3].ID, flight.ID))
+
+	trip, err := database.CreateTag(ctx, pool, " trip-japan ")
+	require.NoError(t, err)
+	require.Equal(t, "trip-japan", trip.Name)
+
+	// Names are unique ignoring case
+	same, err := database.CreateTag(ctx, pool, "Trip-Japan")
+	require.NoError(t, err)
+	require.Equal(t, trip.ID, same.ID)
+	require.Equal(t, "trip-japan", same.Name)
+
+	_, err = database.CreateTag(ctx, pool, " ")
+	require.Error(t, err)
+
+	added, err := database.TagExpenditures(ctx, pool, []int{flight.ID, ramen.ID},
+		[]string{"TRIP-JAPAN", "reimbursable"})
+	require.NoError(t, err)
+	require.Equal(t, int64(4), added)
+
+	// Tagging again adds nothing, and other users' expenditures are ignored
+	added, err = database.TagExpenditures(ctx, pool, []int{flight.ID}, []string{"trip-japan"})
+	require.NoError(t, err)
+	require.Zero(t, added)
+
+	otherCtx := ctxutil.WithUser(t.Context(), uuid.New())
+	added, err = database.TagExpenditures(otherCtx, pool, []int{grocer.ID}, []string{"trip-japan"})
+	require.NoError(t, err)
+	require.Zero(t, added)
+
+	tags, err := database.ListTags(ctx, pool)
+	require.NoError(t, err)
+	require.Len(t, tags, 2)
+	require.Equal(t, "reimbursable", tags[0].Name)
+
+	reimbursable := tags[0]
+
+	tags, err = database.ListExpenditureTags(ctx, pool, ramen.ID)
+	require.NoError(t, err)
+	require.Len(t, tags, 2)
+
+	byExpenditure, err := database.ListTagsByExpenditure(ctx, pool, []int{flight.ID, ramen.ID, grocer.ID})
+	require.NoError(t, err)
+	require.Len(t, byExpenditure, 2)
+	require.Equal(t, tags, byExpenditure[ramen.ID])
+	require.Equal(t, tags, byExpenditure[flight.ID])
+
+	tags, err = database.ListExpenditureTags(otherCtx, pool, ramen.ID)
+	require.NoError(t, err)
+	require.Empty(t, tags)
+
+	list := func(tags ...string) []int {
+		expenditures, err := database.ListExpenditures(ctx, pool, nil, nil, nil, nil, tags,
+			date, date.AddDate(0, 1, 0), nil, nil)
+		require.NoError(t, err)
+
+		ids := make([]int, len(expenditures))
+		for i, e := range expenditures {
+			ids[i] = e.ID
+		}
+
+		return ids
+	}
+
+	require.Equal(t, []int{ramen.ID, flight.ID}, list("trip-japan"))
+	require.Len(t, list(), 4)
+
+	removed, err := database.UntagExpenditures(ctx, pool, []int{flight.ID}, []string{"Reimbursable"})
+	require.NoError(t, err)
+	require.Equal(t, int64(1), removed)
+	require.Equal(t, []int{ramen.ID}, list("trip-japan", "reimbursable"))
+
+	// The trip is totalled across categories and payment methods, and the refund counts towards it
+	aggregate := func() map[string]float64 {
+		summaries, err := database.AggregateExpenditures(ctx, pool, date, date.AddDate(0, 1, 0),
+			model.TimespanMonth, model.AggregationSum, model.GroupByTag, model.RefundAttributionRefundDate, false)
+		require.NoError(t, err)
+
+		totals := make(map[string]float64)
+		for _, summary := range summaries {
+			totals[summary.Category] = summary.Amount.Float64()
+		}
+
+		return totals
+	}
+
+	require.Equal(t, map[string]float64{
+		trip.ID.String():         15,
+		reimbursable.ID.String(): 15,
+		uuid.Nil.String():        60,
+	}, aggregate())
+
+	renamed, err := database.RenameTag(ctx, pool, trip.ID, "trip-japan-2026")
+	require.NoError(t, err)
+	require.Equal(t, "trip-japan-2026", renamed.Name)
+
+	_, err = database.RenameTag(ctx, pool, trip.ID, "REIMBURSABLE")
+	require.Error(t, err)
+	_, err = database.RenameTag(otherCtx, pool, trip.ID, "mine")
+	require.ErrorContains(t, err, "no such element")
+
+	require.ErrorContains(t, database.DeleteTag(otherCtx, pool, trip.ID), "no such element")
+	require.NoError(t, database.DeleteTag(ctx, pool, trip.ID))
+	require.Empty(t, list("trip-japan-2026"))
+}
@@ -0,0 +1,135 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"yaba/errors"
+	"yaba/internal/ctxutil"
+	"yaba/internal/model"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// transferMatchDays is how far apart the two sides of a transfer can be posted.
+const transferMatchDays = 5
+
+// matchTransfer finds the closest unpaired expenditure for the opposite amount on another payment
+// method, e.g. the credit card payment for a withdrawal from chequing.
+const matchTransfer = `SELECT o.id
+FROM expenditure o
+WHERE o.owner = $1
+  AND o.amount = -$2::NUMERIC
+  AND o.method != $3
+  AND o.method != uuid_nil()
+  AND o.date BETWEEN $4::DATE - $5::INT AND $4::DATE + $5::INT
+  AND o.transfer_id IS NULL
+  AND o.refund_of IS NULL
+  AND o.id != $6
+  AND o.deleted_at IS NULL
+ORDER BY ABS(o.date - $4::DATE), o.id
+LIMIT 1`
+
+const pairTransfer = `UPDATE expenditure
+SET kind        = 'TRANSFER',
+    transfer_id = CASE WHEN id = $2::INT THEN $3::INT ELSE $2::INT END
+WHERE owner = $1
+  AND id IN ($2, $3)`
+
+const unpairTransfer = `UPDATE expenditure
+SET transfer_id = NULL
+WHERE owner = $1
+  AND (id = $2 OR transfer_id = $2)`
+
+// pairTransfers pairs each newly saved transfer with the other side of it, which is marked as a
+// transfer too. Transfers without a payment method are left unpaired.
+func pairTransfers(ctx context.Context, tx pgx.Tx, expenditures []*model.Expenditure) error {
+	user := ctxutil.GetUser(ctx)
+	byID := make(map[int]*model.Expenditure, len(expenditures))
+
+	for _, e := range expenditures {
+		byID[e.ID] = e
+	}
+
+	for _, e := range expenditures {
+		if e.Kind != model.KindTransfer || e.Method == uuid.Nil || e.TransferID.Valid {
+			continue
+		}
+
+		rows, err := tx.Query(ctx, matchTransfer, user, e.Amount, e.Method, e.Date, transferMatchDays, e.ID)
+		if err != nil {
+			return fmt.Errorf("failed to match transfer: %w", err)
+		}
+
+		ids, err := pgx.CollectRows(rows, pgx.RowTo[int])
+		if err != nil {
+			return fmt.Errorf("failed to match transfer: %w", err)
+		}
+
+		if len(ids) == 0 {
+			continue
+		}
+
+		if _, err = tx.Exec(ctx, pairTransfer, user, e.ID, ids[0]); err != nil {
+			return fmt.Errorf("failed to pair transfer: %w", err)
+		}
+
+		e.TransferID.Int64, e.TransferID.Valid = int64(ids[0]), true
+
+		if other, ok := byID[ids[0]]; ok {
+			other.Kind = model.KindTransfer
+			other.TransferID.Int64, other.TransferID.Valid = int64(e.ID), true
+		}
+	}
+
+	return nil
+}
+
+// PairTransfer marks two of the user's expenditures as the two sides of a transfer between their
+// payment methods. Their amounts must cancel out.
+func PairTransfer(ctx context.Context, pool *pgxpool.Pool, id, otherID int) error {
+	if id == otherID {
+		return fmt.Errorf("an expenditure cannot be paired with itself: %w", errors.InvalidInputError{Input: id})
+	}
+
+	expenditure, err := GetExpenditure(ctx, pool, id)
+	if err != nil {
+		return err
+	}
+
+	other, err := GetExpenditure(ctx, pool, otherID)
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case expenditure.TransferID.Valid || other.TransferID.Valid:
+		return fmt.Errorf("expenditure is already paired: %w", errors.InvalidInputError{Input: []int{id, otherID}})
+	case expenditure.Method == uuid.Nil || other.Method == uuid.Nil || expenditure.Method == other.Method:
+		return fmt.Errorf("transfers must be between two payment methods: %w",
+			errors.InvalidInputError{Input: []int{id, otherID}})
+	case expenditure.Amount.Round() != -other.Amount.Round():
+		return fmt.Errorf("transfer amounts must cancel out: %w", errors.InvalidInputError{Input: []int{id, otherID}})
+	}
+
+	if _, err = pool.Exec(ctx, pairTransfer, ctxutil.GetUser(ctx), id, otherID); err != nil {
+		return fmt.Errorf("failed to pair transfer: %w", err)
+	}
+
+	return nil
+}
+
+// UnpairTransfer removes the link between the two sides of a transfer. Both stay transfers.
+func UnpairTransfer(ctx context.Context, pool *pgxpool.Pool, id int) error {
+	tag, err := pool.Exec(ctx, unpairTransfer, ctxutil.GetUser(ctx), id)
+	if err != nil {
+		return fmt.Errorf("failed to unpair transfer: %w", err)
+	}
+
+	if tag.RowsAffected() == 0 {
+		return errors.NoSuchElementError{Element: id}
+	}
+
+	return nil
+}
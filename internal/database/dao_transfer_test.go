@@ -0,0 +1,84 @@
+package database_test
+
+import (
+	"testing"
+	"time"
+	"yaba/internal/ctxutil"
+	"yaba/internal/database"
+	"yaba/internal/model"
+	"yaba/internal/test/helper"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTransfers(t *testing.T) {
+	t.Parallel()
+
+	pool := helper.GetTestPool()
+	owner := uuid.New()
+	ctx := ctxutil.WithUser(t.Context(), owner)
+	chequing, card := uuid.New(), uuid.New()
+	date := time.Date(2024, 8, 1, 0, 0, 0, 0, time.UTC)
+
+	statement, err := database.ImportExpenditures(ctx, pool, []*model.Expenditure{
+		{Owner: owner, Name: "GROCER", Amount: model.MoneyFromFloat(80), Date: date, Method: card},
+		{Owner: owner, Name: "PAYMENT - THANK YOU", Amount: model.MoneyFromFloat(-500), Date: date.AddDate(0, 0, 3),
+			Method: card},
+		{Owner: owner, Name: "PAYMENT - THANK YOU", Amount: model.MoneyFromFloat(-60), Date: date.AddDate(0, 0, 10),
+			Method: card},
+	})
+	require.NoError(t, err)
+	require.Equal(t, model.KindExpense, statement.Inserted[0].Kind)
+
+	cardPayment, unmatched := statement.Inserted[1], statement.Inserted[2]
+
+	chequingStatement, err := database.ImportExpenditures(ctx, pool, []*model.Expenditure{
+		{Owner: owner, Name: "VISA PAYMENT", Amount: model.MoneyFromFloat(500), Date: date.AddDate(0, 0, 1),
+			Method: chequing, Kind: model.KindTransfer},
+		{Owner: owner, Name: "SAVINGS", Amount: model.MoneyFromFloat(60), Date: date.AddDate(0, 0, 1), Method: chequing,
+			Kind: model.KindTransfer},
+		{Owner: owner, Name: "PAYROLL", Amount: model.MoneyFromFloat(-2000), Date: date, Method: chequing,
+			Kind: model.KindIncome},
+	})
+	require.NoError(t, err)
+
+	withdrawal, savings := chequingStatement.Inserted[0], chequingStatement.Inserted[1]
+	require.Equal(t, int64(cardPayment.ID), withdrawal.TransferID.Int64)
+	// Too far apart
+	require.False(t, savings.TransferID.Valid)
+
+	stored, err := database.GetExpenditure(ctx, pool, cardPayment.ID)
+	require.NoError(t, err)
+	require.Equal(t, model.KindTransfer, stored.Kind)
+	require.Equal(t, int64(withdrawal.ID), stored.TransferID.Int64)
+
+	aggregate := func(includeTransfers bool) float64 {
+		summaries, err := database.AggregateExpenditures(ctx, pool, date, date.AddDate(0, 1, 0),
+			model.TimespanMonth, model.AggregationSum, model.GroupByNone, model.RefundAttributionOriginal,
+			includeTransfers)
+		require.NoError(t, err)
+		require.Len(t, summaries, 1)
+
+		return summaries[0].Amount.Float64()
+	}
+
+	// The unpaired card payment is still spending until it's marked as a transfer
+	require.InDelta(t, 80-60-2000, aggregate(false), 0.001)
+	require.InDelta(t, 80-500-60+500+60-2000, aggregate(true), 0.001)
+
+	require.Error(t, database.PairTransfer(ctx, pool, unmatched.ID, cardPayment.ID))
+	require.Error(t, database.PairTransfer(ctx, pool, unmatched.ID, statement.Inserted[0].ID))
+	require.NoError(t, database.UnpairTransfer(ctx, pool, savings.ID))
+	require.NoError(t, database.PairTransfer(ctx, pool, unmatched.ID, savings.ID))
+	require.InDelta(t, 80-2000, aggregate(false), 0.001)
+
+	// Editing a transfer into an expense unpairs both sides
+	withdrawal.Kind = model.KindExpense
+	require.NoError(t, database.UpdateExpenditure(ctx, pool, withdrawal))
+	stored, err = database.GetExpenditure(ctx, pool, cardPayment.ID)
+	require.NoError(t, err)
+	require.Equal(t, model.KindTransfer, stored.Kind)
+	require.False(t, stored.TransferID.Valid)
+	require.InDelta(t, 80+500-2000, aggregate(false), 0.001)
+}
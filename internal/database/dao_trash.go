@@ -0,0 +1,171 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+	yabaerrors "yaba/errors"
+	"yaba/internal/ctxutil"
+	"yaba/internal/model"
+
+	"github.com/georgysavva/scany/v2/pgxscan"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+const listTrash = `
+SELECT id::TEXT, 'BUDGET' AS type, COALESCE(name, '') AS name, deleted_at
+FROM budget
+WHERE owner = $1 AND deleted_at IS NOT NULL
+UNION ALL
+SELECT id::TEXT, 'PAYMENT_METHOD', COALESCE(display_name, ''), deleted_at
+FROM payment_method
+WHERE owner = $1 AND deleted_at IS NOT NULL
+UNION ALL
+SELECT id::TEXT, 'EXPENDITURE', COALESCE(name, ''), deleted_at
+FROM expenditure
+WHERE owner = $1 AND deleted_at IS NOT NULL
+ORDER BY deleted_at DESC, type, id
+`
+
+const restoreExpenditure = `
+UPDATE expenditure
+SET deleted_at = NULL
+WHERE id = $1 AND owner = $2 AND deleted_at IS NOT NULL
+`
+
+const restoreBudget = `
+UPDATE budget
+SET deleted_at = NULL
+WHERE id = $1 AND owner = $2 AND deleted_at IS NOT NULL
+`
+
+const restorePaymentMethod = `
+UPDATE payment_method
+SET deleted_at = NULL
+WHERE id = $1 AND owner = $2 AND deleted_at IS NOT NULL
+`
+
+// listPurgedAttachments lists the attachments of the expenditures that PurgeTrash deletes, whose
+// files are left to the caller.
+const listPurgedAttachments = `
+SELECT a.*
+FROM attachment a
+JOIN expenditure e ON e.id = a.expenditure_id
+WHERE e.deleted_at < $1
+`
+
+// purgeBudgetParts deletes the incomes and expenses of the budgets that purgeTrash deletes. They go
+// first, so that the audit log can still find their owner.
+//
+//nolint:gochecknoglobals
+var purgeBudgetParts = []string{
+	`DELETE FROM income WHERE owner IN (SELECT id FROM budget WHERE deleted_at < $1)`,
+	`DELETE FROM expense WHERE budget_id IN (SELECT id FROM budget WHERE deleted_at < $1)`,
+}
+
+// purgeTrash deletes what was trashed before $1 for all users.
+//
+//nolint:gochecknoglobals
+var purgeTrash = []string{
+	`DELETE FROM budget WHERE deleted_at < $1`,
+	`DELETE FROM payment_method WHERE deleted_at < $1`,
+	`DELETE FROM expenditure WHERE deleted_at < $1`,
+}
+
+// ListTrash returns the user's deleted budgets, payment methods and expenditures, most recently
+// deleted first.
+func ListTrash(ctx context.Context, pool *pgxpool.Pool) ([]*model.TrashItem, error) {
+	var items []*model.TrashItem
+	if err := pgxscan.Select(ctx, pool, &items, listTrash, ctxutil.GetUser(ctx)); err != nil {
+		return nil, fmt.Errorf("failed to list trash: %w", err)
+	}
+
+	return items, nil
+}
+
+// RestoreFromTrash takes the user's budget, payment method or expenditure with the ID out of the
+// trash. It returns false if there is none in the trash. A budget can't be restored while another
+// budget has its name.
+func RestoreFromTrash(ctx context.Context, pool *pgxpool.Pool, id string) (bool, error) {
+	user := ctxutil.GetUser(ctx)
+
+	// Expenditures have numeric IDs, and budgets and payment methods have UUIDs.
+	if expenditureID, err := strconv.Atoi(id); err == nil {
+		tag, err := pool.Exec(ctx, restoreExpenditure, expenditureID, user)
+		if err != nil {
+			return false, fmt.Errorf("failed to restore expenditure: %w", err)
+		}
+
+		return tag.RowsAffected() > 0, nil
+	}
+
+	uid, err := uuid.Parse(id)
+	if err != nil {
+		return false, fmt.Errorf("invalid ID: %w", yabaerrors.InvalidInputError{Input: id})
+	}
+
+	tag, err := pool.Exec(ctx, restoreBudget, uid, user)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+			return false, fmt.Errorf("failed to restore budget: %w",
+				yabaerrors.InvalidStateError{Message: "another budget has the same name"})
+		}
+
+		return false, fmt.Errorf("failed to restore budget: %w", err)
+	}
+
+	if tag.RowsAffected() > 0 {
+		return true, nil
+	}
+
+	if tag, err = pool.Exec(ctx, restorePaymentMethod, uid, user); err != nil {
+		return false, fmt.Errorf("failed to restore payment method: %w", err)
+	}
+
+	return tag.RowsAffected() > 0, nil
+}
+
+// PurgeTrash permanently deletes the budgets, payment methods and expenditures of all users that
+// were trashed before the time, and returns how many were deleted. It also returns the attachments
+// of the deleted expenditures, whose files must be removed from the blob store.
+func PurgeTrash(ctx context.Context, pool *pgxpool.Pool, before time.Time) (int64, []*model.Attachment, error) {
+	tx, err := pool.Begin(ctx)
+	if err != nil {
+		return 0, nil, fmt.Errorf("could not start transaction: %w", err)
+	}
+
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	var attachments []*model.Attachment
+	if err = pgxscan.Select(ctx, tx, &attachments, listPurgedAttachments, before); err != nil {
+		return 0, nil, fmt.Errorf("failed to list attachments: %w", err)
+	}
+
+	for _, query := range purgeBudgetParts {
+		if _, err = tx.Exec(ctx, query, before); err != nil {
+			return 0, nil, fmt.Errorf("failed to purge trash: %w", err)
+		}
+	}
+
+	var purged int64
+
+	for _, query := range purgeTrash {
+		tag, err := tx.Exec(ctx, query, before)
+		if err != nil {
+			return 0, nil, fmt.Errorf("failed to purge trash: %w", err)
+		}
+
+		purged += tag.RowsAffected()
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return 0, nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return purged, attachments, nil
+}
@@ -0,0 +1,198 @@
+package database_test
+
+import (
+	"strconv"
+	"testing"
+	"time"
+	"yaba/errors"
+	"yaba/internal/ctxutil"
+	"yaba/internal/database"
+	"yaba/internal/model"
+	"yaba/internal/test/helper"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTrash(t *testing.T) {
+	t.Parallel()
+
+	pool := helper.GetTestPool()
+	owner := uuid.New()
+	ctx := ctxutil.WithUser(t.Context(), owner)
+	today := time.Now().UTC().Truncate(24 * time.Hour)
+
+	budget := model.NewBudget(owner, "monthly")
+	budget.SetFixedExpense("food", model.MoneyFromFloat(500))
+	require.NoError(t, database.PersistBudget(ctx, pool, budget))
+
+	method := &model.PaymentMethod{ID: uuid.New(), DisplayName: "Visa"}
+	require.NoError(t, database.CreatePaymentMethod(ctx, pool, method))
+
+	expenditures := []*model.Expenditure{
+		{Owner: owner, Name: "Groceries", Amount: model.MoneyFromFloat(80), Date: today, Method: method.ID},
+		{Owner: owner, Name: "Coffee", Amount: model.MoneyFromFloat(5), Date: today, Method: method.ID},
+	}
+	require.NoError(t, database.PersistExpenditures(ctx, pool, expenditures))
+
+	trash, err := database.ListTrash(ctx, pool)
+	require.NoError(t, err)
+	require.Empty(t, trash)
+
+	require.NoError(t, database.DeleteBudget(ctx, pool, budget, nil))
+
+	deleted, err := database.DeletePaymentMethod(ctx, pool, method.ID)
+	require.NoError(t, err)
+	require.True(t, deleted)
+
+	count, err := database.DeleteExpenditures(ctx, pool, []int{expenditures[0].ID})
+	require.NoError(t, err)
+	require.Equal(t, int64(1), count)
+
+	// Trashed records are hidden from everything else.
+	_, err = database.GetBudget(ctx, pool, owner, budget.ID)
+	require.ErrorAs(t, err, &errors.NoSuchElementError{})
+
+	methods, err := database.ListPaymentMethods(ctx, pool)
+	require.NoError(t, err)
+	require.Empty(t, methods)
+
+	_, err = database.GetExpenditure(ctx, pool, expenditures[0].ID)
+	require.ErrorAs(t, err, &errors.NoSuchElementError{})
+
+	listed, err := database.ListExpenditures(ctx, pool, nil, nil, nil, nil, nil, today, today, nil, nil)
+	require.NoError(t, err)
+	require.Len(t, listed, 1)
+	require.Equal(t, "Coffee", listed[0].Name)
+
+	summaries, err := database.AggregateExpenditures(ctx, pool, today, today, model.TimespanDay, model.AggregationSum,
+		model.GroupByNone, model.RefundAttributionOriginal, false)
+	require.NoError(t, err)
+	require.Len(t, summaries, 1)
+	require.Equal(t, model.MoneyFromFloat(5), summaries[0].Amount)
+
+	trash, err = database.ListTrash(ctx, pool)
+	require.NoError(t, err)
+	require.Len(t, trash, 3)
+
+	names := map[model.TrashType]string{}
+	for _, item := range trash {
+		require.False(t, item.DeletedAt.IsZero())
+		names[item.Type] = item.Name
+	}
+
+	require.Equal(t, map[model.TrashType]string{
+		model.TrashTypeBudget:        "monthly",
+		model.TrashTypePaymentMethod: "Visa",
+		model.TrashTypeExpenditure:   "Groceries",
+	}, names)
+
+	// Deleting again doesn't move anything.
+	deleted, err = database.DeletePaymentMethod(ctx, pool, method.ID)
+	require.NoError(t, err)
+	require.False(t, deleted)
+
+	// Other users can't see or restore the trash.
+	other := ctxutil.WithUser(t.Context(), uuid.New())
+
+	trash, err = database.ListTrash(other, pool)
+	require.NoError(t, err)
+	require.Empty(t, trash)
+
+	restored, err := database.RestoreFromTrash(other, pool, budget.ID.String())
+	require.NoError(t, err)
+	require.False(t, restored)
+
+	for _, id := range []string{budget.ID.String(), method.ID.String(), strconv.Itoa(expenditures[0].ID)} {
+		restored, err = database.RestoreFromTrash(ctx, pool, id)
+		require.NoError(t, err)
+		require.True(t, restored, id)
+	}
+
+	fetched, err := database.GetBudget(ctx, pool, owner, budget.ID)
+	require.NoError(t, err)
+	require.Len(t, fetched.Expenses, 1)
+
+	_, err = database.GetPaymentMethod(ctx, pool, method.ID)
+	require.NoError(t, err)
+
+	_, err = database.GetExpenditure(ctx, pool, expenditures[0].ID)
+	require.NoError(t, err)
+
+	trash, err = database.ListTrash(ctx, pool)
+	require.NoError(t, err)
+	require.Empty(t, trash)
+
+	// Items that aren't in the trash can't be restored.
+	restored, err = database.RestoreFromTrash(ctx, pool, budget.ID.String())
+	require.NoError(t, err)
+	require.False(t, restored)
+
+	_, err = database.RestoreFromTrash(ctx, pool, "nope")
+	require.ErrorAs(t, err, &errors.InvalidInputError{})
+
+	// A budget can't be restored while another one has its name.
+	require.NoError(t, database.DeleteBudget(ctx, pool, budget, nil))
+	require.NoError(t, database.PersistBudget(ctx, pool, model.NewBudget(owner, "monthly")))
+
+	_, err = database.RestoreFromTrash(ctx, pool, budget.ID.String())
+	require.ErrorAs(t, err, &errors.InvalidStateError{})
+}
+
+func TestPurgeTrash(t *testing.T) {
+	t.Parallel()
+
+	pool := helper.GetTestPool()
+	owner := uuid.New()
+	ctx := ctxutil.WithUser(t.Context(), owner)
+
+	budget := model.NewBudget(owner, "monthly")
+	budget.SetFixedExpense("food", model.MoneyFromFloat(500))
+	require.NoError(t, database.PersistBudget(ctx, pool, budget))
+
+	expenditures := []*model.Expenditure{
+		{Owner: owner, Name: "Groceries", Amount: model.MoneyFromFloat(80), Date: time.Now()},
+		{Owner: owner, Name: "Coffee", Amount: model.MoneyFromFloat(5), Date: time.Now()},
+	}
+	require.NoError(t, database.PersistExpenditures(ctx, pool, expenditures))
+
+	attachment := &model.Attachment{
+		ID:            uuid.New(),
+		ExpenditureID: expenditures[0].ID,
+		Filename:      "receipt.pdf",
+		ContentType:   "application/pdf",
+		Size:          1,
+	}
+	require.NoError(t, database.CreateAttachment(ctx, pool, attachment))
+
+	require.NoError(t, database.DeleteBudget(ctx, pool, budget, nil))
+	_, err := database.DeleteExpenditures(ctx, pool, []int{expenditures[0].ID, expenditures[1].ID})
+	require.NoError(t, err)
+
+	// Other tests share the database, so only this test's trash is made old enough to purge.
+	longAgo := time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC)
+	for _, table := range []string{"budget", "expenditure"} {
+		_, err = pool.Exec(ctx, "UPDATE "+table+` SET deleted_at = $1
+			WHERE owner = $2 AND deleted_at IS NOT NULL AND id::TEXT != $3`, longAgo, owner, strconv.Itoa(expenditures[1].ID))
+		require.NoError(t, err)
+	}
+
+	purged, attachments, err := database.PurgeTrash(t.Context(), pool, longAgo.AddDate(0, 0, 1))
+	require.NoError(t, err)
+	require.Equal(t, int64(2), purged)
+	require.Len(t, attachments, 1)
+	require.Equal(t, attachment.ID, attachments[0].ID)
+
+	trash, err := database.ListTrash(ctx, pool)
+	require.NoError(t, err)
+	require.Len(t, trash, 1)
+	require.Equal(t, strconv.Itoa(expenditures[1].ID), trash[0].ID)
+
+	restored, err := database.RestoreFromTrash(ctx, pool, budget.ID.String())
+	require.NoError(t, err)
+	require.False(t, restored)
+
+	var expenses int
+	require.NoError(t, pool.QueryRow(ctx, "SELECT COUNT(*) FROM expense WHERE budget_id = $1", budget.ID).Scan(&expenses))
+	require.Zero(t, expenses)
+}
@@ -0,0 +1,77 @@
+package export
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"time"
+	"yaba/internal/model"
+)
+
+// csvHeader is the header that tools/mock.go writes, with the currency and kind of each expenditure,
+// which the CSV importer's default mapping also reads.
+var csvHeader = []string{ //nolint:gochecknoglobals
+	"date", "amount", "name", "method", "budget_category", "reward_category", "comment", "currency", "kind",
+}
+
+type csvWriter struct {
+	csv     *csv.Writer
+	options *Options
+	started bool
+}
+
+func newCSVWriter(w io.Writer, options *Options) *csvWriter {
+	return &csvWriter{csv: csv.NewWriter(w), options: options}
+}
+
+func (w *csvWriter) Write(expenditure *model.Expenditure) error {
+	if err := w.start(); err != nil {
+		return err
+	}
+
+	err := w.csv.Write([]string{
+		expenditure.Date.Format(time.DateOnly),
+		expenditure.Amount.String(),
+		expenditure.Name,
+		w.options.methodName(expenditure.Method),
+		expenditure.BudgetCategory,
+		expenditure.RewardCategory,
+		expenditure.Comment,
+		w.options.currency(expenditure),
+		string(expenditure.Kind),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to write csv: %w", err)
+	}
+
+	return nil
+}
+
+func (w *csvWriter) Close() error {
+	if err := w.start(); err != nil {
+		return err
+	}
+
+	w.csv.Flush()
+
+	if err := w.csv.Error(); err != nil {
+		return fmt.Errorf("failed to write csv: %w", err)
+	}
+
+	return nil
+}
+
+// start writes the header, which is written even if there are no expenditures.
+func (w *csvWriter) start() error {
+	if w.started {
+		return nil
+	}
+
+	w.started = true
+
+	if err := w.csv.Write(csvHeader); err != nil {
+		return fmt.Errorf("failed to write csv: %w", err)
+	}
+
+	return nil
+}
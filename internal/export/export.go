@@ -0,0 +1,138 @@
+// Package export writes expenditures as CSV, newline-delimited JSON or OFX for spreadsheets and tax
+// software. Expenditures are written one at a time, so exports of any size are streamed.
+package export
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"yaba/errors"
+	"yaba/internal/database"
+	"yaba/internal/model"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type Format string
+
+const (
+	// FormatCSV has the column layout of tools/mock.go plus currency and kind, which the CSV importer
+	// reads by default.
+	FormatCSV Format = "csv"
+	// FormatNDJSON has one JSON object per expenditure per line.
+	FormatNDJSON Format = "ndjson"
+	// FormatOFX has a bank or credit card statement for each payment method and currency.
+	FormatOFX Format = "ofx"
+)
+
+// ParseFormat returns the format with the name, ignoring case.
+func ParseFormat(name string) (Format, error) {
+	switch format := Format(strings.ToLower(name)); format {
+	case FormatCSV, FormatNDJSON, FormatOFX:
+		return format, nil
+	default:
+		return "", fmt.Errorf("unknown export format: %w", errors.InvalidInputError{Input: name})
+	}
+}
+
+// ContentType is the media type of files in the format.
+func (f Format) ContentType() string {
+	switch f {
+	case FormatNDJSON:
+		return "application/x-ndjson"
+	case FormatOFX:
+		return "application/x-ofx"
+	default:
+		return "text/csv; charset=utf-8"
+	}
+}
+
+// Writer writes expenditures in a format.
+type Writer interface {
+	Write(expenditure *model.Expenditure) error
+	// Close writes the end of the export. It doesn't close the underlying writer.
+	Close() error
+}
+
+// Options are what the writers need to know about the user besides their expenditures.
+type Options struct {
+	// Methods are the user's payment methods by ID.
+	Methods map[uuid.UUID]*model.PaymentMethod
+	// HomeCurrency is the currency of expenditures without one.
+	HomeCurrency string
+	// Filter is the filter that the expenditures were selected by. OFX statements cover its dates.
+	Filter *model.ExpenditureFilter
+}
+
+// NewWriter returns a writer of the format. OFX expenditures must be grouped by payment method and
+// currency, with those of bank accounts before those of credit cards.
+func NewWriter(format Format, w io.Writer, options *Options) (Writer, error) {
+	switch format {
+	case FormatCSV:
+		return newCSVWriter(w, options), nil
+	case FormatNDJSON:
+		return newNDJSONWriter(w, options), nil
+	case FormatOFX:
+		return newOFXWriter(w, options), nil
+	default:
+		return nil, fmt.Errorf("unknown export format: %w", errors.InvalidInputError{Input: format})
+	}
+}
+
+// Export writes the user's expenditures that match the filter to w in the format.
+func Export(
+	ctx context.Context,
+	pool *pgxpool.Pool,
+	w io.Writer,
+	format Format,
+	filter *model.ExpenditureFilter,
+) error {
+	methods, err := database.ListPaymentMethods(ctx, pool)
+	if err != nil {
+		return err
+	}
+
+	homeCurrency, err := database.GetHomeCurrency(ctx, pool)
+	if err != nil {
+		return err
+	}
+
+	options := &Options{
+		Methods:      make(map[uuid.UUID]*model.PaymentMethod, len(methods)),
+		HomeCurrency: homeCurrency,
+		Filter:       filter,
+	}
+
+	for _, method := range methods {
+		options.Methods[method.ID] = method
+	}
+
+	writer, err := NewWriter(format, w, options)
+	if err != nil {
+		return err
+	}
+
+	if err = database.StreamExpenditures(ctx, pool, filter, format == FormatOFX, writer.Write); err != nil {
+		return err
+	}
+
+	return writer.Close()
+}
+
+func (o *Options) methodName(id uuid.UUID) string {
+	if method, ok := o.Methods[id]; ok {
+		return method.DisplayName
+	}
+
+	return ""
+}
+
+func (o *Options) currency(expenditure *model.Expenditure) string {
+	if expenditure.Currency == "" {
+		return o.HomeCurrency
+	}
+
+	return expenditure.Currency
+}
@@ -0,0 +1,283 @@
+package export_test
+
+import (
+	"bufio"
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+	"yaba/errors"
+	"yaba/internal/export"
+	"yaba/internal/importer"
+	"yaba/internal/model"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+func testOptions() (*export.Options, uuid.UUID, uuid.UUID) {
+	visa := &model.PaymentMethod{ID: uuid.New(), DisplayName: "Visa", AccountID: "4510XXXXXXXX1234", CardType: uuid.New()}
+	cash := &model.PaymentMethod{ID: uuid.New(), DisplayName: "Cash"}
+
+	return &export.Options{
+		Methods:      map[uuid.UUID]*model.PaymentMethod{visa.ID: visa, cash.ID: cash},
+		HomeCurrency: "CAD",
+		Filter: &model.ExpenditureFilter{
+			Since: time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC),
+			Until: time.Date(2025, 3, 31, 0, 0, 0, 0, time.UTC),
+		},
+	}, visa.ID, cash.ID
+}
+
+func write(t *testing.T, format export.Format, options *export.Options, expenditures []*model.Expenditure) []byte {
+	t.Helper()
+
+	var buf bytes.Buffer
+
+	writer, err := export.NewWriter(format, &buf, options)
+	require.NoError(t, err)
+
+	for _, expenditure := range expenditures {
+		require.NoError(t, writer.Write(expenditure))
+	}
+
+	require.NoError(t, writer.Close())
+
+	return buf.Bytes()
+}
+
+func TestParseFormat(t *testing.T) {
+	t.Parallel()
+
+	format, err := export.ParseFormat("OFX")
+	require.NoError(t, err)
+	require.Equal(t, export.FormatOFX, format)
+
+	_, err = export.ParseFormat("xlsx")
+	require.ErrorAs(t, err, &errors.InvalidInputError{})
+}
+
+func TestCSV(t *testing.T) {
+	t.Parallel()
+
+	options, visa, _ := testOptions()
+
+	expenditures := []*model.Expenditure{
+		{
+			ID:             1,
+			Name:           "Coffee, \"large\"",
+			Amount:         model.MoneyFromFloat(4.5),
+			Date:           time.Date(2025, 3, 14, 0, 0, 0, 0, time.UTC),
+			Method:         visa,
+			BudgetCategory: "food",
+			RewardCategory: "dining",
+			Comment:        "with pastry",
+		},
+		{
+			ID:       2,
+			Name:     "Refund",
+			Amount:   model.MoneyFromFloat(-20),
+			Date:     time.Date(2025, 3, 15, 0, 0, 0, 0, time.UTC),
+			Kind:     model.KindIncome,
+			Currency: "USD",
+		},
+	}
+
+	// The default CSV mapping of the importer reads the export back.
+	records, err := importer.ParseCSV(bytes.NewReader(write(t, export.FormatCSV, options, expenditures)),
+		importer.DefaultCSVMapping())
+	require.NoError(t, err)
+	require.Equal(t, []*importer.Record{
+		{
+			Date:           time.Date(2025, 3, 14, 0, 0, 0, 0, time.UTC),
+			Amount:         model.MoneyFromFloat(4.5),
+			Name:           "Coffee, \"large\"",
+			Method:         "Visa",
+			BudgetCategory: "food",
+			RewardCategory: "dining",
+			Comment:        "with pastry",
+			Currency:       "CAD",
+		},
+		{
+			Date:     time.Date(2025, 3, 15, 0, 0, 0, 0, time.UTC),
+			Amount:   model.MoneyFromFloat(-20),
+			Name:     "Refund",
+			Currency: "USD",
+			Kind:     model.KindIncome,
+		},
+	}, records)
+
+	// Empty exports still have a header.
+	require.Equal(t, "date,amount,name,method,budget_category,reward_category,comment,currency,kind\n",
+		string(write(t, export.FormatCSV, options, nil)))
+}
+
+func TestNDJSON(t *testing.T) {
+	t.Parallel()
+
+	options, visa, _ := testOptions()
+
+	expenditures := []*model.Expenditure{
+		{
+			ID:             1,
+			Name:           "Coffee & pastry",
+			Amount:         model.MoneyFromFloat(4.5),
+			Date:           time.Date(2025, 3, 14, 0, 0, 0, 0, time.UTC),
+			Method:         visa,
+			BudgetCategory: "food",
+			Source:         "import",
+			Kind:           model.KindExpense,
+		},
+		{
+			ID:       2,
+			Name:     "Refund",
+			Amount:   model.MoneyFromFloat(-4.5),
+			Date:     time.Date(2025, 3, 15, 0, 0, 0, 0, time.UTC),
+			Kind:     model.KindExpense,
+			Currency: "USD",
+			RefundOf: sql.NullInt64{Int64: 1, Valid: true},
+		},
+	}
+
+	out := write(t, export.FormatNDJSON, options, expenditures)
+	require.Contains(t, string(out), `"name":"Coffee & pastry"`)
+
+	var lines []map[string]any
+
+	scanner := bufio.NewScanner(bytes.NewReader(out))
+	for scanner.Scan() {
+		var line map[string]any
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &line))
+
+		lines = append(lines, line)
+	}
+
+	require.NoError(t, scanner.Err())
+	require.Len(t, lines, 2)
+
+	require.Equal(t, "2025-03-14", lines[0]["date"])
+	require.Equal(t, "Visa", lines[0]["method"])
+	require.Equal(t, "CAD", lines[0]["currency"])
+	require.Equal(t, "EXPENSE", lines[0]["kind"])
+	require.NotContains(t, lines[0], "refund_of")
+
+	require.Equal(t, "USD", lines[1]["currency"])
+	require.Equal(t, "", lines[1]["method"])
+	require.InDelta(t, 1, lines[1]["refund_of"], 0)
+
+	require.Empty(t, write(t, export.FormatNDJSON, options, nil))
+}
+
+func TestOFX(t *testing.T) {
+	t.Parallel()
+
+	options, visa, cash := testOptions()
+
+	// Expenditures are grouped by method and currency, bank accounts first, as StreamExpenditures orders
+	// them for OFX.
+	expenditures := []*model.Expenditure{
+		{
+			ID:       3,
+			Name:     "Hot dog",
+			Amount:   model.MoneyFromFloat(3),
+			Date:     time.Date(2025, 3, 16, 0, 0, 0, 0, time.UTC),
+			Method:   cash,
+			Kind:     model.KindExpense,
+			Currency: "USD",
+		},
+		{
+			ID:     4,
+			Name:   "Payroll",
+			Amount: model.MoneyFromFloat(-2000),
+			Date:   time.Date(2025, 3, 20, 0, 0, 0, 0, time.UTC),
+			Kind:   model.KindIncome,
+		},
+		{
+			ID:         1,
+			Name:       "SQ *BLUE BOTTLE <1234> SAN FRANCISCO CA",
+			Amount:     model.MoneyFromFloat(4.5),
+			Date:       time.Date(2025, 3, 14, 0, 0, 0, 0, time.UTC),
+			Method:     visa,
+			Comment:    "Coffee & pastry",
+			ExternalID: "2025031400001",
+			Kind:       model.KindExpense,
+		},
+		{
+			ID:     2,
+			Name:   "PAYMENT - THANK YOU",
+			Amount: model.MoneyFromFloat(-100),
+			Date:   time.Date(2025, 3, 15, 0, 0, 0, 0, time.UTC),
+			Method: visa,
+			Kind:   model.KindTransfer,
+		},
+	}
+
+	out := write(t, export.FormatOFX, options, expenditures)
+	require.Contains(t, string(out), "<DTSTART>20250301</DTSTART><DTEND>20250331</DTEND>")
+
+	// Each statement's ledger balance is the net of its transactions at the end of the range.
+	require.Contains(t, string(out), "<LEDGERBAL><BALAMT>95.50</BALAMT><DTASOF>20250331</DTASOF></LEDGERBAL>")
+	require.Contains(t, string(out), "<LEDGERBAL><BALAMT>-3.00</BALAMT><DTASOF>20250331</DTASOF></LEDGERBAL>")
+	require.Contains(t, string(out), "<LEDGERBAL><BALAMT>2000.00</BALAMT><DTASOF>20250331</DTASOF></LEDGERBAL>")
+
+	// Cards have credit card statements, and other payment methods bank statements.
+	bank, card, found := strings.Cut(string(out), "</BANKMSGSRSV1>")
+	require.True(t, found)
+	require.Contains(t, bank, "<BANKMSGSRSV1>")
+	require.Contains(t, bank, "<STMTRS><CURDEF>USD</CURDEF>")
+	require.Contains(t, bank, "<BANKACCTFROM><BANKID>yaba</BANKID><ACCTID>Cash</ACCTID><ACCTTYPE>CHECKING</ACCTTYPE>"+
+		"</BANKACCTFROM>")
+	require.NotContains(t, bank, "4510XXXXXXXX1234")
+	require.Contains(t, card, "<CREDITCARDMSGSRSV1>\n<CCSTMTTRNRS>")
+	require.Contains(t, card, "<CCSTMTRS><CURDEF>CAD</CURDEF>\n<CCACCTFROM><ACCTID>4510XXXXXXXX1234</ACCTID></CCACCTFROM>")
+	require.Contains(t, card, "</CCSTMTRS>\n</CCSTMTTRNRS>\n</CREDITCARDMSGSRSV1>\n</OFX>")
+	require.NotContains(t, card, "CHECKING")
+
+	// The OFX importer reads the export back.
+	records, err := importer.ParseOFX(bytes.NewReader(out))
+	require.NoError(t, err)
+	require.Equal(t, []*importer.Record{
+		{
+			Date:       time.Date(2025, 3, 16, 0, 0, 0, 0, time.UTC),
+			Amount:     model.MoneyFromFloat(3),
+			Name:       "Hot dog",
+			ExternalID: "3",
+			Account:    "Cash",
+			Currency:   "USD",
+		},
+		{
+			Date:       time.Date(2025, 3, 20, 0, 0, 0, 0, time.UTC),
+			Amount:     model.MoneyFromFloat(-2000),
+			Name:       "Payroll",
+			ExternalID: "4",
+			Account:    "unassigned",
+			Currency:   "CAD",
+			Kind:       model.KindIncome,
+		},
+		{
+			Date:       time.Date(2025, 3, 14, 0, 0, 0, 0, time.UTC),
+			Amount:     model.MoneyFromFloat(4.5),
+			Name:       "SQ *BLUE BOTTLE <1234> SAN FRANC",
+			Comment:    "Coffee & pastry",
+			ExternalID: "2025031400001",
+			Account:    "4510XXXXXXXX1234",
+			Currency:   "CAD",
+		},
+		{
+			Date:       time.Date(2025, 3, 15, 0, 0, 0, 0, time.UTC),
+			Amount:     model.MoneyFromFloat(-100),
+			Name:       "PAYMENT - THANK YOU",
+			ExternalID: "2",
+			Account:    "4510XXXXXXXX1234",
+			Currency:   "CAD",
+			Kind:       model.KindTransfer,
+		},
+	}, records)
+
+	// Empty exports are still valid files.
+	records, err = importer.ParseOFX(bytes.NewReader(write(t, export.FormatOFX, options, nil)))
+	require.NoError(t, err)
+	require.Empty(t, records)
+}
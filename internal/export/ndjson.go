@@ -0,0 +1,73 @@
+package export
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+	"yaba/internal/model"
+)
+
+// jsonExpenditure is a line of an NDJSON export. Fields are named like those of the GraphQL API.
+type jsonExpenditure struct {
+	ID             int         `json:"id"`
+	Date           string      `json:"date"`
+	Amount         model.Money `json:"amount"`
+	Currency       string      `json:"currency"`
+	Name           string      `json:"name"`
+	Method         string      `json:"method"`
+	BudgetCategory string      `json:"budget_category"`
+	RewardCategory string      `json:"reward_category"`
+	Comment        string      `json:"comment"`
+	Source         string      `json:"source"`
+	Kind           model.Kind  `json:"kind"`
+	RefundOf       *int64      `json:"refund_of,omitempty"`
+	TransferID     *int64      `json:"transfer_id,omitempty"`
+}
+
+type ndjsonWriter struct {
+	encoder *json.Encoder
+	options *Options
+}
+
+func newNDJSONWriter(w io.Writer, options *Options) *ndjsonWriter {
+	encoder := json.NewEncoder(w)
+	encoder.SetEscapeHTML(false)
+
+	return &ndjsonWriter{encoder: encoder, options: options}
+}
+
+func (w *ndjsonWriter) Write(expenditure *model.Expenditure) error {
+	line := &jsonExpenditure{
+		ID:             expenditure.ID,
+		Date:           expenditure.Date.Format(time.DateOnly),
+		Amount:         expenditure.Amount,
+		Currency:       w.options.currency(expenditure),
+		Name:           expenditure.Name,
+		Method:         w.options.methodName(expenditure.Method),
+		BudgetCategory: expenditure.BudgetCategory,
+		RewardCategory: expenditure.RewardCategory,
+		Comment:        expenditure.Comment,
+		Source:         expenditure.Source,
+		Kind:           expenditure.Kind,
+	}
+
+	if expenditure.RefundOf.Valid {
+		line.RefundOf = &expenditure.RefundOf.Int64
+	}
+
+	if expenditure.TransferID.Valid {
+		line.TransferID = &expenditure.TransferID.Int64
+	}
+
+	// The encoder ends each value with a newline.
+	if err := w.encoder.Encode(line); err != nil {
+		return fmt.Errorf("failed to write json: %w", err)
+	}
+
+	return nil
+}
+
+func (w *ndjsonWriter) Close() error {
+	return nil
+}
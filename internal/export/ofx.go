@@ -0,0 +1,224 @@
+package export
+
+import (
+	"fmt"
+	"html"
+	"io"
+	"strconv"
+	"time"
+	"yaba/internal/model"
+
+	"github.com/google/uuid"
+)
+
+const (
+	ofxDateLayout     = "20060102"
+	ofxDateTimeLayout = "20060102150405"
+	// maxOFXNameLength and maxOFXAccountLength are the sizes of NAME and ACCTID in the OFX spec.
+	maxOFXNameLength    = 32
+	maxOFXAccountLength = 22
+)
+
+const ofxHeader = `<?xml version="1.0" encoding="UTF-8" standalone="no"?>
+<?OFX OFXHEADER="200" VERSION="220" SECURITY="NONE" OLDFILEUID="NONE" NEWFILEUID="NONE"?>
+<OFX>
+<SIGNONMSGSRSV1><SONRS><STATUS><CODE>0</CODE><SEVERITY>INFO</SEVERITY></STATUS>` +
+	`<DTSERVER>%s</DTSERVER><LANGUAGE>ENG</LANGUAGE></SONRS></SIGNONMSGSRSV1>
+`
+
+// ofxStatementTags are the elements of a statement of a bank account, or of a credit card.
+type ofxStatementTags struct {
+	messageSet, transaction, statement, account string
+}
+
+//nolint:gochecknoglobals
+var (
+	ofxBankStatement       = ofxStatementTags{"BANKMSGSRSV1", "STMTTRNRS", "STMTRS", "BANKACCTFROM"}
+	ofxCreditCardStatement = ofxStatementTags{"CREDITCARDMSGSRSV1", "CCSTMTTRNRS", "CCSTMTRS", "CCACCTFROM"}
+)
+
+// ofxWriter writes OFX 2 with a statement for each payment method and currency, since all
+// transactions of a statement share its account and currency. Payment methods with a card type
+// have credit card statements, and the others bank statements.
+type ofxWriter struct {
+	w       io.Writer
+	options *Options
+	started bool
+	// statements is the number of statements started, and the key and tags of the last one.
+	statements int
+	method     uuid.UUID
+	currency   string
+	tags       *ofxStatementTags
+	// balance is the sum of the amounts of the last statement's transactions.
+	balance model.Money
+}
+
+func newOFXWriter(w io.Writer, options *Options) *ofxWriter {
+	return &ofxWriter{w: w, options: options}
+}
+
+func (w *ofxWriter) Write(expenditure *model.Expenditure) error {
+	if err := w.start(); err != nil {
+		return err
+	}
+
+	currency := w.options.currency(expenditure)
+	if w.statements == 0 || expenditure.Method != w.method || currency != w.currency {
+		if err := w.endStatement(); err != nil {
+			return err
+		}
+
+		if err := w.startStatement(expenditure.Method, currency); err != nil {
+			return err
+		}
+	}
+
+	fitID := expenditure.ExternalID
+	if fitID == "" {
+		fitID = strconv.Itoa(expenditure.ID)
+	}
+
+	name := []rune(expenditure.Name)
+	if len(name) > maxOFXNameLength {
+		name = name[:maxOFXNameLength]
+	}
+
+	// OFX amounts are signed from the account holder's point of view, so purchases are negative.
+	w.balance -= expenditure.Amount
+
+	return w.printf("<STMTTRN><TRNTYPE>%s</TRNTYPE><DTPOSTED>%s</DTPOSTED><TRNAMT>%s</TRNAMT>"+
+		"<FITID>%s</FITID><NAME>%s</NAME><MEMO>%s</MEMO></STMTTRN>\n",
+		ofxTransactionType(expenditure),
+		expenditure.Date.Format(ofxDateLayout),
+		(-expenditure.Amount).String(),
+		html.EscapeString(fitID),
+		html.EscapeString(string(name)),
+		html.EscapeString(expenditure.Comment))
+}
+
+func (w *ofxWriter) Close() error {
+	if err := w.start(); err != nil {
+		return err
+	}
+
+	if err := w.endStatement(); err != nil {
+		return err
+	}
+
+	if w.tags != nil {
+		if err := w.printf("</%s>\n", w.tags.messageSet); err != nil {
+			return err
+		}
+	}
+
+	return w.printf("</OFX>\n")
+}
+
+func (w *ofxWriter) start() error {
+	if w.started {
+		return nil
+	}
+
+	w.started = true
+
+	return w.printf(ofxHeader, time.Now().UTC().Format(ofxDateTimeLayout))
+}
+
+func (w *ofxWriter) startStatement(method uuid.UUID, currency string) error {
+	w.statements++
+	w.method = method
+	w.currency = currency
+	w.balance = 0
+
+	// Accounts are identified the way the OFX importer matches them to payment methods.
+	account := "unassigned"
+	tags := &ofxBankStatement
+
+	if pm, ok := w.options.Methods[method]; ok {
+		account = pm.DisplayName
+		if pm.AccountID != "" {
+			account = pm.AccountID
+		}
+
+		if pm.CardType != uuid.Nil {
+			tags = &ofxCreditCardStatement
+		}
+	}
+
+	if runes := []rune(account); len(runes) > maxOFXAccountLength {
+		account = string(runes[:maxOFXAccountLength])
+	}
+
+	// Statements of the same kind share a message set, so bank accounts and cards are each written
+	// together when expenditures are grouped by the kind of their payment method.
+	if w.tags != tags {
+		if w.tags != nil {
+			if err := w.printf("</%s>\n", w.tags.messageSet); err != nil {
+				return err
+			}
+		}
+
+		if err := w.printf("<%s>\n", tags.messageSet); err != nil {
+			return err
+		}
+
+		w.tags = tags
+	}
+
+	// Credit card accounts are identified by their number alone.
+	accountFrom := "<ACCTID>" + html.EscapeString(account) + "</ACCTID>"
+	if tags == &ofxBankStatement {
+		accountFrom = "<BANKID>yaba</BANKID>" + accountFrom + "<ACCTTYPE>CHECKING</ACCTTYPE>"
+	}
+
+	return w.printf("<%s><TRNUID>%d</TRNUID><STATUS><CODE>0</CODE><SEVERITY>INFO</SEVERITY></STATUS>\n"+
+		"<%s><CURDEF>%s</CURDEF>\n"+
+		"<%s>%s</%s>\n"+
+		"<BANKTRANLIST><DTSTART>%s</DTSTART><DTEND>%s</DTEND>\n",
+		tags.transaction,
+		w.statements,
+		tags.statement,
+		html.EscapeString(currency),
+		tags.account,
+		accountFrom,
+		tags.account,
+		w.options.Filter.Since.Format(ofxDateLayout),
+		w.options.Filter.Until.Format(ofxDateLayout))
+}
+
+func (w *ofxWriter) endStatement() error {
+	if w.statements == 0 {
+		return nil
+	}
+
+	// Statements only hold the exported range, so the ledger balance is the net of its transactions as
+	// of the end of the range.
+	return w.printf("</BANKTRANLIST>\n<LEDGERBAL><BALAMT>%s</BALAMT><DTASOF>%s</DTASOF></LEDGERBAL>\n"+
+		"</%s>\n</%s>\n",
+		w.balance.String(),
+		w.options.Filter.Until.Format(ofxDateLayout),
+		w.tags.statement,
+		w.tags.transaction)
+}
+
+func (w *ofxWriter) printf(format string, args ...any) error {
+	if _, err := fmt.Fprintf(w.w, format, args...); err != nil {
+		return fmt.Errorf("failed to write ofx: %w", err)
+	}
+
+	return nil
+}
+
+// ofxTransactionType is the TRNTYPE that the OFX importer reads back as the expenditure's kind.
+func ofxTransactionType(expenditure *model.Expenditure) string {
+	switch {
+	case expenditure.Kind == model.KindTransfer:
+		return "XFER"
+	case expenditure.Kind == model.KindIncome:
+		return "DIRECTDEP"
+	case expenditure.Amount < 0:
+		return "CREDIT"
+	default:
+		return "DEBIT"
+	}
+}
@@ -0,0 +1,71 @@
+package handlers
+
+import (
+	"errors"
+	"io"
+	"log"
+	"mime"
+	"net/http"
+	"strconv"
+	yabaerrors "yaba/errors"
+	"yaba/internal/attachment"
+	"yaba/internal/blob"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// AttachmentHandler serves the file of the attachment with the ID in the path. Attachments of other
+// users are not found.
+type AttachmentHandler struct {
+	Pool  *pgxpool.Pool
+	Blobs blob.Store
+}
+
+func NewAttachmentHandler(pool *pgxpool.Pool, blobs blob.Store) *AttachmentHandler {
+	return &AttachmentHandler{Pool: pool, Blobs: blobs}
+}
+
+func (h *AttachmentHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+
+		return
+	}
+
+	id, err := uuid.Parse(r.PathValue("id"))
+	if err != nil {
+		http.NotFound(w, r)
+
+		return
+	}
+
+	file, content, err := attachment.Open(r.Context(), h.Pool, h.Blobs, id)
+	if err != nil {
+		var noSuchElement yabaerrors.NoSuchElementError
+		if errors.As(err, &noSuchElement) {
+			http.NotFound(w, r)
+
+			return
+		}
+
+		log.Println("Error opening attachment:", err)
+		w.WriteHeader(http.StatusInternalServerError)
+
+		return
+	}
+
+	defer func() { _ = content.Close() }()
+
+	w.Header().Set("Content-Type", file.ContentType)
+	w.Header().Set("Content-Length", strconv.FormatInt(file.Size, 10))
+	w.Header().Set("Content-Disposition", mime.FormatMediaType("inline", map[string]string{"filename": file.Filename}))
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.Header().Set("Cache-Control", "private")
+
+	if _, err = io.Copy(w, content); err != nil {
+		log.Println("Error writing attachment:", err)
+	}
+}
+
+var _ http.Handler = (*AttachmentHandler)(nil)
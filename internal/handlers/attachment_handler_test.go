@@ -0,0 +1,107 @@
+package handlers_test
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"yaba/errors"
+	"yaba/graph/model"
+	"yaba/internal/blob"
+	"yaba/internal/ctxutil"
+	"yaba/internal/handlers"
+	internalmodel "yaba/internal/model"
+	"yaba/internal/test/helper"
+
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAttachmentHandler(t *testing.T) {
+	t.Parallel()
+
+	pool := helper.GetTestPool()
+	user := uuid.New()
+	ctx := ctxutil.WithUser(t.Context(), user)
+
+	blobs, err := blob.NewLocalStore(t.TempDir())
+	require.NoError(t, err)
+
+	resolver := &handlers.Resolver{Pool: pool, Blobs: blobs}
+
+	result, err := resolver.Mutation().CreateExpenditures(ctx, []*model.ExpenditureInput{
+		{Name: ptr("Laptop"), Amount: money(1200), Date: "2024-05-01"},
+	})
+	require.NoError(t, err)
+
+	expenditures, err := resolver.ImportBatch().Expenditures(ctx, &result.Batch)
+	require.NoError(t, err)
+
+	laptop := expenditures[0]
+
+	receipt, err := os.ReadFile("../importer/testdata/statement.pdf")
+	require.NoError(t, err)
+
+	upload := func(filename string, content []byte) (*model.Attachment, error) {
+		return resolver.Mutation().AddAttachment(ctx, *laptop.ID, graphql.Upload{
+			File:     bytes.NewReader(content),
+			Filename: filename,
+		})
+	}
+
+	attached, err := upload("../../receipt.pdf", receipt)
+	require.NoError(t, err)
+	require.Equal(t, "receipt.pdf", attached.Filename)
+	require.Equal(t, "application/pdf", attached.ContentType)
+	require.Equal(t, len(receipt), attached.Size)
+
+	// Only images and PDFs are accepted
+	_, err = upload("notes.txt", []byte("not a receipt"))
+	require.Error(t, err)
+
+	_, err = resolver.Mutation().AddAttachment(ctxutil.WithUser(t.Context(), uuid.New()), *laptop.ID,
+		graphql.Upload{File: bytes.NewReader(receipt), Filename: "receipt.pdf"})
+	require.ErrorContains(t, err, "no such element")
+
+	attachments, err := resolver.ExpenditureResponse().Attachments(ctx, laptop)
+	require.NoError(t, err)
+	require.Equal(t, []*model.Attachment{attached}, attachments)
+
+	mux := http.NewServeMux()
+	mux.Handle(model.AttachmentURLPrefix+"{id}", handlers.NewAttachmentHandler(pool, blobs))
+
+	download := func(owner uuid.UUID) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, httptest.NewRequestWithContext(
+			ctxutil.WithUser(t.Context(), owner), http.MethodGet, attached.URL, nil))
+
+		return w
+	}
+
+	w := download(user)
+	require.Equal(t, http.StatusOK, w.Code)
+	require.Equal(t, "application/pdf", w.Header().Get("Content-Type"))
+	require.Equal(t, `inline; filename=receipt.pdf`, w.Header().Get("Content-Disposition"))
+	require.Equal(t, receipt, w.Body.Bytes())
+
+	require.Equal(t, http.StatusNotFound, download(uuid.New()).Code)
+
+	deleted, err := resolver.Mutation().DeleteAttachment(ctx, attached.ID)
+	require.NoError(t, err)
+	require.True(t, deleted)
+	require.Equal(t, http.StatusNotFound, download(user).Code)
+
+	// Undoing the import deletes the files attached to its expenditures
+	attached, err = upload("receipt.pdf", receipt)
+	require.NoError(t, err)
+
+	undone, err := resolver.Mutation().UndoImport(ctx, result.Batch.ID)
+	require.NoError(t, err)
+	require.Equal(t, 1, undone)
+
+	key := (&internalmodel.Attachment{Owner: user, ID: uuid.MustParse(attached.ID)}).BlobKey()
+	_, err = blobs.Get(t.Context(), key)
+	require.ErrorAs(t, err, &errors.NoSuchElementError{})
+}
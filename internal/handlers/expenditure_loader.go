@@ -0,0 +1,121 @@
+package handlers
+
+import (
+	"context"
+	"sync"
+	"time"
+	"yaba/internal/database"
+	model1 "yaba/internal/model"
+
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// loaderWait is how long a loader waits for the other expenditures of a response to ask for their
+// values before fetching them all in one query.
+const loaderWait = 2 * time.Millisecond
+
+type loadersKey struct{}
+
+// expenditureLoaders load the fields of the expenditures in a response, such as their home amounts,
+// splits, tags and attachments, together instead of one query per expenditure.
+type expenditureLoaders struct {
+	homeAmounts *loader[*model1.Money]
+	splits      *loader[[]*model1.ExpenditureSplit]
+	tags        *loader[[]*model1.Tag]
+	attachments *loader[[]*model1.Attachment]
+}
+
+func newExpenditureLoaders(pool *pgxpool.Pool) *expenditureLoaders {
+	return &expenditureLoaders{
+		homeAmounts: newLoader(func(ctx context.Context, ids []int) (map[int]*model1.Money, error) {
+			return database.GetHomeAmounts(ctx, pool, ids)
+		}),
+		splits: newLoader(func(ctx context.Context, ids []int) (map[int][]*model1.ExpenditureSplit, error) {
+			return database.ListSplitsByExpenditure(ctx, pool, ids)
+		}),
+		tags: newLoader(func(ctx context.Context, ids []int) (map[int][]*model1.Tag, error) {
+			return database.ListTagsByExpenditure(ctx, pool, ids)
+		}),
+		attachments: newLoader(func(ctx context.Context, ids []int) (map[int][]*model1.Attachment, error) {
+			return database.ListAttachmentsByExpenditure(ctx, pool, ids)
+		}),
+	}
+}
+
+// WithExpenditureLoaders returns a context whose resolvers load the fields of expenditures in batches.
+func WithExpenditureLoaders(ctx context.Context, pool *pgxpool.Pool) context.Context {
+	return context.WithValue(ctx, loadersKey{}, newExpenditureLoaders(pool))
+}
+
+// loadExpenditures gives every operation its own loaders, so that values aren't shared between users
+// or kept after the operation.
+func loadExpenditures(pool *pgxpool.Pool) graphql.OperationMiddleware {
+	return func(ctx context.Context, next graphql.OperationHandler) graphql.ResponseHandler {
+		return next(WithExpenditureLoaders(ctx, pool))
+	}
+}
+
+// loaders returns the operation's loaders, or new ones if the resolver is called outside an operation.
+func (r *Resolver) loaders(ctx context.Context) *expenditureLoaders {
+	if loaders, ok := ctx.Value(loadersKey{}).(*expenditureLoaders); ok {
+		return loaders
+	}
+
+	return newExpenditureLoaders(r.Pool)
+}
+
+// loader collects the expenditure IDs asked for within loaderWait of each other and fetches their
+// values with one call.
+type loader[V any] struct {
+	fetch func(ctx context.Context, ids []int) (map[int]V, error)
+
+	mu    sync.Mutex
+	batch *loaderBatch[V]
+}
+
+type loaderBatch[V any] struct {
+	ids    []int
+	done   chan struct{}
+	values map[int]V
+	err    error
+}
+
+func newLoader[V any](fetch func(ctx context.Context, ids []int) (map[int]V, error)) *loader[V] {
+	return &loader[V]{fetch: fetch}
+}
+
+// load returns the expenditure's value, or the zero value if the fetch didn't return one.
+func (l *loader[V]) load(ctx context.Context, id int) (V, error) {
+	l.mu.Lock()
+
+	batch := l.batch
+	if batch == nil {
+		batch = &loaderBatch[V]{done: make(chan struct{})}
+		l.batch = batch
+
+		time.AfterFunc(loaderWait, func() { l.run(ctx, batch) })
+	}
+
+	batch.ids = append(batch.ids, id)
+
+	l.mu.Unlock()
+
+	select {
+	case <-batch.done:
+		return batch.values[id], batch.err
+	case <-ctx.Done():
+		var zero V
+
+		return zero, ctx.Err()
+	}
+}
+
+func (l *loader[V]) run(ctx context.Context, batch *loaderBatch[V]) {
+	l.mu.Lock()
+	l.batch = nil
+	l.mu.Unlock()
+
+	batch.values, batch.err = l.fetch(ctx, batch.ids)
+	close(batch.done)
+}
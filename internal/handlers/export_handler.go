@@ -0,0 +1,111 @@
+package handlers
+
+import (
+	"errors"
+	"log"
+	"mime"
+	"net/http"
+	yabaerrors "yaba/errors"
+	"yaba/internal/export"
+	"yaba/internal/model"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// ExportHandler streams the user's expenditures as a file. The query takes the format and the same
+// filters as the expenditures query, with a "tag" parameter for each tag.
+type ExportHandler struct {
+	Pool *pgxpool.Pool
+}
+
+func NewExportHandler(pool *pgxpool.Pool) *ExportHandler {
+	return &ExportHandler{Pool: pool}
+}
+
+func (h *ExportHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+
+		return
+	}
+
+	query := r.URL.Query()
+
+	format := export.FormatCSV
+	if name := query.Get("format"); name != "" {
+		var err error
+		if format, err = export.ParseFormat(name); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+
+			return
+		}
+	}
+
+	optional := func(key string) *string {
+		if !query.Has(key) {
+			return nil
+		}
+
+		value := query.Get(key)
+
+		return &value
+	}
+
+	since, until, err := parseExpenditureDates(optional("since"), optional("until"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+
+		return
+	}
+
+	filter := &model.ExpenditureFilter{
+		Search:        optional("filter"),
+		Category:      optional("category"),
+		PaymentMethod: optional("paymentMethod"),
+		Source:        optional("source"),
+		Tags:          query["tag"],
+		Since:         since,
+		Until:         until,
+	}
+
+	w.Header().Set("Content-Type", format.ContentType())
+	w.Header().Set("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{
+		"filename": "expenditures." + string(format),
+	}))
+
+	body := &responseStarted{ResponseWriter: w}
+	if err = export.Export(r.Context(), h.Pool, body, format, filter); err != nil {
+		// Once the file has started, the status can no longer change, so the export is cut short.
+		if body.started {
+			log.Println("Error writing export:", err)
+
+			return
+		}
+
+		w.Header().Del("Content-Disposition")
+
+		var invalidInput yabaerrors.InvalidInputError
+		if errors.As(err, &invalidInput) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+
+			return
+		}
+
+		log.Println("Error exporting expenditures:", err)
+		w.WriteHeader(http.StatusInternalServerError)
+	}
+}
+
+// responseStarted records whether anything was written to the response.
+type responseStarted struct {
+	http.ResponseWriter
+	started bool
+}
+
+func (w *responseStarted) Write(p []byte) (int, error) {
+	w.started = true
+
+	return w.ResponseWriter.Write(p) //nolint:wrapcheck
+}
+
+var _ http.Handler = (*ExportHandler)(nil)
@@ -0,0 +1,82 @@
+package handlers_test
+
+import (
+	"encoding/csv"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"yaba/graph/model"
+	"yaba/internal/ctxutil"
+	"yaba/internal/handlers"
+	"yaba/internal/test/helper"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExportHandler(t *testing.T) {
+	t.Parallel()
+
+	pool := helper.GetTestPool()
+	user := uuid.New()
+	ctx := ctxutil.WithUser(t.Context(), user)
+	resolver := &handlers.Resolver{Pool: pool}
+
+	_, err := resolver.Mutation().CreateExpenditures(ctx, []*model.ExpenditureInput{
+		{Name: ptr("Groceries"), Amount: money(80), Date: "2024-05-01", BudgetCategory: ptr("food")},
+		{Name: ptr("Coffee, large"), Amount: money(5), Date: "2024-05-02", BudgetCategory: ptr("food")},
+		{Name: ptr("Rent"), Amount: money(1500), Date: "2024-05-01", BudgetCategory: ptr("housing")},
+		{Name: ptr("Old groceries"), Amount: money(60), Date: "2024-04-01", BudgetCategory: ptr("food")},
+	})
+	require.NoError(t, err)
+
+	download := func(owner uuid.UUID, url string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		handlers.NewExportHandler(pool).ServeHTTP(w, httptest.NewRequestWithContext(
+			ctxutil.WithUser(t.Context(), owner), http.MethodGet, url, nil))
+
+		return w
+	}
+
+	url, err := resolver.Query().ExportExpenditures(ctx, &model.ExpenditureFilter{
+		Category: ptr("food"),
+		Since:    ptr("2024-05-01"),
+		Until:    ptr("2024-05-31"),
+	}, nil)
+	require.NoError(t, err)
+	require.Equal(t, model.ExportURL+"?category=food&format=csv&since=2024-05-01&until=2024-05-31", url)
+
+	w := download(user, url)
+	require.Equal(t, http.StatusOK, w.Code)
+	require.Equal(t, "text/csv; charset=utf-8", w.Header().Get("Content-Type"))
+	require.Equal(t, `attachment; filename=expenditures.csv`, w.Header().Get("Content-Disposition"))
+
+	rows, err := csv.NewReader(w.Body).ReadAll()
+	require.NoError(t, err)
+	require.Equal(t, [][]string{
+		{"date", "amount", "name", "method", "budget_category", "reward_category", "comment"},
+		{"2024-05-01", "80.00", "Groceries", "", "food", "", ""},
+		{"2024-05-02", "5.00", "Coffee, large", "", "food", "", ""},
+	}, rows)
+
+	// Other users' expenditures aren't exported.
+	w = download(uuid.New(), url)
+	require.Equal(t, http.StatusOK, w.Code)
+	require.Equal(t, "date,amount,name,method,budget_category,reward_category,comment\n", w.Body.String())
+
+	w = download(user, model.ExportURL+"?format=ndjson&filter=rent")
+	require.Equal(t, http.StatusOK, w.Code)
+	require.Equal(t, "application/x-ndjson", w.Header().Get("Content-Type"))
+	require.Contains(t, w.Body.String(), `"name":"Rent"`)
+	require.NotContains(t, w.Body.String(), "Groceries")
+
+	// Invalid queries are rejected before the download starts.
+	for _, query := range []string{"?format=xlsx", "?since=May", "?filter=date:yesterday"} {
+		w = download(user, model.ExportURL+query)
+		require.Equal(t, http.StatusBadRequest, w.Code, query)
+		require.Empty(t, w.Header().Get("Content-Disposition"), query)
+	}
+
+	_, err = resolver.Query().ExportExpenditures(ctx, &model.ExpenditureFilter{Search: ptr("amount:lots")}, nil)
+	require.Error(t, err)
+}
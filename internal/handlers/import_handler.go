@@ -0,0 +1,127 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"log"
+	"net/http"
+	yabaerrors "yaba/errors"
+	"yaba/graph/model"
+	"yaba/internal/database"
+	"yaba/internal/importer"
+	internalmodel "yaba/internal/model"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+const maxUploadSize = 32 << 20
+
+// ImportHandler persists the transactions of a statement uploaded in the "file" field of a
+// multipart form. The optional "method" field is the payment method the statement belongs to. It
+// is assigned to transactions that don't match one of the user's payment methods.
+type ImportHandler struct {
+	Pool      *pgxpool.Pool
+	ParseFunc func(context.Context, *pgxpool.Pool, io.Reader, uuid.UUID) (*importer.Statement, error)
+}
+
+// ImportResponse is the JSON body returned after a successful import.
+type ImportResponse struct {
+	*model.ImportResult
+	Unparsed []string `json:"unparsed"`
+}
+
+func NewOFXImportHandler(pool *pgxpool.Pool) *ImportHandler {
+	return &ImportHandler{
+		Pool: pool,
+		ParseFunc: func(_ context.Context, _ *pgxpool.Pool, r io.Reader, _ uuid.UUID) (*importer.Statement, error) {
+			records, err := importer.ParseOFX(r)
+			if err != nil {
+				return nil, err
+			}
+
+			return &importer.Statement{Records: records, Unparsed: []string{}}, nil
+		},
+	}
+}
+
+func NewPDFImportHandler(pool *pgxpool.Pool) *ImportHandler {
+	return &ImportHandler{
+		Pool:      pool,
+		ParseFunc: importer.ParsePDFStatement,
+	}
+}
+
+func (h *ImportHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+
+		return
+	}
+
+	if err := r.ParseMultipartForm(maxUploadSize); err != nil {
+		http.Error(w, "invalid multipart form", http.StatusBadRequest)
+
+		return
+	}
+
+	file, header, err := r.FormFile("file")
+	if err != nil {
+		http.Error(w, "missing file", http.StatusBadRequest)
+
+		return
+	}
+
+	defer func() { _ = file.Close() }()
+
+	method := uuid.Nil
+	if value := r.FormValue("method"); value != "" {
+		if method, err = uuid.Parse(value); err != nil {
+			http.Error(w, "invalid payment method", http.StatusBadRequest)
+
+			return
+		}
+	}
+
+	statement, err := h.ParseFunc(r.Context(), h.Pool, file, method)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+
+		return
+	}
+
+	var result *internalmodel.ImportResult
+
+	expenditures, err := importer.ToExpenditures(r.Context(), h.Pool, statement.Records, header.Filename, method)
+	if err == nil {
+		result, err = database.ImportExpenditures(r.Context(), h.Pool, expenditures)
+	}
+
+	if err != nil {
+		var invalidInput yabaerrors.InvalidInputError
+		if errors.As(err, &invalidInput) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+
+			return
+		}
+
+		log.Println("Error importing statement:", err)
+		w.WriteHeader(http.StatusInternalServerError)
+
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+
+	err = json.NewEncoder(w).Encode(ImportResponse{
+		ImportResult: model.ImportResultToImportResultResponse(result),
+		Unparsed:     statement.Unparsed,
+	})
+	if err != nil {
+		log.Println("Error writing import response:", err)
+	}
+}
+
+var _ http.Handler = (*ImportHandler)(nil)
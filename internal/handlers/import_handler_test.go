@@ -0,0 +1,171 @@
+package handlers_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+	"yaba/internal/ctxutil"
+	"yaba/internal/database"
+	"yaba/internal/handlers"
+	"yaba/internal/model"
+	"yaba/internal/test/helper"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOFXImportHandler(t *testing.T) {
+	t.Parallel()
+
+	pool := helper.GetTestPool()
+	user := uuid.New()
+	ctx := ctxutil.WithUser(t.Context(), user)
+
+	card := &model.PaymentMethod{ID: uuid.New(), DisplayName: "Visa", AccountID: "4510XXXXXXXX1234"}
+	require.NoError(t, database.CreatePaymentMethod(ctx, pool, card))
+
+	handler := handlers.NewOFXImportHandler(pool)
+
+	// Importing the same statement twice doesn't duplicate rows.
+	for range 2 {
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, newUploadRequest(t, user, "../importer/testdata/statement.qfx", ""))
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	}
+
+	since := time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)
+	until := time.Date(2025, 3, 31, 0, 0, 0, 0, time.UTC)
+	expenditures, err := database.ListExpenditures(ctx, pool, nil, nil, nil, nil, nil, since, until, nil, nil)
+	require.NoError(t, err)
+	require.Len(t, expenditures, 2)
+
+	for _, e := range expenditures {
+		require.Equal(t, card.ID, e.Method)
+		require.Equal(t, "statement.qfx", e.Source)
+		require.NotEmpty(t, e.ExternalID)
+	}
+}
+
+func TestPDFImportHandler(t *testing.T) {
+	t.Parallel()
+
+	pool := helper.GetTestPool()
+	user := uuid.New()
+	ctx := ctxutil.WithUser(t.Context(), user)
+
+	card := &model.PaymentMethod{ID: uuid.New(), DisplayName: "Example Visa"}
+	require.NoError(t, database.CreatePaymentMethod(ctx, pool, card))
+
+	w := httptest.NewRecorder()
+	handlers.NewPDFImportHandler(pool).ServeHTTP(
+		w, newUploadRequest(t, user, "../importer/testdata/statement.pdf", card.ID.String()))
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	var response handlers.ImportResponse
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&response))
+	require.Equal(t, 3, response.Inserted)
+	require.Equal(t, []string{"MAR 16 MAR 17 ILLEGIBLE ROW"}, response.Unparsed)
+
+	since := time.Date(2024, 12, 1, 0, 0, 0, 0, time.UTC)
+	until := time.Date(2025, 3, 31, 0, 0, 0, 0, time.UTC)
+	expenditures, err := database.ListExpenditures(ctx, pool, nil, nil, nil, nil, nil, since, until, nil, nil)
+	require.NoError(t, err)
+	require.Len(t, expenditures, 3)
+
+	for _, e := range expenditures {
+		require.Equal(t, card.ID, e.Method)
+		require.Equal(t, "statement.pdf", e.Source)
+	}
+}
+
+func TestOFXImportHandlerBadRequests(t *testing.T) {
+	t.Parallel()
+
+	pool := helper.GetTestPool()
+	user := uuid.New()
+	handler := handlers.NewOFXImportHandler(pool)
+
+	testCases := []struct {
+		name    string
+		request func() *http.Request
+		code    int
+	}{
+		{
+			name: "wrong method",
+			request: func() *http.Request {
+				return httptest.NewRequestWithContext(
+					ctxutil.WithUser(t.Context(), user), http.MethodGet, "/api/import/ofx", nil)
+			},
+			code: http.StatusMethodNotAllowed,
+		},
+		{
+			name: "not multipart",
+			request: func() *http.Request {
+				return httptest.NewRequestWithContext(
+					ctxutil.WithUser(t.Context(), user), http.MethodPost, "/api/import/ofx", nil)
+			},
+			code: http.StatusBadRequest,
+		},
+		{
+			name: "not ofx",
+			request: func() *http.Request {
+				return newUploadRequest(t, user, "testdata/spend.csv", "")
+			},
+			code: http.StatusBadRequest,
+		},
+		{
+			name: "bad payment method",
+			request: func() *http.Request {
+				return newUploadRequest(t, user, "../importer/testdata/statement.ofx", "visa")
+			},
+			code: http.StatusBadRequest,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, tc.request())
+			require.Equal(t, tc.code, w.Code)
+		})
+	}
+}
+
+func newUploadRequest(t *testing.T, user uuid.UUID, path, method string) *http.Request {
+	t.Helper()
+
+	f, err := os.Open(path)
+	require.NoError(t, err)
+
+	defer func() { _ = f.Close() }()
+
+	body := &bytes.Buffer{}
+	writer := multipart.NewWriter(body)
+
+	part, err := writer.CreateFormFile("file", filepath.Base(path))
+	require.NoError(t, err)
+
+	_, err = io.Copy(part, f)
+	require.NoError(t, err)
+
+	if method != "" {
+		require.NoError(t, writer.WriteField("method", method))
+	}
+
+	require.NoError(t, writer.Close())
+
+	request := httptest.NewRequestWithContext(
+		ctxutil.WithUser(t.Context(), user), http.MethodPost, "/api/import", body)
+	request.Header.Set("Content-Type", writer.FormDataContentType())
+
+	return request
+}
@@ -1,6 +1,11 @@
 package handlers
 
 import (
+	"fmt"
+	"strconv"
+	"time"
+	"yaba/internal/blob"
+
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
@@ -10,4 +15,47 @@ import (
 
 type Resolver struct {
 	Pool *pgxpool.Pool
+	// Blobs stores the files attached to expenditures.
+	Blobs blob.Store
+}
+
+func parseExpenditureIDs(ids []string) ([]int, error) {
+	expenditureIDs := make([]int, len(ids))
+
+	for i, id := range ids {
+		expenditureID, err := strconv.Atoi(id)
+		if err != nil {
+			return nil, fmt.Errorf("invalid expenditure ID: %w", err)
+		}
+
+		expenditureIDs[i] = expenditureID
+	}
+
+	return expenditureIDs, nil
+}
+
+// parseExpenditureDates parses the dates of an expenditures query, which default to all expenditures
+// until today.
+func parseExpenditureDates(since, until *string) (time.Time, time.Time, error) {
+	start := time.Unix(0, 0).Format(time.DateOnly)
+	if since != nil {
+		start = *since
+	}
+
+	end := time.Now().Format(time.DateOnly)
+	if until != nil {
+		end = *until
+	}
+
+	sinceTime, err := time.ParseInLocation(time.DateOnly, start, time.UTC)
+	if err != nil {
+		return time.Time{}, time.Time{}, fmt.Errorf("invalid start date: %w", err)
+	}
+
+	untilTime, err := time.ParseInLocation(time.DateOnly, end, time.UTC)
+	if err != nil {
+		return time.Time{}, time.Time{}, fmt.Errorf("invalid end date: %w", err)
+	}
+
+	return sinceTime, untilTime, nil
 }
@@ -7,15 +7,114 @@ package handlers
 import (
 	"context"
 	"fmt"
+	"strconv"
 	"time"
+	yabaerrors "yaba/errors"
 	"yaba/graph/model"
 	"yaba/graph/server"
+	"yaba/internal/attachment"
 	"yaba/internal/ctxutil"
+	"yaba/internal/currency"
 	"yaba/internal/database"
+	"yaba/internal/importer"
+	model1 "yaba/internal/model"
+	"yaba/internal/search"
 
+	"github.com/99designs/gqlgen/graphql"
 	"github.com/google/uuid"
 )
 
+// HomeAmount is the resolver for the homeAmount field.
+func (r *expenditureResponseResolver) HomeAmount(ctx context.Context, obj *model.ExpenditureResponse) (*model1.Money, error) {
+	if obj.ID == nil || obj.Amount == nil {
+		return nil, nil
+	}
+
+	// Amounts without a currency are already in the home currency.
+	if obj.Currency == nil || *obj.Currency == "" {
+		return obj.Amount, nil
+	}
+
+	expenditureID, err := strconv.Atoi(*obj.ID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid expenditure ID: %w", err)
+	}
+
+	return r.loaders(ctx).homeAmounts.load(ctx, expenditureID)
+}
+
+// Splits is the resolver for the splits field.
+func (r *expenditureResponseResolver) Splits(ctx context.Context, obj *model.ExpenditureResponse) ([]*model.ExpenditureSplit, error) {
+	if obj.ID == nil {
+		return []*model.ExpenditureSplit{}, nil
+	}
+
+	expenditureID, err := strconv.Atoi(*obj.ID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid expenditure ID: %w", err)
+	}
+
+	splits, err := r.loaders(ctx).splits.load(ctx, expenditureID)
+	if err != nil {
+		return nil, err
+	}
+
+	return model.ExpenditureSplitsToExpenditureSplitResponses(splits), nil
+}
+
+// Tags is the resolver for the tags field.
+func (r *expenditureResponseResolver) Tags(ctx context.Context, obj *model.ExpenditureResponse) ([]*model.Tag, error) {
+	if obj.ID == nil {
+		return []*model.Tag{}, nil
+	}
+
+	expenditureID, err := strconv.Atoi(*obj.ID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid expenditure ID: %w", err)
+	}
+
+	tags, err := r.loaders(ctx).tags.load(ctx, expenditureID)
+	if err != nil {
+		return nil, err
+	}
+
+	return model.TagsToTagResponses(tags), nil
+}
+
+// Attachments is the resolver for the attachments field.
+func (r *expenditureResponseResolver) Attachments(ctx context.Context, obj *model.ExpenditureResponse) ([]*model.Attachment, error) {
+	if obj.ID == nil {
+		return []*model.Attachment{}, nil
+	}
+
+	expenditureID, err := strconv.Atoi(*obj.ID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid expenditure ID: %w", err)
+	}
+
+	attachments, err := r.loaders(ctx).attachments.load(ctx, expenditureID)
+	if err != nil {
+		return nil, err
+	}
+
+	return model.AttachmentsToAttachmentResponses(attachments), nil
+}
+
+// Expenditures is the resolver for the expenditures field.
+func (r *importBatchResolver) Expenditures(ctx context.Context, obj *model.ImportBatch) ([]*model.ExpenditureResponse, error) {
+	batchID, err := uuid.Parse(obj.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse UUID: %w", err)
+	}
+
+	expenditures, err := database.ListImportBatchExpenditures(ctx, r.Pool, batchID)
+	if err != nil {
+		return nil, err
+	}
+
+	return model.ExpendituresToExpenitureResponse(expenditures)
+}
+
 // CreateBudget is the resolver for the createBudget field.
 func (r *mutationResolver) CreateBudget(ctx context.Context, input model.NewBudgetInput) (*model.BudgetResponse, error) {
 	user := ctxutil.GetUser(ctx)
@@ -41,13 +140,13 @@ func (r *mutationResolver) UpdateBudget(ctx context.Context, input model.UpdateB
 	}
 
 	// Check that user owns this budget; if not, this will fail.
-	_, err = database.GetBudget(ctx, r.Pool, user, budgetID)
+	existing, err := database.GetBudget(ctx, r.Pool, user, budgetID)
 	if err != nil {
 		return nil, fmt.Errorf("budget not found: %w", err)
 	}
 
 	// Persist budget
-	b, err := model.BudgetFromUpdateBudgetInput(budgetID, user, &input)
+	b, err := model.BudgetFromUpdateBudgetInput(existing, &input)
 	if err != nil {
 		return nil, err
 	}
@@ -59,23 +158,495 @@ func (r *mutationResolver) UpdateBudget(ctx context.Context, input model.UpdateB
 	return model.BudgetToBudgetResponse(b), nil
 }
 
+// DeleteBudget is the resolver for the deleteBudget field.
+func (r *mutationResolver) DeleteBudget(ctx context.Context, id string, reassignTo *string) (bool, error) {
+	user := ctxutil.GetUser(ctx)
+
+	budgetID, err := uuid.Parse(id)
+	if err != nil {
+		return false, fmt.Errorf("invalid budget ID: %w", err)
+	}
+
+	// Only the owner's budgets are found.
+	budget, err := database.GetBudget(ctx, r.Pool, user, budgetID)
+	if err != nil {
+		return false, err
+	}
+
+	var target *model1.Budget
+
+	if reassignTo != nil {
+		targetID, err := uuid.Parse(*reassignTo)
+		if err != nil {
+			return false, fmt.Errorf("invalid budget ID: %w", err)
+		}
+
+		if targetID == budgetID {
+			return false, fmt.Errorf("can't reassign expenditures to the deleted budget: %w",
+				yabaerrors.InvalidInputError{Input: *reassignTo})
+		}
+
+		if target, err = database.GetBudget(ctx, r.Pool, user, targetID); err != nil {
+			return false, err
+		}
+	}
+
+	if err = database.DeleteBudget(ctx, r.Pool, budget, target); err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
 // CreateExpenditures is the resolver for the createExpenditures field.
-func (r *mutationResolver) CreateExpenditures(ctx context.Context, input []*model.ExpenditureInput) (*bool, error) {
+func (r *mutationResolver) CreateExpenditures(ctx context.Context, input []*model.ExpenditureInput) (*model.ImportResult, error) {
 	user := ctxutil.GetUser(ctx)
 	expenditures, err := model.ExpendituresFromExpenditureInput(user, input)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := database.ImportExpenditures(ctx, r.Pool, expenditures)
+	if err != nil {
+		return nil, err
+	}
+
+	return model.ImportResultToImportResultResponse(result), nil
+}
+
+// UpdateExpenditure is the resolver for the updateExpenditure field.
+func (r *mutationResolver) UpdateExpenditure(ctx context.Context, id string, input model.ExpenditureInput) (*model.ExpenditureResponse, error) {
+	expenditureID, err := strconv.Atoi(id)
+	if err != nil {
+		return nil, fmt.Errorf("invalid expenditure ID: %w", err)
+	}
+
+	expenditure, err := database.GetExpenditure(ctx, r.Pool, expenditureID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = model.ApplyExpenditureInput(expenditure, &input); err != nil {
+		return nil, err
+	}
+
+	if err = database.UpdateExpenditure(ctx, r.Pool, expenditure); err != nil {
+		return nil, err
+	}
+
+	return model.ExpenditureToExpenditureResponse(expenditure), nil
+}
+
+// DeleteExpenditures is the resolver for the deleteExpenditures field.
+func (r *mutationResolver) DeleteExpenditures(ctx context.Context, ids []string) (int, error) {
+	expenditureIDs, err := parseExpenditureIDs(ids)
+	if err != nil {
+		return 0, err
+	}
+
+	deleted, err := database.DeleteExpenditures(ctx, r.Pool, expenditureIDs)
+	if err != nil {
+		return 0, err
+	}
+
+	return int(deleted), nil
+}
+
+// BulkUpdateExpenditures is the resolver for the bulkUpdateExpenditures field.
+func (r *mutationResolver) BulkUpdateExpenditures(ctx context.Context, filter model.ExpenditureFilter, set model.ExpenditurePatch, dryRun *bool) (*model.BulkUpdateResult, error) {
+	since, until, err := parseExpenditureDates(filter.Since, filter.Until)
+	if err != nil {
+		return nil, err
+	}
+
+	patch, err := model.ExpenditurePatchFromInput(&set)
+	if err != nil {
+		return nil, err
+	}
+
+	affected, changes, err := database.BulkUpdateExpenditures(ctx, r.Pool, &model1.ExpenditureFilter{
+		Search:        filter.Search,
+		Category:      filter.Category,
+		PaymentMethod: filter.PaymentMethod,
+		Source:        filter.Source,
+		Tags:          filter.Tags,
+		Since:         since,
+		Until:         until,
+	}, patch, dryRun != nil && *dryRun)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &model.BulkUpdateResult{Affected: affected, Preview: []*model.ExpenditureChange{}}
+	if dryRun != nil && *dryRun {
+		result.Preview = model.FieldChangesToExpenditureChanges(changes)
+	}
+
+	return result, nil
+}
+
+// SetExpenditureSplits is the resolver for the setExpenditureSplits field.
+func (r *mutationResolver) SetExpenditureSplits(ctx context.Context, id string, splits []*model.ExpenditureSplitInput) ([]*model.ExpenditureSplit, error) {
+	expenditureID, err := strconv.Atoi(id)
+	if err != nil {
+		return nil, fmt.Errorf("invalid expenditure ID: %w", err)
+	}
+
+	expenditureSplits := model.ExpenditureSplitsFromExpenditureSplitInput(splits)
+	if err = database.SetExpenditureSplits(ctx, r.Pool, expenditureID, expenditureSplits); err != nil {
+		return nil, err
+	}
+
+	return model.ExpenditureSplitsToExpenditureSplitResponses(expenditureSplits), nil
+}
+
+// LinkRefund is the resolver for the linkRefund field.
+func (r *mutationResolver) LinkRefund(ctx context.Context, refund string, original *string) (*model.ExpenditureResponse, error) {
+	refundID, err := strconv.Atoi(refund)
+	if err != nil {
+		return nil, fmt.Errorf("invalid expenditure ID: %w", err)
+	}
+
+	if original == nil {
+		err = database.UnlinkRefund(ctx, r.Pool, refundID)
+	} else {
+		originalID, parseErr := strconv.Atoi(*original)
+		if parseErr != nil {
+			return nil, fmt.Errorf("invalid expenditure ID: %w", parseErr)
+		}
+
+		err = database.LinkRefund(ctx, r.Pool, refundID, originalID)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	expenditure, err := database.GetExpenditure(ctx, r.Pool, refundID)
+	if err != nil {
+		return nil, err
+	}
+
+	return model.ExpenditureToExpenditureResponse(expenditure), nil
+}
+
+// PairTransfer is the resolver for the pairTransfer field.
+func (r *mutationResolver) PairTransfer(ctx context.Context, id string, other string) (*model.ExpenditureResponse, error) {
+	expenditureID, err := strconv.Atoi(id)
+	if err != nil {
+		return nil, fmt.Errorf("invalid expenditure ID: %w", err)
+	}
+
+	otherID, err := strconv.Atoi(other)
+	if err != nil {
+		return nil, fmt.Errorf("invalid expenditure ID: %w", err)
+	}
+
+	if err = database.PairTransfer(ctx, r.Pool, expenditureID, otherID); err != nil {
+		return nil, err
+	}
+
+	expenditure, err := database.GetExpenditure(ctx, r.Pool, expenditureID)
+	if err != nil {
+		return nil, err
+	}
+
+	return model.ExpenditureToExpenditureResponse(expenditure), nil
+}
+
+// UnpairTransfer is the resolver for the unpairTransfer field.
+func (r *mutationResolver) UnpairTransfer(ctx context.Context, id string) (*model.ExpenditureResponse, error) {
+	expenditureID, err := strconv.Atoi(id)
+	if err != nil {
+		return nil, fmt.Errorf("invalid expenditure ID: %w", err)
+	}
+
+	if err = database.UnpairTransfer(ctx, r.Pool, expenditureID); err != nil {
+		return nil, err
+	}
+
+	expenditure, err := database.GetExpenditure(ctx, r.Pool, expenditureID)
+	if err != nil {
+		return nil, err
+	}
+
+	return model.ExpenditureToExpenditureResponse(expenditure), nil
+}
+
+// AddAttachment is the resolver for the addAttachment field.
+func (r *mutationResolver) AddAttachment(ctx context.Context, expenditureID string, file graphql.Upload) (*model.Attachment, error) {
+	id, err := strconv.Atoi(expenditureID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid expenditure ID: %w", err)
+	}
+
+	saved, err := attachment.Save(ctx, r.Pool, r.Blobs, id, file.Filename, file.File)
+	if err != nil {
+		return nil, err
+	}
+
+	return model.AttachmentToAttachmentResponse(saved), nil
+}
+
+// DeleteAttachment is the resolver for the deleteAttachment field.
+func (r *mutationResolver) DeleteAttachment(ctx context.Context, id string) (bool, error) {
+	attachmentID, err := uuid.Parse(id)
+	if err != nil {
+		return false, fmt.Errorf("invalid attachment ID: %w", err)
+	}
+
+	if err = attachment.Delete(ctx, r.Pool, r.Blobs, attachmentID); err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+// ImportExpenditures is the resolver for the importExpenditures field.
+func (r *mutationResolver) ImportExpenditures(ctx context.Context, file graphql.Upload, mapping *model.CSVMappingInput) (*model.ImportResult, error) {
+	csvMapping, err := model.CSVMappingFromCSVMappingInput(mapping)
+	if err != nil {
+		return nil, err
+	}
+
+	method, err := model.PaymentMethodFromCSVMappingInput(mapping)
+	if err != nil {
+		return nil, err
+	}
+
+	records, err := importer.ParseCSV(file.File, csvMapping)
+	if err != nil {
+		return nil, err
+	}
+
+	expenditures, err := importer.ToExpenditures(ctx, r.Pool, records, file.Filename, method)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := database.ImportExpenditures(ctx, r.Pool, expenditures)
+	if err != nil {
+		return nil, err
+	}
+
+	return model.ImportResultToImportResultResponse(result), nil
+}
+
+// UndoImport is the resolver for the undoImport field.
+func (r *mutationResolver) UndoImport(ctx context.Context, id string) (int, error) {
+	batchID, err := uuid.Parse(id)
+	if err != nil {
+		return 0, fmt.Errorf("failed to parse UUID: %w", err)
+	}
+
+	deleted, err := attachment.UndoImport(ctx, r.Pool, r.Blobs, batchID)
+	if err != nil {
+		return 0, err
+	}
 
-	var success bool
+	return int(deleted), nil
+}
 
+// CreateCategorizationRule is the resolver for the createCategorizationRule field.
+func (r *mutationResolver) CreateCategorizationRule(ctx context.Context, input model.CategorizationRuleInput) (*model.CategorizationRule, error) {
+	rule, err := model.CategorizationRuleFromCategorizationRuleInput(&input)
 	if err != nil {
-		return &success, err
+		return nil, err
+	}
+
+	rule.ID = uuid.New()
+
+	if err = database.CreateCategorizationRule(ctx, r.Pool, rule); err != nil {
+		return nil, err
 	}
 
-	if err = database.PersistExpenditures(ctx, r.Pool, expenditures); err != nil {
-		return &success, err
+	created, err := database.GetCategorizationRule(ctx, r.Pool, rule.ID)
+	if err != nil {
+		return nil, err
 	}
 
-	success = true
-	return &success, nil
+	return model.CategorizationRuleToCategorizationRuleResponse(created), nil
+}
+
+// UpdateCategorizationRule is the resolver for the updateCategorizationRule field.
+func (r *mutationResolver) UpdateCategorizationRule(ctx context.Context, id string, input model.CategorizationRuleInput) (*model.CategorizationRule, error) {
+	rule, err := model.CategorizationRuleFromCategorizationRuleInput(&input)
+	if err != nil {
+		return nil, err
+	}
+
+	if rule.ID, err = uuid.Parse(id); err != nil {
+		return nil, fmt.Errorf("invalid categorization rule ID: %w", err)
+	}
+
+	if err = database.UpdateCategorizationRule(ctx, r.Pool, rule); err != nil {
+		return nil, err
+	}
+
+	updated, err := database.GetCategorizationRule(ctx, r.Pool, rule.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	return model.CategorizationRuleToCategorizationRuleResponse(updated), nil
+}
+
+// DeleteCategorizationRule is the resolver for the deleteCategorizationRule field.
+func (r *mutationResolver) DeleteCategorizationRule(ctx context.Context, id string) (bool, error) {
+	ruleID, err := uuid.Parse(id)
+	if err != nil {
+		return false, fmt.Errorf("invalid categorization rule ID: %w", err)
+	}
+
+	return database.DeleteCategorizationRule(ctx, r.Pool, ruleID)
+}
+
+// ApplyRules is the resolver for the applyRules field.
+func (r *mutationResolver) ApplyRules(ctx context.Context, since *string, until *string, dryRun *bool) ([]*model.ExpenditureChange, error) {
+	start := time.Unix(0, 0).Format(time.DateOnly)
+	if since != nil {
+		start = *since
+	}
+
+	end := time.Now().Format(time.DateOnly)
+	if until != nil {
+		end = *until
+	}
+
+	sinceTime, err := time.ParseInLocation(time.DateOnly, start, time.UTC)
+	if err != nil {
+		return nil, err
+	}
+
+	untilTime, err := time.ParseInLocation(time.DateOnly, end, time.UTC)
+	if err != nil {
+		return nil, err
+	}
+
+	changes, err := database.ApplyCategorizationRules(ctx, r.Pool, sinceTime, untilTime, dryRun != nil && *dryRun)
+	if err != nil {
+		return nil, err
+	}
+
+	return model.FieldChangesToExpenditureChanges(changes), nil
+}
+
+// MergeMerchants is the resolver for the mergeMerchants field.
+func (r *mutationResolver) MergeMerchants(ctx context.Context, from string, into string) (*model.Merchant, error) {
+	fromID, err := uuid.Parse(from)
+	if err != nil {
+		return nil, fmt.Errorf("invalid merchant ID: %w", err)
+	}
+
+	intoID, err := uuid.Parse(into)
+	if err != nil {
+		return nil, fmt.Errorf("invalid merchant ID: %w", err)
+	}
+
+	if err = database.MergeMerchants(ctx, r.Pool, fromID, intoID); err != nil {
+		return nil, err
+	}
+
+	merchant, err := database.GetMerchant(ctx, r.Pool, intoID)
+	if err != nil {
+		return nil, err
+	}
+
+	return model.MerchantToMerchantResponse(merchant), nil
+}
+
+// CreateTag is the resolver for the createTag field.
+func (r *mutationResolver) CreateTag(ctx context.Context, name string) (*model.Tag, error) {
+	tag, err := database.CreateTag(ctx, r.Pool, name)
+	if err != nil {
+		return nil, err
+	}
+
+	return model.TagToTagResponse(tag), nil
+}
+
+// RenameTag is the resolver for the renameTag field.
+func (r *mutationResolver) RenameTag(ctx context.Context, id string, name string) (*model.Tag, error) {
+	tagID, err := uuid.Parse(id)
+	if err != nil {
+		return nil, fmt.Errorf("invalid tag ID: %w", err)
+	}
+
+	tag, err := database.RenameTag(ctx, r.Pool, tagID, name)
+	if err != nil {
+		return nil, err
+	}
+
+	return model.TagToTagResponse(tag), nil
+}
+
+// DeleteTag is the resolver for the deleteTag field.
+func (r *mutationResolver) DeleteTag(ctx context.Context, id string) (bool, error) {
+	tagID, err := uuid.Parse(id)
+	if err != nil {
+		return false, fmt.Errorf("invalid tag ID: %w", err)
+	}
+
+	if err = database.DeleteTag(ctx, r.Pool, tagID); err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+// TagExpenditures is the resolver for the tagExpenditures field.
+func (r *mutationResolver) TagExpenditures(ctx context.Context, ids []string, tags []string) (int, error) {
+	expenditureIDs, err := parseExpenditureIDs(ids)
+	if err != nil {
+		return 0, err
+	}
+
+	added, err := database.TagExpenditures(ctx, r.Pool, expenditureIDs, tags)
+	if err != nil {
+		return 0, err
+	}
+
+	return int(added), nil
+}
+
+// UntagExpenditures is the resolver for the untagExpenditures field.
+func (r *mutationResolver) UntagExpenditures(ctx context.Context, ids []string, tags []string) (int, error) {
+	expenditureIDs, err := parseExpenditureIDs(ids)
+	if err != nil {
+		return 0, err
+	}
+
+	removed, err := database.UntagExpenditures(ctx, r.Pool, expenditureIDs, tags)
+	if err != nil {
+		return 0, err
+	}
+
+	return int(removed), nil
+}
+
+// DetectRecurringExpenditures is the resolver for the detectRecurringExpenditures field.
+func (r *mutationResolver) DetectRecurringExpenditures(ctx context.Context) ([]*model.RecurringExpenditure, error) {
+	series, err := database.DetectRecurringSeries(ctx, r.Pool)
+	if err != nil {
+		return nil, err
+	}
+
+	return model.RecurringSeriesToRecurringExpenditures(series), nil
+}
+
+// SetHomeCurrency is the resolver for the setHomeCurrency field.
+func (r *mutationResolver) SetHomeCurrency(ctx context.Context, currency string) (string, error) {
+	return database.SetHomeCurrency(ctx, r.Pool, currency)
+}
+
+// ImportExchangeRates is the resolver for the importExchangeRates field.
+func (r *mutationResolver) ImportExchangeRates(ctx context.Context, file graphql.Upload) (int, error) {
+	rates, err := currency.ParseRates(file.File)
+	if err != nil {
+		return 0, err
+	}
+
+	return database.SaveExchangeRates(ctx, r.Pool, rates)
 }
 
 // CreatePaymentMethod is the resolver for the createPaymentMethod field.
@@ -135,6 +706,11 @@ func (r *mutationResolver) DeletePaymentMethod(ctx context.Context, id string) (
 	return database.DeletePaymentMethod(ctx, r.Pool, paymentMethodID)
 }
 
+// Restore is the resolver for the restore field.
+func (r *mutationResolver) Restore(ctx context.Context, id string) (bool, error) {
+	return database.RestoreFromTrash(ctx, r.Pool, id)
+}
+
 // CreateRewardCard is the resolver for the createRewardCard field.
 func (r *mutationResolver) CreateRewardCard(ctx context.Context, input model.RewardCardInput) (*model.RewardCard, error) {
 	rewardCard := model.RewardCardFromRewardCardInput(input)
@@ -169,26 +745,73 @@ func (r *queryResolver) Budget(ctx context.Context, id string) (*model.BudgetRes
 }
 
 // Budgets is the resolver for the budgets field.
-func (r *queryResolver) Budgets(ctx context.Context, first *int) ([]*model.BudgetResponse, error) {
+func (r *queryResolver) Budgets(ctx context.Context, first *int, name *string) ([]*model.BudgetResponse, error) {
 	limit := 10
 	if first != nil {
 		limit = *first
 	}
 
-	user := ctxutil.GetUser(ctx)
-	b, err := database.GetBudgets(ctx, r.Pool, user, limit)
+	page, err := database.ListBudgetPage(ctx, r.Pool, name, limit, nil)
 	if err != nil {
 		return nil, err
 	}
 
-	out := make([]*model.BudgetResponse, len(b))
-	for i := range b {
-		out[i] = model.BudgetToBudgetResponse(b[i])
+	out := make([]*model.BudgetResponse, len(page.Budgets))
+	for i := range page.Budgets {
+		out[i] = model.BudgetToBudgetResponse(page.Budgets[i])
 	}
 
 	return out, nil
 }
 
+// BudgetsConnection is the resolver for the budgetsConnection field.
+func (r *queryResolver) BudgetsConnection(
+	ctx context.Context,
+	name *string,
+	first *int,
+	after *string,
+) (*model.BudgetConnection, error) {
+	pageSize := 10
+	if first != nil {
+		pageSize = *first
+	}
+
+	var cursor *model1.BudgetCursor
+	if after != nil {
+		var err error
+		if cursor, err = model1.ParseBudgetCursor(*after); err != nil {
+			return nil, err
+		}
+	}
+
+	page, err := database.ListBudgetPage(ctx, r.Pool, name, pageSize, cursor)
+	if err != nil {
+		return nil, fmt.Errorf("budgetsConnection: %w", err)
+	}
+
+	return model.BudgetPageToBudgetConnection(page), nil
+}
+
+// BudgetPeriod is the resolver for the budgetPeriod field.
+func (r *queryResolver) BudgetPeriod(ctx context.Context, budgetID string, date string) (*model.BudgetPeriod, error) {
+	id, err := uuid.Parse(budgetID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid budget ID: %w", err)
+	}
+
+	day, err := time.Parse(time.DateOnly, date)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse date: %w", err)
+	}
+
+	period, err := database.GetBudgetPeriod(ctx, r.Pool, id, day)
+	if err != nil {
+		return nil, err
+	}
+
+	return model.BudgetPeriodToBudgetPeriodResponse(period), nil
+}
+
 // Expenditures is the resolver for the expenditures field.
 func (r *queryResolver) Expenditures(
 	ctx context.Context,
@@ -196,19 +819,15 @@ func (r *queryResolver) Expenditures(
 	category *string,
 	paymentMethod *string,
 	source *string,
+	tags []string,
 	since *string,
 	until *string,
 	count *int,
 	offset *int,
 ) ([]*model.ExpenditureResponse, error) {
-	start := time.Unix(0, 0).Format(time.DateOnly)
-	if since != nil {
-		start = *since
-	}
-
-	end := time.Now().Format(time.DateOnly)
-	if until != nil {
-		end = *until
+	sinceTime, untilTime, err := parseExpenditureDates(since, until)
+	if err != nil {
+		return []*model.ExpenditureResponse{}, err
 	}
 
 	limit := 10
@@ -216,26 +835,92 @@ func (r *queryResolver) Expenditures(
 		limit = *count
 	}
 
-	sinceTime, err := time.ParseInLocation(time.DateOnly, start, time.UTC)
+	expenditures, err := database.ListExpenditures(ctx, r.Pool, filter, category, paymentMethod, source, tags, sinceTime, untilTime, &limit, offset)
 	if err != nil {
 		return []*model.ExpenditureResponse{}, err
 	}
 
-	untilTime, err := time.ParseInLocation(time.DateOnly, end, time.UTC)
+	return model.ExpendituresToExpenitureResponse(expenditures)
+}
+
+// ExpendituresConnection is the resolver for the expendituresConnection field.
+func (r *queryResolver) ExpendituresConnection(
+	ctx context.Context,
+	filter *string,
+	category *string,
+	paymentMethod *string,
+	source *string,
+	tags []string,
+	since *string,
+	until *string,
+	first *int,
+	after *string,
+	sort *model.ExpenditureSort,
+	direction *model.SortDirection,
+) (*model.ExpenditureConnection, error) {
+	sinceTime, untilTime, err := parseExpenditureDates(since, until)
 	if err != nil {
-		return []*model.ExpenditureResponse{}, err
+		return nil, err
+	}
+
+	pageSize := 10
+	if first != nil {
+		pageSize = *first
+	}
+
+	order := model1.ExpenditureSortDate
+	if sort != nil {
+		order = model.ConvertExpenditureSort(*sort)
 	}
 
-	expenditures, err := database.ListExpenditures(ctx, r.Pool, filter, category, paymentMethod, source, sinceTime, untilTime, &limit, offset)
+	var cursor *model1.ExpenditureCursor
+	if after != nil {
+		if cursor, err = model1.ParseExpenditureCursor(*after); err != nil {
+			return nil, err
+		}
+	}
+
+	page, err := database.ListExpenditurePage(ctx, r.Pool, &model1.ExpenditureFilter{
+		Search:        filter,
+		Category:      category,
+		PaymentMethod: paymentMethod,
+		Source:        source,
+		Tags:          tags,
+		Since:         sinceTime,
+		Until:         untilTime,
+	}, order, direction == nil || *direction == model.SortDirectionDesc, pageSize, cursor)
 	if err != nil {
-		return []*model.ExpenditureResponse{}, err
+		return nil, fmt.Errorf("expendituresConnection: %w", err)
 	}
 
-	return model.ExpendituresToExpenitureResponse(expenditures)
+	return model.ExpenditurePageToExpenditureConnection(page, order), nil
+}
+
+// ExportExpenditures is the resolver for the exportExpenditures field.
+func (r *queryResolver) ExportExpenditures(ctx context.Context, filter *model.ExpenditureFilter, format *model.ExportFormat) (string, error) {
+	exportFormat := model.ExportFormatCSV
+	if format != nil {
+		exportFormat = *format
+	}
+
+	// Check the filter here, since errors can't be reported once the download has started.
+	if filter != nil {
+		if _, _, err := parseExpenditureDates(filter.Since, filter.Until); err != nil {
+			return "", err
+		}
+
+		if filter.Search != nil {
+			if _, err := search.Parse(*filter.Search, ctxutil.GetUser(ctx)); err != nil {
+				return "", err
+			}
+		}
+	}
+
+	return model.ExportURLFromFilter(filter, exportFormat), nil
 }
 
 // AggregatedExpenditures is the resolver for the aggregatedExpenditures field.
-func (r *queryResolver) AggregatedExpenditures(ctx context.Context, since *string, until *string, span *model.Timespan, groupBy *model.GroupBy, aggregation *model.Aggregation) ([]*model.AggregatedExpendituresResponse, error) {
+func (r *queryResolver) AggregatedExpenditures(ctx context.Context, since *string, until *string, span *model.Timespan, groupBy *model.GroupBy, aggregation *model.Aggregation, refunds *model.RefundAttribution, includeTransfers *bool) ([]*model.AggregatedExpendituresResponse, error) {
 	var err error
 
 	start := time.Unix(0, 0)
@@ -269,8 +954,14 @@ func (r *queryResolver) AggregatedExpenditures(ctx context.Context, since *strin
 		agg = *aggregation
 	}
 
+	attribution := model.RefundAttributionOriginal
+	if refunds != nil {
+		attribution = *refunds
+	}
+
 	aggregateExpenditures, err := database.AggregateExpenditures(ctx, r.Pool, start, end,
-		model.ConvertTimespan(timespan), model.ConvertAggregation(agg), model.ConvertGroupBy(gb))
+		model.ConvertTimespan(timespan), model.ConvertAggregation(agg), model.ConvertGroupBy(gb),
+		model.ConvertRefundAttribution(attribution), includeTransfers != nil && *includeTransfers)
 	if err != nil {
 		return nil, fmt.Errorf("aggregatedExpenditures: %w", err)
 	}
@@ -278,6 +969,141 @@ func (r *queryResolver) AggregatedExpenditures(ctx context.Context, since *strin
 	return model.ExpenditureSummariesToAggregateExpenditures(aggregateExpenditures, timespan), nil
 }
 
+// ImportBatches is the resolver for the importBatches field.
+func (r *queryResolver) ImportBatches(ctx context.Context) ([]*model.ImportBatch, error) {
+	batches, err := database.ListImportBatches(ctx, r.Pool)
+	if err != nil {
+		return nil, err
+	}
+
+	out := make([]*model.ImportBatch, len(batches))
+	for i, batch := range batches {
+		out[i] = model.ImportBatchToImportBatchResponse(batch)
+	}
+
+	return out, nil
+}
+
+// ImportBatch is the resolver for the importBatch field.
+func (r *queryResolver) ImportBatch(ctx context.Context, id string) (*model.ImportBatch, error) {
+	batchID, err := uuid.Parse(id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse UUID: %w", err)
+	}
+
+	batch, err := database.GetImportBatch(ctx, r.Pool, batchID)
+	if err != nil {
+		return nil, err
+	}
+
+	return model.ImportBatchToImportBatchResponse(batch), nil
+}
+
+// CategorizationRules is the resolver for the categorizationRules field.
+func (r *queryResolver) CategorizationRules(ctx context.Context) ([]*model.CategorizationRule, error) {
+	categorizationRules, err := database.ListCategorizationRules(ctx, r.Pool)
+	if err != nil {
+		return nil, err
+	}
+
+	out := make([]*model.CategorizationRule, len(categorizationRules))
+	for i, rule := range categorizationRules {
+		out[i] = model.CategorizationRuleToCategorizationRuleResponse(rule)
+	}
+
+	return out, nil
+}
+
+// SuggestCategories is the resolver for the suggestCategories field.
+func (r *queryResolver) SuggestCategories(ctx context.Context, names []string) ([]*model.CategorySuggestion, error) {
+	categoryModel, err := database.GetCategoryModel(ctx, r.Pool)
+	if err != nil {
+		return nil, err
+	}
+
+	out := make([]*model.CategorySuggestion, len(names))
+	for i, name := range names {
+		out[i] = model.SuggestionToCategorySuggestion(categoryModel.Suggest(name))
+	}
+
+	return out, nil
+}
+
+// Merchants is the resolver for the merchants field.
+func (r *queryResolver) Merchants(ctx context.Context) ([]*model.Merchant, error) {
+	merchants, err := database.ListMerchants(ctx, r.Pool)
+	if err != nil {
+		return nil, err
+	}
+
+	return model.MerchantsToMerchantResponses(merchants), nil
+}
+
+// Tags is the resolver for the tags field.
+func (r *queryResolver) Tags(ctx context.Context) ([]*model.Tag, error) {
+	tags, err := database.ListTags(ctx, r.Pool)
+	if err != nil {
+		return nil, err
+	}
+
+	return model.TagsToTagResponses(tags), nil
+}
+
+// RecurringExpenditures is the resolver for the recurringExpenditures field.
+func (r *queryResolver) RecurringExpenditures(ctx context.Context) ([]*model.RecurringExpenditure, error) {
+	series, err := database.ListRecurringSeries(ctx, r.Pool)
+	if err != nil {
+		return nil, err
+	}
+
+	return model.RecurringSeriesToRecurringExpenditures(series), nil
+}
+
+// UpcomingCharges is the resolver for the upcomingCharges field.
+func (r *queryResolver) UpcomingCharges(ctx context.Context, days int) ([]*model.RecurringExpenditure, error) {
+	series, err := database.ListUpcomingCharges(ctx, r.Pool, days)
+	if err != nil {
+		return nil, err
+	}
+
+	return model.RecurringSeriesToRecurringExpenditures(series), nil
+}
+
+// HomeCurrency is the resolver for the homeCurrency field.
+func (r *queryResolver) HomeCurrency(ctx context.Context) (string, error) {
+	return database.GetHomeCurrency(ctx, r.Pool)
+}
+
+// ExchangeRates is the resolver for the exchangeRates field.
+func (r *queryResolver) ExchangeRates(ctx context.Context, base string, quote string) ([]*model.ExchangeRate, error) {
+	rates, err := database.ListExchangeRates(ctx, r.Pool, base, quote)
+	if err != nil {
+		return nil, err
+	}
+
+	return model.ExchangeRatesToExchangeRateResponses(rates), nil
+}
+
+// History is the resolver for the history field.
+func (r *queryResolver) History(ctx context.Context, entityID string) ([]*model.AuditEntry, error) {
+	entries, err := database.ListHistory(ctx, r.Pool, entityID)
+	if err != nil {
+		return nil, err
+	}
+
+	return model.AuditEntriesToAuditEntryResponses(entries), nil
+}
+
+// Trash is the resolver for the trash field.
+func (r *queryResolver) Trash(ctx context.Context) ([]*model.TrashItem, error) {
+	items, err := database.ListTrash(ctx, r.Pool)
+	if err != nil {
+		return nil, err
+	}
+
+	return model.TrashItemsToTrashItemResponses(items), nil
+}
+
 // PaymentMethods is the resolver for the paymentMethods field.
 func (r *queryResolver) PaymentMethods(ctx context.Context) ([]*model.PaymentMethod, error) {
 	paymentMethods, err := database.ListPaymentMethods(ctx, r.Pool)
@@ -308,11 +1134,21 @@ func (r *queryResolver) RewardCards(ctx context.Context, issuer *string, name *s
 	return out, nil
 }
 
+// ExpenditureResponse returns server.ExpenditureResponseResolver implementation.
+func (r *Resolver) ExpenditureResponse() server.ExpenditureResponseResolver {
+	return &expenditureResponseResolver{r}
+}
+
+// ImportBatch returns server.ImportBatchResolver implementation.
+func (r *Resolver) ImportBatch() server.ImportBatchResolver { return &importBatchResolver{r} }
+
 // Mutation returns server.MutationResolver implementation.
 func (r *Resolver) Mutation() server.MutationResolver { return &mutationResolver{r} }
 
 // Query returns server.QueryResolver implementation.
 func (r *Resolver) Query() server.QueryResolver { return &queryResolver{r} }
 
+type expenditureResponseResolver struct{ *Resolver }
+type importBatchResolver struct{ *Resolver }
 type mutationResolver struct{ *Resolver }
 type queryResolver struct{ *Resolver }
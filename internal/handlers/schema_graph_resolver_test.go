@@ -1,14 +1,21 @@
 package handlers_test
 
 import (
+	"errors"
+	"io"
+	"os"
 	"strconv"
+	"strings"
+	"sync"
 	"testing"
 	"time"
 	"yaba/graph/model"
 	"yaba/internal/ctxutil"
 	"yaba/internal/database"
 	"yaba/internal/handlers"
+	internalmodel "yaba/internal/model"
 
+	"github.com/99designs/gqlgen/graphql"
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/require"
 
@@ -23,7 +30,7 @@ func TestCreateEmptyBudget(t *testing.T) {
 	pool := helper.GetTestPool()
 	resolver := &handlers.Resolver{Pool: pool}
 
-	budgets, err := resolver.Query().Budgets(ctx, nil)
+	budgets, err := resolver.Query().Budgets(ctx, nil, nil)
 	require.NoError(t, err)
 	require.Empty(t, budgets)
 
@@ -35,7 +42,7 @@ func TestCreateEmptyBudget(t *testing.T) {
 
 	require.NoError(t, err)
 
-	budgets, err = resolver.Query().Budgets(ctx, nil)
+	budgets, err = resolver.Query().Budgets(ctx, nil, nil)
 	require.NoError(t, err)
 	require.Len(t, budgets, 1)
 
@@ -61,7 +68,7 @@ func TestCreateFullBudget(t *testing.T) {
 	resolver := &handlers.Resolver{Pool: pool}
 	limit := 10
 
-	budgets, err := resolver.Query().Budgets(ctx, &limit)
+	budgets, err := resolver.Query().Budgets(ctx, &limit, nil)
 	require.NoError(t, err)
 	require.Empty(t, budgets)
 
@@ -70,22 +77,22 @@ func TestCreateFullBudget(t *testing.T) {
 		Incomes: []*model.IncomeInput{
 			{
 				Source: "work",
-				Amount: 100_000.00,
+				Amount: money(100_000.00),
 			},
 		},
 		Expenses: []*model.ExpenseInput{
 			{
 				Category: "rent",
-				Amount:   2_000,
+				Amount:   money(2_000),
 			}, {
 				Category: "food",
-				Amount:   1_000,
+				Amount:   money(1_000),
 			}, {
 				Category: "entertainment",
-				Amount:   1_000,
+				Amount:   money(1_000),
 			}, {
 				Category: "savings",
-				Amount:   1_000,
+				Amount:   money(1_000),
 			},
 		},
 	})
@@ -98,7 +105,7 @@ func TestCreateFullBudget(t *testing.T) {
 	require.Len(t, b.Incomes, 1)
 	require.Len(t, b.Expenses, 4)
 
-	budgets, err = resolver.Query().Budgets(ctx, &limit)
+	budgets, err = resolver.Query().Budgets(ctx, &limit, nil)
 	require.NoError(t, err)
 	require.Len(t, budgets, 1)
 }
@@ -112,7 +119,7 @@ func TestUpdateBudget(t *testing.T) {
 	resolver := &handlers.Resolver{Pool: pool}
 	limit := 10
 
-	budgets, err := resolver.Query().Budgets(ctx, &limit)
+	budgets, err := resolver.Query().Budgets(ctx, &limit, nil)
 	require.NoError(t, err)
 	require.Empty(t, budgets)
 
@@ -121,22 +128,22 @@ func TestUpdateBudget(t *testing.T) {
 		Incomes: []*model.IncomeInput{
 			{
 				Source: "work",
-				Amount: 100_000.00,
+				Amount: money(100_000.00),
 			},
 		},
 		Expenses: []*model.ExpenseInput{
 			{
 				Category: "rent",
-				Amount:   2_000,
+				Amount:   money(2_000),
 			}, {
 				Category: "food",
-				Amount:   1_000,
+				Amount:   money(1_000),
 			}, {
 				Category: "entertainment",
-				Amount:   1_000,
+				Amount:   money(1_000),
 			}, {
 				Category: "savings",
-				Amount:   1_000,
+				Amount:   money(1_000),
 			},
 		},
 	})
@@ -155,23 +162,23 @@ func TestUpdateBudget(t *testing.T) {
 		Incomes: []*model.IncomeInput{
 			{
 				Source: "work",
-				Amount: 100_000.00,
+				Amount: money(100_000.00),
 			},
 			{
 				Source: "uber",
-				Amount: 20_000,
+				Amount: money(20_000),
 			},
 		},
 		Expenses: []*model.ExpenseInput{
 			{
 				Category: "rent",
-				Amount:   2_000,
+				Amount:   money(2_000),
 			}, {
 				Category: "food",
-				Amount:   1_000,
+				Amount:   money(1_000),
 			}, {
 				Category: "savings",
-				Amount:   1_000,
+				Amount:   money(1_000),
 			},
 		},
 	})
@@ -179,7 +186,7 @@ func TestUpdateBudget(t *testing.T) {
 	require.NoError(t, err)
 
 	// Check that the budget has been updated
-	budgets, err = resolver.Query().Budgets(ctx, &limit)
+	budgets, err = resolver.Query().Budgets(ctx, &limit, nil)
 	require.NoError(t, err)
 	require.Len(t, budgets, 1)
 
@@ -199,7 +206,7 @@ func TestUpdateFailsWrongOwner(t *testing.T) {
 	resolver := &handlers.Resolver{Pool: pool}
 	limit := 10
 
-	budgets, err := resolver.Query().Budgets(ctx, &limit)
+	budgets, err := resolver.Query().Budgets(ctx, &limit, nil)
 	require.NoError(t, err)
 	require.Empty(t, budgets)
 
@@ -234,6 +241,142 @@ func TestUpdateFailsWrongOwner(t *testing.T) {
 	require.Equal(t, "Budget V1", dbBudget.Name)
 }
 
+func TestBudgetsConnection(t *testing.T) {
+	t.Parallel()
+
+	user := uuid.New()
+	ctx := ctxutil.WithUser(t.Context(), user)
+	pool := helper.GetTestPool()
+	resolver := &handlers.Resolver{Pool: pool}
+
+	for _, name := range []string{"wedding", "monthly", "vacation", "monthly 2025"} {
+		_, err := resolver.Mutation().CreateBudget(ctx, model.NewBudgetInput{Name: name})
+		require.NoError(t, err)
+	}
+
+	var names []string
+	var after *string
+
+	for {
+		page, err := resolver.Query().BudgetsConnection(ctx, nil, ptr(3), after)
+		require.NoError(t, err)
+		require.Equal(t, 4, page.TotalCount)
+
+		for _, edge := range page.Edges {
+			names = append(names, *edge.Node.Name)
+		}
+
+		if !page.PageInfo.HasNextPage {
+			break
+		}
+
+		after = page.PageInfo.EndCursor
+	}
+
+	require.Equal(t, []string{"monthly", "monthly 2025", "vacation", "wedding"}, names)
+
+	budgets, err := resolver.Query().Budgets(ctx, nil, ptr("Month"))
+	require.NoError(t, err)
+	require.Len(t, budgets, 2)
+
+	_, err = resolver.Query().BudgetsConnection(ctx, nil, nil, ptr("not a cursor"))
+	require.Error(t, err)
+}
+
+func TestDeleteBudget(t *testing.T) {
+	t.Parallel()
+
+	user := uuid.New()
+	ctx := ctxutil.WithUser(t.Context(), user)
+	pool := helper.GetTestPool()
+	resolver := &handlers.Resolver{Pool: pool}
+
+	old, err := resolver.Mutation().CreateBudget(ctx, model.NewBudgetInput{
+		Name:     "old",
+		Expenses: []*model.ExpenseInput{{Category: "food", Amount: money(500)}},
+	})
+	require.NoError(t, err)
+
+	_, err = resolver.Mutation().CreateExpenditures(ctx, []*model.ExpenditureInput{
+		{Name: ptr("Groceries"), Amount: money(80), Date: "2024-05-01", BudgetCategory: ptr("food")},
+	})
+	require.NoError(t, err)
+
+	replacement, err := resolver.Mutation().CreateBudget(ctx, model.NewBudgetInput{
+		Name:     "new",
+		Expenses: []*model.ExpenseInput{{Category: "food", Amount: money(600)}},
+	})
+	require.NoError(t, err)
+
+	// Other users can't delete the budget.
+	other := ctxutil.WithUser(t.Context(), uuid.New())
+	_, err = resolver.Mutation().DeleteBudget(other, *old.ID, nil)
+	require.Error(t, err)
+
+	_, err = resolver.Mutation().DeleteBudget(ctx, *old.ID, old.ID)
+	require.Error(t, err)
+
+	deleted, err := resolver.Mutation().DeleteBudget(ctx, *old.ID, replacement.ID)
+	require.NoError(t, err)
+	require.True(t, deleted)
+
+	expenditures, err := database.ListExpenditures(ctx, pool, nil, nil, nil, nil, nil,
+		time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC), time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC), nil, nil)
+	require.NoError(t, err)
+	require.Len(t, expenditures, 1)
+	require.Equal(t, *replacement.Expenses[0].ID, expenditures[0].ExpenseID.String())
+
+	budgets, err := resolver.Query().Budgets(ctx, nil, nil)
+	require.NoError(t, err)
+	require.Len(t, budgets, 1)
+	require.Equal(t, "new", *budgets[0].Name)
+
+	// The budget is gone.
+	_, err = resolver.Mutation().DeleteBudget(ctx, *old.ID, nil)
+	require.Error(t, err)
+}
+
+func TestBudgetPeriod(t *testing.T) {
+	t.Parallel()
+
+	user := uuid.New()
+	ctx := ctxutil.WithUser(t.Context(), user)
+	pool := helper.GetTestPool()
+	resolver := &handlers.Resolver{Pool: pool}
+
+	budget, err := resolver.Mutation().CreateBudget(ctx, model.NewBudgetInput{
+		Name:        "biweekly",
+		Expenses:    []*model.ExpenseInput{{Category: "food", Amount: money(200), Rollover: ptr(true)}},
+		Period:      ptr(model.BudgetPeriodTypeBiweekly),
+		PeriodStart: ptr("2024-05-01"),
+	})
+	require.NoError(t, err)
+	require.Equal(t, model.BudgetPeriodTypeBiweekly, *budget.Period)
+	require.Equal(t, "2024-05-01", *budget.PeriodStart)
+
+	_, err = resolver.Mutation().CreateExpenditures(ctx, []*model.ExpenditureInput{
+		{Name: ptr("Groceries"), Amount: money(150), Date: "2024-05-03", BudgetCategory: ptr("food")},
+	})
+	require.NoError(t, err)
+
+	period, err := resolver.Query().BudgetPeriod(ctx, *budget.ID, "2024-05-20")
+	require.NoError(t, err)
+	require.Equal(t, "2024-05-15", period.Start)
+	require.Equal(t, "2024-05-28", period.End)
+	require.Len(t, period.Expenses, 1)
+	require.Equal(t, money(200), period.Expenses[0].HomeAllocated)
+	require.Equal(t, money(50), period.Expenses[0].RolledOver)
+	require.Equal(t, money(250), period.Expenses[0].Remaining)
+
+	// Period settings that aren't given are kept.
+	updated, err := resolver.Mutation().UpdateBudget(ctx, model.UpdateBudgetInput{ID: *budget.ID, Name: ptr("renamed")})
+	require.NoError(t, err)
+	require.Equal(t, model.BudgetPeriodTypeBiweekly, *updated.Period)
+
+	_, err = resolver.Query().BudgetPeriod(ctx, *budget.ID, "2024-04-30")
+	require.Error(t, err)
+}
+
 func TestExpenditures(t *testing.T) {
 	t.Parallel()
 
@@ -252,15 +395,13 @@ func TestExpenditures(t *testing.T) {
 	require.NoError(t, generator.PersistAll(ctx, pool))
 
 	expenditures, err := resolver.Query().
-		Expenditures(ctx, nil, nil, nil, nil, &startDateString, &endDateString, &limit, nil)
+		Expenditures(ctx, nil, nil, nil, nil, nil, &startDateString, &endDateString, &limit, nil)
 	require.NoError(t, err)
 	require.Len(t, expenditures, limit-1)
 
 	for i, expenditure := range expenditures {
 		require.Equal(t, generator.Expenditures[i].Name, *expenditure.Name)
-		amount, err := strconv.ParseFloat(*expenditure.Amount, 64)
-		require.NoError(t, err)
-		require.InDelta(t, generator.Expenditures[i].Amount, amount, .009)
+		require.Equal(t, generator.Expenditures[i].Amount, *expenditure.Amount)
 		require.Equal(t, time.Now().UTC().Format(time.DateOnly), *expenditure.Created)
 		require.Equal(t, generator.Expenditures[i].Date.Format(time.DateOnly), *expenditure.Date)
 		require.Equal(t, generator.Expenditures[i].Comment, *expenditure.Comment)
@@ -272,11 +413,69 @@ func TestExpenditures(t *testing.T) {
 	}
 
 	// nil range should return everything
-	expenditures, err = resolver.Query().Expenditures(ctx, nil, nil, nil, nil, nil, nil, nil, nil)
+	expenditures, err = resolver.Query().Expenditures(ctx, nil, nil, nil, nil, nil, nil, nil, nil, nil)
 	require.NoError(t, err)
 	require.Len(t, expenditures, 10)
 }
 
+func TestExpendituresConnection(t *testing.T) {
+	t.Parallel()
+
+	user := uuid.New()
+	ctx := ctxutil.WithUser(t.Context(), user)
+	pool := helper.GetTestPool()
+	resolver := &handlers.Resolver{Pool: pool}
+
+	_, err := resolver.Mutation().CreateExpenditures(ctx, []*model.ExpenditureInput{
+		{Name: ptr("Groceries"), Amount: money(80), Date: "2024-05-01"},
+		{Name: ptr("Coffee"), Amount: money(5), Date: "2024-05-02"},
+		{Name: ptr("Rent"), Amount: money(1500), Date: "2024-05-01"},
+		{Name: ptr("Books"), Amount: money(30), Date: "2024-05-03"},
+		{Name: ptr("Lunch"), Amount: money(15), Date: "2024-05-02"},
+	})
+	require.NoError(t, err)
+
+	since, until := "2024-05-01", "2024-05-31"
+	sort, direction := ptr(model.ExpenditureSortAmount), ptr(model.SortDirectionAsc)
+
+	var names []string
+	var after *string
+
+	for {
+		page, err := resolver.Query().ExpendituresConnection(ctx, nil, nil, nil, nil, nil, &since, &until, ptr(2),
+			after, sort, direction)
+		require.NoError(t, err)
+		require.Equal(t, 5, page.TotalCount)
+
+		for _, edge := range page.Edges {
+			names = append(names, *edge.Node.Name)
+		}
+
+		if !page.PageInfo.HasNextPage {
+			break
+		}
+
+		after = page.PageInfo.EndCursor
+	}
+
+	require.Equal(t, []string{"Coffee", "Lunch", "Books", "Groceries", "Rent"}, names)
+
+	// Newest first by default.
+	page, err := resolver.Query().ExpendituresConnection(ctx, nil, nil, nil, nil, nil, &since, &until, ptr(1),
+		nil, nil, nil)
+	require.NoError(t, err)
+	require.Equal(t, "Books", *page.Edges[0].Node.Name)
+
+	// Cursors only continue the sort they came from.
+	_, err = resolver.Query().ExpendituresConnection(ctx, nil, nil, nil, nil, nil, &since, &until, ptr(1),
+		page.PageInfo.EndCursor, sort, nil)
+	require.Error(t, err)
+
+	_, err = resolver.Query().ExpendituresConnection(ctx, nil, nil, nil, nil, nil, &since, &until, ptr(1),
+		ptr("not a cursor"), nil, nil)
+	require.Error(t, err)
+}
+
 func TestAggregateExpenditures(t *testing.T) {
 	t.Parallel()
 
@@ -296,7 +495,7 @@ func TestAggregateExpenditures(t *testing.T) {
 	)
 	require.NoError(t, err)
 
-	aggregate, err := resolver.Query().AggregatedExpenditures(ctx, nil, nil, nil, nil, nil)
+	aggregate, err := resolver.Query().AggregatedExpenditures(ctx, nil, nil, nil, nil, nil, nil, nil)
 	require.NoError(t, err)
 	require.Len(t, aggregate, 33)
 }
@@ -317,7 +516,7 @@ func TestCreateExpenditures(t *testing.T) {
 		inputs := []*model.ExpenditureInput{
 			{
 				Name:           ptr("Expense 1"),
-				Amount:         100.50,
+				Amount:         money(100.50),
 				Date:           "2024-03-20",
 				Method:         ptr(methods[0].ID.String()),
 				BudgetCategory: ptr("groceries"),
@@ -325,7 +524,7 @@ func TestCreateExpenditures(t *testing.T) {
 			},
 			{
 				Name:           ptr("Expense 2"),
-				Amount:         50.25,
+				Amount:         money(50.25),
 				Date:           "2024-03-21",
 				Method:         ptr(methods[1].ID.String()),
 				BudgetCategory: ptr("entertainment"),
@@ -333,9 +532,11 @@ func TestCreateExpenditures(t *testing.T) {
 			},
 		}
 
-		success, err := resolver.Mutation().CreateExpenditures(ctx, inputs)
+		result, err := resolver.Mutation().CreateExpenditures(ctx, inputs)
 		require.NoError(t, err)
-		require.True(t, *success)
+		require.Equal(t, 2, result.Inserted)
+		require.Zero(t, result.Skipped)
+		require.Empty(t, result.PossibleDuplicates)
 
 		startDate, _ := time.ParseInLocation(time.DateOnly, "2024-03-20", time.UTC)
 		endDate, _ := time.ParseInLocation(time.DateOnly, "2024-03-21", time.UTC)
@@ -348,6 +549,7 @@ func TestCreateExpenditures(t *testing.T) {
 			nil,
 			nil,
 			nil,
+			nil,
 			startDate,
 			endDate,
 			&limit,
@@ -358,17 +560,39 @@ func TestCreateExpenditures(t *testing.T) {
 
 		// Verify second expenditure (comes first due to descending order)
 		require.Equal(t, *inputs[1].Name, expenditures[0].Name)
-		require.InDelta(t, inputs[1].Amount, expenditures[0].Amount, 0.001)
+		require.InDelta(t, inputs[1].Amount.Float64(), expenditures[0].Amount.Float64(), 0.001)
 		require.Equal(t, *inputs[1].BudgetCategory, expenditures[0].BudgetCategory)
 		require.Equal(t, methods[1].ID, expenditures[0].Method)
 		require.Equal(t, *inputs[1].Comment, expenditures[0].Comment)
 
 		// Verify first expenditure (comes second due to descending order)
 		require.Equal(t, *inputs[0].Name, expenditures[1].Name)
-		require.InDelta(t, inputs[0].Amount, expenditures[1].Amount, 0.001)
+		require.InDelta(t, inputs[0].Amount.Float64(), expenditures[1].Amount.Float64(), 0.001)
 		require.Equal(t, *inputs[0].BudgetCategory, expenditures[1].BudgetCategory)
 		require.Equal(t, methods[0].ID, expenditures[1].Method)
 		require.Equal(t, *inputs[0].Comment, expenditures[1].Comment)
+
+		// Submitting the same expenditures again is a no-op
+		result, err = resolver.Mutation().CreateExpenditures(ctx, inputs)
+		require.NoError(t, err)
+		require.Equal(t, &model.ImportResult{
+			Skipped:            2,
+			PossibleDuplicates: []*model.PossibleDuplicate{},
+		}, result)
+
+		// The same charge under a different name a day later might be a duplicate
+		result, err = resolver.Mutation().CreateExpenditures(ctx, []*model.ExpenditureInput{{
+			Name:   ptr("EXPENSE #1"),
+			Amount: money(100.50),
+			Date:   "2024-03-21",
+			Method: ptr(methods[0].ID.String()),
+		}})
+		require.NoError(t, err)
+		require.Equal(t, 1, result.Inserted)
+		require.Len(t, result.PossibleDuplicates, 1)
+		require.Equal(t, "EXPENSE #1", *result.PossibleDuplicates[0].Expenditure.Name)
+		require.Len(t, result.PossibleDuplicates[0].Matches, 1)
+		require.Equal(t, strconv.Itoa(expenditures[1].ID), *result.PossibleDuplicates[0].Matches[0].ID)
 	})
 
 	t.Run("invalid date format", func(t *testing.T) {
@@ -376,23 +600,23 @@ func TestCreateExpenditures(t *testing.T) {
 
 		inputs := []*model.ExpenditureInput{{
 			Name:           ptr("Invalid Date"),
-			Amount:         100.50,
+			Amount:         money(100.50),
 			Date:           "03-20-2024", // Wrong format
 			Method:         ptr("credit"),
 			BudgetCategory: ptr("groceries"),
 		}}
 
-		success, err := resolver.Mutation().CreateExpenditures(ctx, inputs)
+		result, err := resolver.Mutation().CreateExpenditures(ctx, inputs)
 		require.Error(t, err)
-		require.False(t, *success)
+		require.Nil(t, result)
 	})
 
 	t.Run("empty input array", func(t *testing.T) {
 		t.Parallel()
 
-		success, err := resolver.Mutation().CreateExpenditures(ctx, []*model.ExpenditureInput{})
+		result, err := resolver.Mutation().CreateExpenditures(ctx, []*model.ExpenditureInput{})
 		require.NoError(t, err)
-		require.True(t, *success)
+		require.Zero(t, result.Inserted)
 	})
 
 	t.Run("missing required fields", func(t *testing.T) {
@@ -400,16 +624,681 @@ func TestCreateExpenditures(t *testing.T) {
 
 		inputs := []*model.ExpenditureInput{{
 			Name:   ptr("Missing Fields"),
-			Amount: 100.50,
+			Amount: money(100.50),
 			// Missing Date
 			Method:         ptr("credit"),
 			BudgetCategory: ptr("groceries"),
 		}}
 
-		success, err := resolver.Mutation().CreateExpenditures(ctx, inputs)
+		result, err := resolver.Mutation().CreateExpenditures(ctx, inputs)
 		require.Error(t, err)
-		require.False(t, *success)
+		require.Nil(t, result)
+	})
+}
+
+func TestUpdateAndDeleteExpenditures(t *testing.T) {
+	t.Parallel()
+
+	user := uuid.New()
+	ctx := ctxutil.WithUser(t.Context(), user)
+	pool := helper.GetTestPool()
+	resolver := &handlers.Resolver{Pool: pool}
+
+	_, err := resolver.Mutation().CreateExpenditures(ctx, []*model.ExpenditureInput{
+		{
+			Name:           ptr("Expense 1"),
+			Amount:         money(100.50),
+			Date:           "2024-03-20",
+			BudgetCategory: ptr("groceries"),
+			Source:         ptr("statement.csv"),
+		},
+		{
+			Name:   ptr("Expense 2"),
+			Amount: money(50.25),
+			Date:   "2024-03-21",
+		},
+	})
+	require.NoError(t, err)
+
+	since, until := "2024-03-20", "2024-03-21"
+	expenditures, err := resolver.Query().
+		Expenditures(ctx, nil, nil, nil, nil, nil, &since, &until, nil, nil)
+	require.NoError(t, err)
+	require.Len(t, expenditures, 2)
+
+	updated, err := resolver.Mutation().UpdateExpenditure(ctx, *expenditures[1].ID, model.ExpenditureInput{
+		Date:    "2024-03-19",
+		Amount:  money(10.5),
+		Comment: ptr("typo"),
 	})
+	require.NoError(t, err)
+	require.Equal(t, "10.50", updated.Amount.String())
+	require.Equal(t, "2024-03-19", *updated.Date)
+	require.Equal(t, "typo", *updated.Comment)
+	// Unset fields are left alone
+	require.Equal(t, "Expense 1", *updated.Name)
+	require.Equal(t, "groceries", *updated.BudgetCategory)
+	require.Equal(t, "statement.csv", *updated.Source)
+
+	_, err = resolver.Mutation().UpdateExpenditure(ctx, "not a number", model.ExpenditureInput{Date: since})
+	require.ErrorContains(t, err, "invalid expenditure ID")
+
+	_, err = resolver.Mutation().DeleteExpenditures(ctx, []string{"nope"})
+	require.ErrorContains(t, err, "invalid expenditure ID")
+
+	deleted, err := resolver.Mutation().DeleteExpenditures(ctx, []string{*expenditures[0].ID, *expenditures[1].ID})
+	require.NoError(t, err)
+	require.Equal(t, 2, deleted)
+
+	expenditures, err = resolver.Query().Expenditures(ctx, nil, nil, nil, nil, nil, nil, nil, nil, nil)
+	require.NoError(t, err)
+	require.Empty(t, expenditures)
+}
+
+func TestImportExpenditures(t *testing.T) {
+	t.Parallel()
+
+	user := uuid.New()
+	ctx := ctxutil.WithUser(t.Context(), user)
+	pool := helper.GetTestPool()
+	resolver := &handlers.Resolver{Pool: pool}
+
+	for _, name := range []string{"debit", "credit", "cash"} {
+		require.NoError(t, database.CreatePaymentMethod(ctx, pool,
+			&internalmodel.PaymentMethod{ID: uuid.New(), DisplayName: name}))
+	}
+
+	f, err := os.Open("testdata/spend.csv")
+	require.NoError(t, err)
+
+	defer func() { _ = f.Close() }()
+
+	result, err := resolver.Mutation().ImportExpenditures(ctx, graphql.Upload{
+		File:     f,
+		Filename: "spend.csv",
+	}, nil)
+	require.NoError(t, err)
+	require.Equal(t, 3, result.Inserted)
+
+	// Importing the same file again skips every row
+	_, err = f.Seek(0, io.SeekStart)
+	require.NoError(t, err)
+
+	result, err = resolver.Mutation().ImportExpenditures(ctx, graphql.Upload{
+		File:     f,
+		Filename: "spend.csv",
+	}, nil)
+	require.NoError(t, err)
+	require.Zero(t, result.Inserted)
+	require.Equal(t, 3, result.Skipped)
+
+	source := "spend.csv"
+	expenditures, err := resolver.Query().Expenditures(ctx, nil, nil, nil, &source, nil, nil, nil, nil, nil)
+	require.NoError(t, err)
+	require.Len(t, expenditures, 3)
+
+	// A file that isn't a CSV statement is rejected
+	f, err = os.Open("testdata/file.txt")
+	require.NoError(t, err)
+
+	defer func() { _ = f.Close() }()
+
+	result, err = resolver.Mutation().ImportExpenditures(ctx, graphql.Upload{
+		File:     f,
+		Filename: "file.txt",
+	}, &model.CSVMappingInput{Delimiter: ptr(";")})
+	require.Error(t, err)
+	require.Nil(t, result)
+}
+
+func TestImportBatchesAndUndo(t *testing.T) {
+	t.Parallel()
+
+	user := uuid.New()
+	ctx := ctxutil.WithUser(t.Context(), user)
+	pool := helper.GetTestPool()
+	resolver := &handlers.Resolver{Pool: pool}
+
+	result, err := resolver.Mutation().CreateExpenditures(ctx, []*model.ExpenditureInput{
+		{Name: ptr("rent"), Amount: money(1500), Date: "2024-04-01", Source: ptr("manual")},
+		{Name: ptr("hydro"), Amount: money(80), Date: "2024-04-05", Source: ptr("manual")},
+	})
+	require.NoError(t, err)
+	require.Equal(t, "manual", result.Batch.Source)
+	require.Equal(t, 2, result.Batch.RowCount)
+	require.Equal(t, "2024-04-01", *result.Batch.StartDate)
+	require.Equal(t, "2024-04-05", *result.Batch.EndDate)
+
+	batches, err := resolver.Query().ImportBatches(ctx)
+	require.NoError(t, err)
+	require.Len(t, batches, 1)
+	require.Equal(t, result.Batch.ID, batches[0].ID)
+
+	batch, err := resolver.Query().ImportBatch(ctx, result.Batch.ID)
+	require.NoError(t, err)
+
+	expenditures, err := resolver.ImportBatch().Expenditures(ctx, batch)
+	require.NoError(t, err)
+	require.Len(t, expenditures, 2)
+	require.Equal(t, "hydro", *expenditures[0].Name)
+
+	_, err = resolver.Query().ImportBatch(ctx, "not a uuid")
+	require.Error(t, err)
+
+	deleted, err := resolver.Mutation().UndoImport(ctx, result.Batch.ID)
+	require.NoError(t, err)
+	require.Equal(t, 2, deleted)
+
+	batches, err = resolver.Query().ImportBatches(ctx)
+	require.NoError(t, err)
+	require.Empty(t, batches)
+
+	_, err = resolver.Mutation().UndoImport(ctx, result.Batch.ID)
+	require.ErrorContains(t, err, "no such element")
+}
+
+func TestCategorizationRules(t *testing.T) {
+	t.Parallel()
+
+	user := uuid.New()
+	ctx := ctxutil.WithUser(t.Context(), user)
+	pool := helper.GetTestPool()
+	resolver := &handlers.Resolver{Pool: pool}
+
+	rule, err := resolver.Mutation().CreateCategorizationRule(ctx, model.CategorizationRuleInput{
+		Pattern:        "spotify",
+		BudgetCategory: ptr("Subscriptions"),
+	})
+	require.NoError(t, err)
+	require.Equal(t, model.MatchTypeContains, rule.MatchType)
+	require.Equal(t, "Subscriptions", *rule.BudgetCategory)
+	require.Nil(t, rule.PaymentMethod)
+
+	_, err = resolver.Mutation().CreateCategorizationRule(ctx, model.CategorizationRuleInput{Pattern: "spotify"})
+	require.ErrorContains(t, err, "rule must assign a category or payment method")
+
+	rule, err = resolver.Mutation().UpdateCategorizationRule(ctx, rule.ID, model.CategorizationRuleInput{
+		MatchType:      ptr(model.MatchTypeStartsWith),
+		Pattern:        "spotify",
+		BudgetCategory: ptr("Music"),
+	})
+	require.NoError(t, err)
+	require.Equal(t, model.MatchTypeStartsWith, rule.MatchType)
+
+	_, err = resolver.Mutation().CreateExpenditures(ctx, []*model.ExpenditureInput{
+		{Name: ptr("SPOTIFY P1234"), Amount: money(11.99), Date: "2024-09-01", BudgetCategory: ptr("Fun")},
+	})
+	require.NoError(t, err)
+
+	changes, err := resolver.Mutation().ApplyRules(ctx, ptr("2024-09-01"), ptr("2024-09-30"), ptr(true))
+	require.NoError(t, err)
+	require.Len(t, changes, 1)
+	require.Equal(t, "budget_category", changes[0].Field)
+	require.Equal(t, "Fun", changes[0].Before)
+	require.Equal(t, "Music", changes[0].After)
+
+	rules, err := resolver.Query().CategorizationRules(ctx)
+	require.NoError(t, err)
+	require.Equal(t, []*model.CategorizationRule{rule}, rules)
+
+	deleted, err := resolver.Mutation().DeleteCategorizationRule(ctx, rule.ID)
+	require.NoError(t, err)
+	require.True(t, deleted)
+}
+
+func TestSuggestCategories(t *testing.T) {
+	t.Parallel()
+
+	user := uuid.New()
+	ctx := ctxutil.WithUser(t.Context(), user)
+	pool := helper.GetTestPool()
+	resolver := &handlers.Resolver{Pool: pool}
+
+	_, err := resolver.Mutation().CreateExpenditures(ctx, []*model.ExpenditureInput{
+		{Name: ptr("Pizza Pizza"), Amount: money(20), Date: "2024-10-01", BudgetCategory: ptr("Restaurants"),
+			RewardCategory: ptr("restaurant")},
+		{Name: ptr("Pizza Nova"), Amount: money(25), Date: "2024-10-02", BudgetCategory: ptr("Restaurants")},
+		{Name: ptr("Hydro One"), Amount: money(80), Date: "2024-10-03", BudgetCategory: ptr("Utilities")},
+	})
+	require.NoError(t, err)
+
+	suggestions, err := resolver.Query().SuggestCategories(ctx, []string{"PIZZA HUT", "Unknown"})
+	require.NoError(t, err)
+	require.Len(t, suggestions, 2)
+
+	require.Equal(t, "PIZZA HUT", suggestions[0].Name)
+	require.Equal(t, "restaurants", *suggestions[0].BudgetCategory)
+	require.Greater(t, suggestions[0].BudgetCategoryConfidence, 0.5)
+	require.Equal(t, "restaurant", *suggestions[0].RewardCategory)
+
+	require.Equal(t, &model.CategorySuggestion{Name: "Unknown"}, suggestions[1])
+}
+
+func TestMerchants(t *testing.T) {
+	t.Parallel()
+
+	user := uuid.New()
+	ctx := ctxutil.WithUser(t.Context(), user)
+	pool := helper.GetTestPool()
+	resolver := &handlers.Resolver{Pool: pool}
+
+	result, err := resolver.Mutation().CreateExpenditures(ctx, []*model.ExpenditureInput{
+		{Name: ptr("TST* PIZZA NOVA 0042"), Amount: money(20), Date: "2024-10-01"},
+		{Name: ptr("Pizza Nova Online"), Amount: money(25), Date: "2024-10-02"},
+	})
+	require.NoError(t, err)
+	require.Equal(t, 2, result.Inserted)
+
+	merchants, err := resolver.Query().Merchants(ctx)
+	require.NoError(t, err)
+	require.Len(t, merchants, 2)
+	require.Equal(t, "Pizza Nova", merchants[0].Name)
+
+	expenditures, err := resolver.Query().Expenditures(ctx, nil, nil, nil, nil, nil, nil, nil, nil, nil)
+	require.NoError(t, err)
+	require.Len(t, expenditures, 2)
+
+	for _, e := range expenditures {
+		require.NotNil(t, e.MerchantID)
+	}
+
+	_, err = resolver.Mutation().MergeMerchants(ctx, "not-a-uuid", merchants[0].ID)
+	require.Error(t, err)
+
+	merged, err := resolver.Mutation().MergeMerchants(ctx, merchants[1].ID, merchants[0].ID)
+	require.NoError(t, err)
+	require.Equal(t, []string{"PIZZA NOVA", "PIZZA NOVA ONLINE"}, merged.Aliases)
+
+	merchants, err = resolver.Query().Merchants(ctx)
+	require.NoError(t, err)
+	require.Len(t, merchants, 1)
+}
+
+func TestSetExpenditureSplits(t *testing.T) {
+	t.Parallel()
+
+	user := uuid.New()
+	ctx := ctxutil.WithUser(t.Context(), user)
+	pool := helper.GetTestPool()
+	resolver := &handlers.Resolver{Pool: pool}
+
+	_, err := resolver.Mutation().CreateExpenditures(ctx, []*model.ExpenditureInput{
+		{Name: ptr("Costco"), Amount: money(90), Date: "2024-10-01", BudgetCategory: ptr("Groceries")},
+	})
+	require.NoError(t, err)
+
+	expenditures, err := resolver.Query().Expenditures(ctx, nil, nil, nil, nil, nil, nil, nil, nil, nil)
+	require.NoError(t, err)
+	require.Len(t, expenditures, 1)
+
+	_, err = resolver.Mutation().SetExpenditureSplits(ctx, *expenditures[0].ID, []*model.ExpenditureSplitInput{
+		{BudgetCategory: "Groceries", Amount: money(50)},
+	})
+	require.Error(t, err)
+
+	splits, err := resolver.Mutation().SetExpenditureSplits(ctx, *expenditures[0].ID, []*model.ExpenditureSplitInput{
+		{BudgetCategory: "Groceries", Amount: money(50)},
+		{BudgetCategory: "Clothing", Amount: money(40)},
+	})
+	require.NoError(t, err)
+	require.Len(t, splits, 2)
+
+	stored, err := resolver.ExpenditureResponse().Splits(ctx, expenditures[0])
+	require.NoError(t, err)
+	require.Equal(t, splits, stored)
+
+	stored, err = resolver.ExpenditureResponse().Splits(handlers.WithExpenditureLoaders(ctx, pool), expenditures[0])
+	require.NoError(t, err)
+	require.Equal(t, splits, stored)
+}
+
+func TestLinkRefund(t *testing.T) {
+	t.Parallel()
+
+	user := uuid.New()
+	ctx := ctxutil.WithUser(t.Context(), user)
+	pool := helper.GetTestPool()
+	resolver := &handlers.Resolver{Pool: pool}
+
+	_, err := resolver.Mutation().CreateExpenditures(ctx, []*model.ExpenditureInput{
+		{Name: ptr("IKEA"), Amount: money(300), Date: "2024-10-01"},
+		{Name: ptr("IKEA"), Amount: money(-300), Date: "2024-10-05"},
+	})
+	require.NoError(t, err)
+
+	expenditures, err := resolver.Query().Expenditures(ctx, nil, nil, nil, nil, nil, nil, nil, nil, nil)
+	require.NoError(t, err)
+	require.Len(t, expenditures, 2)
+
+	purchase, refund := expenditures[0], expenditures[1]
+	if *purchase.Amount < 0 {
+		purchase, refund = refund, purchase
+	}
+
+	require.Equal(t, purchase.ID, refund.RefundOf)
+
+	unlinked, err := resolver.Mutation().LinkRefund(ctx, *refund.ID, nil)
+	require.NoError(t, err)
+	require.Nil(t, unlinked.RefundOf)
+
+	linked, err := resolver.Mutation().LinkRefund(ctx, *refund.ID, purchase.ID)
+	require.NoError(t, err)
+	require.Equal(t, purchase.ID, linked.RefundOf)
+
+	_, err = resolver.Mutation().LinkRefund(ctx, *purchase.ID, refund.ID)
+	require.Error(t, err)
+}
+
+func TestPairTransfer(t *testing.T) {
+	t.Parallel()
+
+	user := uuid.New()
+	ctx := ctxutil.WithUser(t.Context(), user)
+	pool := helper.GetTestPool()
+	resolver := &handlers.Resolver{Pool: pool}
+	chequing, card := uuid.NewString(), uuid.NewString()
+
+	_, err := resolver.Mutation().CreateExpenditures(ctx, []*model.ExpenditureInput{
+		{Name: ptr("Groceries"), Amount: money(50), Date: "2024-10-01", Method: &card},
+		{Name: ptr("Payment"), Amount: money(-200), Date: "2024-10-02", Method: &card},
+		{Name: ptr("Visa"), Amount: money(200), Date: "2024-10-01", Method: &chequing},
+	})
+	require.NoError(t, err)
+
+	expenditures, err := resolver.Query().Expenditures(ctx, nil, nil, nil, nil, nil, nil, nil, nil, nil)
+	require.NoError(t, err)
+	require.Len(t, expenditures, 3)
+
+	ids := make(map[string]string)
+	for _, e := range expenditures {
+		require.Equal(t, model.KindExpense, *e.Kind)
+		ids[*e.Name] = *e.ID
+	}
+
+	paired, err := resolver.Mutation().PairTransfer(ctx, ids["Visa"], ids["Payment"])
+	require.NoError(t, err)
+	require.Equal(t, model.KindTransfer, *paired.Kind)
+	require.Equal(t, ids["Payment"], *paired.TransferID)
+
+	since, until := "2024-10-01", "2024-10-31"
+	aggregate, err := resolver.Query().AggregatedExpenditures(ctx, &since, &until, ptr(model.TimespanMonth),
+		nil, nil, nil, nil)
+	require.NoError(t, err)
+	require.Len(t, aggregate, 1)
+	require.InDelta(t, 50, aggregate[0].Amount.Float64(), 0.001)
+
+	aggregate, err = resolver.Query().AggregatedExpenditures(ctx, &since, &until, ptr(model.TimespanMonth),
+		nil, nil, nil, ptr(true))
+	require.NoError(t, err)
+	require.InDelta(t, 50, aggregate[0].Amount.Float64(), 0.001)
+
+	unpaired, err := resolver.Mutation().UnpairTransfer(ctx, ids["Payment"])
+	require.NoError(t, err)
+	require.Nil(t, unpaired.TransferID)
+}
+
+func TestRecurringExpenditures(t *testing.T) {
+	t.Parallel()
+
+	user := uuid.New()
+	ctx := ctxutil.WithUser(t.Context(), user)
+	pool := helper.GetTestPool()
+	resolver := &handlers.Resolver{Pool: pool}
+	today := time.Now().UTC()
+
+	input := make([]*model.ExpenditureInput, 3)
+	for i := range input {
+		date := today.AddDate(0, -4+i, 0).Format(time.DateOnly)
+		input[i] = &model.ExpenditureInput{Name: ptr("NETFLIX.COM"), Amount: money(15.49), Date: date}
+	}
+
+	_, err := resolver.Mutation().CreateExpenditures(ctx, input)
+	require.NoError(t, err)
+
+	recurring, err := resolver.Query().RecurringExpenditures(ctx)
+	require.NoError(t, err)
+	require.Len(t, recurring, 1)
+	require.Equal(t, model.CadenceMonthly, recurring[0].Cadence)
+	require.Equal(t, "NETFLIX.COM", recurring[0].Name)
+	require.Equal(t, 3, recurring[0].Occurrences)
+	require.True(t, recurring[0].Missed)
+
+	detected, err := resolver.Mutation().DetectRecurringExpenditures(ctx)
+	require.NoError(t, err)
+	require.Len(t, detected, 1)
+
+	upcoming, err := resolver.Query().UpcomingCharges(ctx, 30)
+	require.NoError(t, err)
+	require.Empty(t, upcoming)
+}
+
+func TestCurrencies(t *testing.T) {
+	t.Parallel()
+
+	user := uuid.New()
+	ctx := ctxutil.WithUser(t.Context(), user)
+	pool := helper.GetTestPool()
+	resolver := &handlers.Resolver{Pool: pool}
+
+	require.NoError(t, database.CreateUser(ctx, pool, &internalmodel.User{ID: user, Username: user.String()}))
+
+	home, err := resolver.Mutation().SetHomeCurrency(ctx, "xrh")
+	require.NoError(t, err)
+	require.Equal(t, "XRH", home)
+
+	saved, err := resolver.Mutation().ImportExchangeRates(ctx, graphql.Upload{
+		File:     strings.NewReader("date,base,quote,rate\n2024-11-01,XRE,XRH,1.5\n"),
+		Filename: "rates.csv",
+	})
+	require.NoError(t, err)
+	require.Equal(t, 1, saved)
+
+	rates, err := resolver.Query().ExchangeRates(ctx, "XRE", "XRH")
+	require.NoError(t, err)
+	require.Equal(t, []*model.ExchangeRate{{Date: "2024-11-01", Base: "XRE", Quote: "XRH", Rate: 1.5}}, rates)
+
+	_, err = resolver.Mutation().CreateExpenditures(ctx, []*model.ExpenditureInput{
+		{Name: ptr("Hotel"), Amount: money(200), Date: "2024-11-03", Currency: ptr("XRE")},
+		{Name: ptr("Taxi"), Amount: money(30), Date: "2024-11-04"},
+	})
+	require.NoError(t, err)
+
+	expenditures, err := resolver.Query().Expenditures(ctx, nil, nil, nil, nil, nil, nil, nil, nil, nil)
+	require.NoError(t, err)
+	require.Len(t, expenditures, 2)
+
+	// The original amount is shown alongside the converted one
+	taxi, hotel := expenditures[0], expenditures[1]
+	require.Equal(t, "200.00", hotel.Amount.String())
+	require.Equal(t, "XRE", *hotel.Currency)
+
+	amount, err := resolver.ExpenditureResponse().HomeAmount(ctx, hotel)
+	require.NoError(t, err)
+	require.InDelta(t, 300, amount.Float64(), 0.001)
+
+	amount, err = resolver.ExpenditureResponse().HomeAmount(ctx, taxi)
+	require.NoError(t, err)
+	require.InDelta(t, 30, amount.Float64(), 0.001)
+
+	// Home amounts resolved together in an operation are loaded together.
+	loaded := handlers.WithExpenditureLoaders(ctx, pool)
+	amounts := make([]*internalmodel.Money, len(expenditures))
+	errs := make([]error, len(expenditures))
+
+	var wg sync.WaitGroup
+	for i, expenditure := range expenditures {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			amounts[i], errs[i] = resolver.ExpenditureResponse().HomeAmount(loaded, expenditure)
+		}()
+	}
+
+	wg.Wait()
+
+	require.NoError(t, errors.Join(errs...))
+	require.InDelta(t, 30, amounts[0].Float64(), 0.001)
+	require.InDelta(t, 300, amounts[1].Float64(), 0.001)
+
+	since, until := "2024-11-01", "2024-11-30"
+	aggregate, err := resolver.Query().AggregatedExpenditures(ctx, &since, &until, ptr(model.TimespanMonth),
+		nil, nil, nil, nil)
+	require.NoError(t, err)
+	require.Len(t, aggregate, 1)
+	require.InDelta(t, 330, aggregate[0].Amount.Float64(), 0.001)
+}
+
+func TestTags(t *testing.T) {
+	t.Parallel()
+
+	user := uuid.New()
+	ctx := ctxutil.WithUser(t.Context(), user)
+	pool := helper.GetTestPool()
+	resolver := &handlers.Resolver{Pool: pool}
+
+	result, err := resolver.Mutation().CreateExpenditures(ctx, []*model.ExpenditureInput{
+		{Name: ptr("Hotel"), Amount: money(200), Date: "2024-04-03", BudgetCategory: ptr("Travel")},
+		{Name: ptr("Sushi"), Amount: money(40), Date: "2024-04-04", BudgetCategory: ptr("Food")},
+		{Name: ptr("Rent"), Amount: money(1500), Date: "2024-04-01"},
+	})
+	require.NoError(t, err)
+
+	batch, err := resolver.ImportBatch().Expenditures(ctx, &result.Batch)
+	require.NoError(t, err)
+
+	ids := make([]string, len(batch))
+	for i, expenditure := range batch {
+		ids[i] = *expenditure.ID
+	}
+
+	trip, err := resolver.Mutation().CreateTag(ctx, "trip-japan-2026")
+	require.NoError(t, err)
+
+	added, err := resolver.Mutation().TagExpenditures(ctx, ids[:2], []string{"Trip-Japan-2026", "reimbursable"})
+	require.NoError(t, err)
+	require.Equal(t, 4, added)
+
+	_, err = resolver.Mutation().TagExpenditures(ctx, []string{"hotel"}, []string{"trip"})
+	require.Error(t, err)
+
+	tags, err := resolver.Query().Tags(ctx)
+	require.NoError(t, err)
+	require.Len(t, tags, 2)
+
+	expenditures, err := resolver.Query().Expenditures(ctx, nil, nil, nil, nil, []string{"trip-japan-2026"},
+		nil, nil, nil, nil)
+	require.NoError(t, err)
+	require.Len(t, expenditures, 2)
+
+	expenditureTags, err := resolver.ExpenditureResponse().Tags(ctx, expenditures[0])
+	require.NoError(t, err)
+	require.Equal(t, []*model.Tag{tags[0], tags[1]}, expenditureTags)
+
+	since, until := "2024-04-01", "2024-04-30"
+	aggregate, err := resolver.Query().AggregatedExpenditures(ctx, &since, &until, ptr(model.TimespanMonth),
+		ptr(model.GroupByTag), nil, nil, nil)
+	require.NoError(t, err)
+	require.Len(t, aggregate, 3)
+
+	totals := make(map[string]string, len(aggregate))
+	for _, total := range aggregate {
+		totals[*total.GroupByCategory] = total.Amount.String()
+	}
+
+	require.Contains(t, totals, trip.ID)
+	require.Equal(t, "240.00", totals[trip.ID])
+
+	removed, err := resolver.Mutation().UntagExpenditures(ctx, ids, []string{"reimbursable"})
+	require.NoError(t, err)
+	require.Equal(t, 2, removed)
+
+	renamed, err := resolver.Mutation().RenameTag(ctx, trip.ID, "japan")
+	require.NoError(t, err)
+	require.Equal(t, "japan", renamed.Name)
+
+	deleted, err := resolver.Mutation().DeleteTag(ctx, trip.ID)
+	require.NoError(t, err)
+	require.True(t, deleted)
+
+	_, err = resolver.Mutation().DeleteTag(ctx, trip.ID)
+	require.Error(t, err)
+}
+
+func TestBulkUpdateExpenditures(t *testing.T) {
+	t.Parallel()
+
+	user := uuid.New()
+	ctx := ctxutil.WithUser(t.Context(), user)
+	pool := helper.GetTestPool()
+	resolver := &handlers.Resolver{Pool: pool}
+
+	_, err := resolver.Mutation().CreateExpenditures(ctx, []*model.ExpenditureInput{
+		{Name: ptr("Shell"), Amount: money(60), Date: "2024-07-01", BudgetCategory: ptr("Auto")},
+		{Name: ptr("Esso"), Amount: money(55), Date: "2024-07-15", BudgetCategory: ptr("Auto")},
+		{Name: ptr("Cinema"), Amount: money(20), Date: "2024-07-20", BudgetCategory: ptr("Fun")},
+	})
+	require.NoError(t, err)
+
+	filter := model.ExpenditureFilter{Category: ptr("Auto"), Since: ptr("2024-07-01"), Until: ptr("2024-07-31")}
+	set := model.ExpenditurePatch{BudgetCategory: ptr("Gas"), Tags: []string{"car"}}
+
+	result, err := resolver.Mutation().BulkUpdateExpenditures(ctx, filter, set, ptr(true))
+	require.NoError(t, err)
+	require.Equal(t, 2, result.Affected)
+	require.Len(t, result.Preview, 4)
+	require.Equal(t, "Auto", result.Preview[0].Before)
+	require.Equal(t, "Gas", result.Preview[0].After)
+
+	result, err = resolver.Mutation().BulkUpdateExpenditures(ctx, filter, set, nil)
+	require.NoError(t, err)
+	require.Equal(t, 2, result.Affected)
+	require.Empty(t, result.Preview)
+
+	expenditures, err := resolver.Query().Expenditures(ctx, nil, ptr("Gas"), nil, nil, []string{"car"},
+		ptr("2024-07-01"), ptr("2024-07-31"), nil, nil)
+	require.NoError(t, err)
+	require.Len(t, expenditures, 2)
+
+	_, err = resolver.Mutation().BulkUpdateExpenditures(ctx, filter, model.ExpenditurePatch{Method: ptr("visa")}, nil)
+	require.Error(t, err)
+}
+
+func TestHistory(t *testing.T) {
+	t.Parallel()
+
+	user := uuid.New()
+	ctx := ctxutil.WithUser(t.Context(), user)
+	pool := helper.GetTestPool()
+	resolver := &handlers.Resolver{Pool: pool}
+
+	b, err := resolver.Mutation().CreateBudget(ctx, model.NewBudgetInput{
+		Name:     "Budget",
+		Expenses: []*model.ExpenseInput{{Category: "food", Amount: money(500)}},
+	})
+	require.NoError(t, err)
+
+	_, err = resolver.Mutation().UpdateBudget(ctx, model.UpdateBudgetInput{
+		ID:       *b.ID,
+		Expenses: []*model.ExpenseInput{{Category: "food", Amount: money(600), ID: b.Expenses[0].ID}},
+	})
+	require.NoError(t, err)
+
+	history, err := resolver.Query().History(ctx, *b.Expenses[0].ID)
+	require.NoError(t, err)
+	require.Len(t, history, 2)
+	require.Equal(t, model.AuditActionInsert, history[0].Action)
+	require.Nil(t, history[0].Before)
+	require.Equal(t, model.AuditActionUpdate, history[1].Action)
+	require.Equal(t, "expense", history[1].EntityType)
+	require.Equal(t, *b.Expenses[0].ID, history[1].EntityID)
+	require.Contains(t, *history[1].Before, `"amount": 500`)
+	require.Contains(t, *history[1].After, `"amount": 600`)
+
+	history, err = resolver.Query().History(ctxutil.WithUser(t.Context(), uuid.New()), *b.Expenses[0].ID)
+	require.NoError(t, err)
+	require.Empty(t, history)
 }
 
 //nolint:paralleltest
@@ -813,6 +1702,10 @@ func TestRewardCards(t *testing.T) {
 	}
 }
 
-func ptr(s string) *string {
-	return &s
+func ptr[T any](v T) *T {
+	return &v
+}
+
+func money(amount float64) internalmodel.Money {
+	return internalmodel.MoneyFromFloat(amount)
 }
@@ -3,8 +3,10 @@ package handlers
 import (
 	"net/http"
 	"os"
+	"yaba/graph/model"
 	"yaba/graph/server"
 	"yaba/internal/auth"
+	"yaba/internal/blob"
 
 	"github.com/99designs/gqlgen/graphql/handler"
 	"github.com/99designs/gqlgen/graphql/handler/extension"
@@ -12,16 +14,18 @@ import (
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
-func BuildServerHandler(pool *pgxpool.Pool) (http.Handler, error) {
+func BuildServerHandler(pool *pgxpool.Pool, blobs blob.Store) (http.Handler, error) {
 	mux := http.NewServeMux()
 
 	gqlHandler := handler.New(server.NewExecutableSchema(server.Config{Resolvers: &Resolver{
-		Pool: pool,
+		Pool:  pool,
+		Blobs: blobs,
 	}}))
 	gqlHandler.AddTransport(transport.GET{})
 	gqlHandler.AddTransport(transport.POST{})
 	gqlHandler.AddTransport(transport.MultipartForm{})
 	gqlHandler.Use(extension.Introspection{})
+	gqlHandler.AroundOperations(loadExpenditures(pool))
 
 	mux.Handle("/graphql", auth.NewAuthRequired(gqlHandler))
 
@@ -29,6 +33,13 @@ func BuildServerHandler(pool *pgxpool.Pool) (http.Handler, error) {
 	mux.Handle("/api/login", auth.VerifyUserHandler(pool))
 	mux.Handle("/api/logout", auth.NewLogoutHandler(pool))
 
+	mux.Handle("/api/import/ofx", auth.NewAuthRequired(NewOFXImportHandler(pool)))
+	mux.Handle("/api/import/pdf", auth.NewAuthRequired(NewPDFImportHandler(pool)))
+
+	mux.Handle(model.ExportURL, auth.NewAuthRequired(NewExportHandler(pool)))
+
+	mux.Handle(model.AttachmentURLPrefix+"{id}", auth.NewAuthRequired(NewAttachmentHandler(pool, blobs)))
+
 	routeReactPages(mux)
 
 	mux.Handle("/", http.FileServer(http.Dir(os.Getenv("UI_ROOT_DIR"))))
@@ -0,0 +1,279 @@
+package importer
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+	"yaba/errors"
+	"yaba/internal/model"
+)
+
+type SignConvention string
+
+const (
+	// SignExpensesPositive is used by statements that list purchases as positive amounts.
+	SignExpensesPositive SignConvention = "EXPENSES_POSITIVE"
+	// SignExpensesNegative is used by statements that list purchases as negative amounts.
+	SignExpensesNegative SignConvention = "EXPENSES_NEGATIVE"
+)
+
+// CSVMapping describes the layout of a CSV statement. Columns are referenced by header name, or by
+// their 1-based position when the file has no header. Empty columns are not read. The currency and
+// kind columns are also not read if the file doesn't have them, since most statements don't.
+type CSVMapping struct {
+	Date           string
+	Amount         string
+	Debit          string
+	Credit         string
+	Name           string
+	Method         string
+	BudgetCategory string
+	RewardCategory string
+	Comment        string
+	// Currency is the column holding the ISO 4217 code of each amount. Amounts are in the user's
+	// home currency if it is not mapped.
+	Currency string
+	// Kind is the column holding whether each row is an EXPENSE, INCOME or TRANSFER. Rows are
+	// expenses if it is not mapped or blank.
+	Kind string
+
+	// DateFormat is a Go time layout.
+	DateFormat string
+	Sign       SignConvention
+	Delimiter  rune
+	HasHeader  bool
+}
+
+// DefaultCSVMapping returns the mapping for the layout written by tools/mock.go, and by CSV exports,
+// which add the currency and kind columns.
+func DefaultCSVMapping() *CSVMapping {
+	return &CSVMapping{
+		Date:           "date",
+		Amount:         "amount",
+		Name:           "name",
+		Method:         "method",
+		BudgetCategory: "budget_category",
+		RewardCategory: "reward_category",
+		Comment:        "comment",
+		Currency:       "currency",
+		Kind:           "kind",
+		DateFormat:     time.DateOnly,
+		Sign:           SignExpensesPositive,
+		Delimiter:      ',',
+		HasHeader:      true,
+	}
+}
+
+// ParseCSV reads every row of a CSV statement into records.
+func ParseCSV(r io.Reader, mapping *CSVMapping) ([]*Record, error) {
+	reader := csv.NewReader(r)
+	reader.Comma = mapping.Delimiter
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	rows, err := reader.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read csv: %w", err)
+	}
+
+	if len(rows) == 0 {
+		return []*Record{}, nil
+	}
+
+	var header []string
+	if mapping.HasHeader {
+		header, rows = rows[0], rows[1:]
+	}
+
+	columns, err := mapping.resolveColumns(header)
+	if err != nil {
+		return nil, err
+	}
+
+	records := make([]*Record, 0, len(rows))
+
+	for i, row := range rows {
+		line := i + 1
+		if mapping.HasHeader {
+			line++
+		}
+
+		if isBlank(row) {
+			continue
+		}
+
+		record, err := columns.parse(row, mapping)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+
+		records = append(records, record)
+	}
+
+	return records, nil
+}
+
+// csvColumns holds the 0-based index of each mapped column, or -1 if it is not mapped.
+type csvColumns struct {
+	date, amount, debit, credit, name, method, budgetCategory, rewardCategory, comment,
+	currency, kind int
+}
+
+func (m *CSVMapping) resolveColumns(header []string) (*csvColumns, error) {
+	indexOf := func(column string) (int, error) {
+		if column == "" {
+			return -1, nil
+		}
+
+		if !m.HasHeader {
+			i, err := strconv.Atoi(column)
+			if err != nil || i < 1 {
+				return -1, errors.InvalidInputError{Input: "column " + column}
+			}
+
+			return i - 1, nil
+		}
+
+		for i, name := range header {
+			if strings.EqualFold(strings.TrimSpace(name), column) {
+				return i, nil
+			}
+		}
+
+		return -1, errors.InvalidInputError{Input: "missing column " + column}
+	}
+
+	var cols csvColumns
+	var err error
+
+	for _, c := range []struct {
+		dst      *int
+		column   string
+		optional bool
+	}{
+		{&cols.date, m.Date, false},
+		{&cols.amount, m.Amount, false},
+		{&cols.debit, m.Debit, false},
+		{&cols.credit, m.Credit, false},
+		{&cols.name, m.Name, false},
+		{&cols.method, m.Method, false},
+		{&cols.budgetCategory, m.BudgetCategory, false},
+		{&cols.rewardCategory, m.RewardCategory, false},
+		{&cols.comment, m.Comment, false},
+		{&cols.currency, m.Currency, true},
+		{&cols.kind, m.Kind, true},
+	} {
+		if *c.dst, err = indexOf(c.column); err != nil {
+			if !c.optional {
+				return nil, err
+			}
+
+			*c.dst = -1
+		}
+	}
+
+	if cols.date < 0 {
+		return nil, errors.InvalidInputError{Input: "date column is required"}
+	}
+
+	if cols.amount < 0 && cols.debit < 0 && cols.credit < 0 {
+		return nil, errors.InvalidInputError{Input: "amount, debit or credit column is required"}
+	}
+
+	return &cols, nil
+}
+
+func (c *csvColumns) parse(row []string, mapping *CSVMapping) (*Record, error) {
+	date, err := time.ParseInLocation(mapping.DateFormat, field(row, c.date), time.UTC)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse date: %w", err)
+	}
+
+	var amount model.Money
+
+	if c.amount >= 0 {
+		if amount, err = parseAmount(field(row, c.amount)); err != nil {
+			return nil, err
+		}
+
+		if mapping.Sign == SignExpensesNegative {
+			amount = -amount
+		}
+	} else {
+		// Debit and credit columns hold unsigned amounts for purchases and payments respectively.
+		debit, err := parseAmount(field(row, c.debit))
+		if err != nil {
+			return nil, err
+		}
+
+		credit, err := parseAmount(field(row, c.credit))
+		if err != nil {
+			return nil, err
+		}
+
+		amount = debit - credit
+	}
+
+	kind := model.Kind(strings.ToUpper(field(row, c.kind)))
+	switch kind {
+	case "", model.KindExpense, model.KindIncome, model.KindTransfer:
+	default:
+		return nil, errors.InvalidInputError{Input: "kind " + field(row, c.kind)}
+	}
+
+	return &Record{
+		Date:           date,
+		Amount:         amount,
+		Name:           field(row, c.name),
+		Method:         field(row, c.method),
+		BudgetCategory: field(row, c.budgetCategory),
+		RewardCategory: field(row, c.rewardCategory),
+		Comment:        field(row, c.comment),
+		Currency:       field(row, c.currency),
+		Kind:           kind,
+	}, nil
+}
+
+// parseAmount parses amounts such as "1,234.56", "$12", "-3.50" and "(3.50)". Blank is zero.
+func parseAmount(s string) (model.Money, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return 0, nil
+	}
+
+	negative := strings.HasPrefix(s, "(") && strings.HasSuffix(s, ")")
+	s = strings.Trim(s, "()")
+	s = strings.NewReplacer(",", "", "$", "", " ", "").Replace(s)
+
+	amount, err := model.ParseMoney(s)
+	if err != nil {
+		return 0, fmt.Errorf("failed to parse amount: %w", err)
+	}
+
+	if negative {
+		amount = -amount
+	}
+
+	return amount, nil
+}
+
+func field(row []string, i int) string {
+	if i < 0 || i >= len(row) {
+		return ""
+	}
+
+	return strings.TrimSpace(row[i])
+}
+
+func isBlank(row []string) bool {
+	for _, v := range row {
+		if strings.TrimSpace(v) != "" {
+			return false
+		}
+	}
+
+	return true
+}
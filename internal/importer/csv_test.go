@@ -0,0 +1,180 @@
+package importer_test
+
+import (
+	"os"
+	"strings"
+	"testing"
+	"time"
+	"yaba/internal/importer"
+	"yaba/internal/model"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseCSVDefaultMapping(t *testing.T) {
+	t.Parallel()
+
+	f, err := os.Open("../handlers/testdata/spend.csv")
+	require.NoError(t, err)
+
+	defer func() { _ = f.Close() }()
+
+	records, err := importer.ParseCSV(f, importer.DefaultCSVMapping())
+	require.NoError(t, err)
+	require.Len(t, records, 3)
+
+	require.Equal(t, &importer.Record{
+		Date:           time.Date(2006, 7, 8, 0, 0, 0, 0, time.UTC),
+		Amount:         model.MoneyFromFloat(99.99),
+		Name:           "lawn mowing",
+		Method:         "cash",
+		BudgetCategory: "maintenance",
+		Comment:        "lawn mowing kid",
+	}, records[2])
+	require.Equal(t, "GROCERY", records[1].RewardCategory)
+}
+
+func TestParseCSVCustomMapping(t *testing.T) {
+	t.Parallel()
+
+	f, err := os.Open("testdata/bank.csv")
+	require.NoError(t, err)
+
+	defer func() { _ = f.Close() }()
+
+	records, err := importer.ParseCSV(f, &importer.CSVMapping{
+		Date:       "transaction date",
+		Debit:      "Withdrawals",
+		Credit:     "Deposits",
+		Name:       "Description",
+		DateFormat: "01/02/2006",
+		Delimiter:  ';',
+		HasHeader:  true,
+	})
+	require.NoError(t, err)
+	require.Len(t, records, 3)
+
+	require.Equal(t, "SQ *BLUE BOTTLE 1234 SF", records[0].Name)
+	require.InDelta(t, 4.5, records[0].Amount.Float64(), .001)
+	require.Equal(t, time.Date(2025, 3, 14, 0, 0, 0, 0, time.UTC), records[0].Date)
+	require.InDelta(t, -100, records[1].Amount.Float64(), .001)
+	// A negative credit is money going out
+	require.InDelta(t, 12, records[2].Amount.Float64(), .001)
+}
+
+func TestParseCSVSignConventionAndPositions(t *testing.T) {
+	t.Parallel()
+
+	data := "2025-01-02,-1,234.50,COSTCO\n2025-01-03,20.00,PAYROLL\n"
+	data = strings.ReplaceAll(data, "-1,234.50", `"-1,234.50"`)
+
+	records, err := importer.ParseCSV(strings.NewReader(data), &importer.CSVMapping{
+		Date:       "1",
+		Amount:     "2",
+		Name:       "3",
+		DateFormat: time.DateOnly,
+		Sign:       importer.SignExpensesNegative,
+		Delimiter:  ',',
+	})
+	require.NoError(t, err)
+	require.Len(t, records, 2)
+	require.InDelta(t, 1234.5, records[0].Amount.Float64(), .001)
+	require.Equal(t, "COSTCO", records[0].Name)
+	require.InDelta(t, -20, records[1].Amount.Float64(), .001)
+}
+
+func TestParseCSVCurrency(t *testing.T) {
+	t.Parallel()
+
+	data := "date,amount,name,currency\n2025-01-02,12.50,BLUE BOTTLE,USD\n2025-01-03,20.00,COSTCO,\n"
+
+	mapping := importer.DefaultCSVMapping()
+	mapping.Method, mapping.BudgetCategory, mapping.RewardCategory, mapping.Comment = "", "", "", ""
+	mapping.Currency = "currency"
+
+	records, err := importer.ParseCSV(strings.NewReader(data), mapping)
+	require.NoError(t, err)
+	require.Len(t, records, 2)
+	require.Equal(t, "USD", records[0].Currency)
+	// Blank currencies are the user's home currency
+	require.Empty(t, records[1].Currency)
+}
+
+func TestParseCSVKind(t *testing.T) {
+	t.Parallel()
+
+	data := "date,amount,name,kind\n2025-01-02,-2000.00,PAYROLL,income\n2025-01-03,20.00,COSTCO,\n" +
+		"2025-01-04,100.00,PAYMENT,TRANSFER\n"
+
+	records, err := importer.ParseCSV(strings.NewReader(data), &importer.CSVMapping{
+		Date: "date", Amount: "amount", Name: "name", Kind: "kind", DateFormat: time.DateOnly, Delimiter: ',',
+		HasHeader: true,
+	})
+	require.NoError(t, err)
+	require.Len(t, records, 3)
+	require.Equal(t, model.KindIncome, records[0].Kind)
+	// Blank kinds are expenses
+	require.Empty(t, records[1].Kind)
+	require.Equal(t, model.KindTransfer, records[2].Kind)
+
+	mapping := importer.DefaultCSVMapping()
+	mapping.Name, mapping.Method, mapping.BudgetCategory, mapping.RewardCategory, mapping.Comment = "", "", "", "", ""
+
+	_, err = importer.ParseCSV(strings.NewReader("date,amount,kind\n2025-01-02,1,REFUND\n"), mapping)
+	require.ErrorContains(t, err, "line 2: invalid input value: kind REFUND")
+}
+
+func TestParseCSVErrors(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name    string
+		data    string
+		mapping *importer.CSVMapping
+		err     string
+	}{
+		{
+			name:    "missing column",
+			data:    "date,name\n2025-01-01,foo\n",
+			mapping: importer.DefaultCSVMapping(),
+			err:     "missing column amount",
+		},
+		{
+			name:    "bad date",
+			data:    "date,amount\n01/01/2025,1\n",
+			mapping: &importer.CSVMapping{Date: "date", Amount: "amount", DateFormat: time.DateOnly, HasHeader: true},
+			err:     "line 2: failed to parse date",
+		},
+		{
+			name:    "bad amount",
+			data:    "date,amount\n2025-01-01,1\n2025-01-01,abc\n",
+			mapping: &importer.CSVMapping{Date: "date", Amount: "amount", DateFormat: time.DateOnly, HasHeader: true},
+			err:     "line 3: failed to parse amount",
+		},
+		{
+			name:    "bad position",
+			data:    "2025-01-01,1\n",
+			mapping: &importer.CSVMapping{Date: "date", Amount: "2", DateFormat: time.DateOnly},
+			err:     "invalid input value: column date",
+		},
+		{
+			name:    "no amount",
+			data:    "date\n2025-01-01\n",
+			mapping: &importer.CSVMapping{Date: "date", DateFormat: time.DateOnly, HasHeader: true},
+			err:     "amount, debit or credit column is required",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			if tc.mapping.Delimiter == 0 {
+				tc.mapping.Delimiter = ','
+			}
+
+			_, err := importer.ParseCSV(strings.NewReader(tc.data), tc.mapping)
+			require.ErrorContains(t, err, tc.err)
+		})
+	}
+}
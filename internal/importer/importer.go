@@ -0,0 +1,114 @@
+package importer
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+	"yaba/errors"
+	"yaba/internal/ctxutil"
+	"yaba/internal/database"
+	"yaba/internal/model"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// maxSourceLength is the size of the expenditure.source column.
+const maxSourceLength = 50
+
+// Record is a single transaction read from a statement file. Amounts are positive for spending.
+type Record struct {
+	Date           time.Time
+	Amount         model.Money
+	Name           string
+	Method         string
+	BudgetCategory string
+	RewardCategory string
+	Comment        string
+	// ExternalID is the bank's ID for the transaction. It is unique per account.
+	ExternalID string
+	// Account is the bank's account ID, which is matched against payment_method.account_id.
+	Account string
+	// Kind is left empty when the statement doesn't say, which saves the record as an expense.
+	Kind model.Kind
+	// Currency is the ISO 4217 code of the amount, or empty for the user's home currency.
+	Currency string
+}
+
+// Statement holds the transactions read from a statement file.
+type Statement struct {
+	Records []*Record
+	// Unparsed holds lines that look like transactions but could not be read, for manual review.
+	Unparsed []string
+}
+
+// ToExpenditures binds parsed records to the user in the context. Payment methods are matched by
+// display name, then by account ID; records without either are assigned defaultMethod.
+func ToExpenditures(
+	ctx context.Context,
+	pool *pgxpool.Pool,
+	records []*Record,
+	source string,
+	defaultMethod uuid.UUID,
+) ([]*model.Expenditure, error) {
+	methods, err := database.ListPaymentMethods(ctx, pool)
+	if err != nil {
+		return nil, err
+	}
+
+	methodIDs := make(map[string]uuid.UUID, len(methods))
+	accountIDs := make(map[string]uuid.UUID, len(methods))
+
+	for _, method := range methods {
+		methodIDs[strings.ToLower(method.DisplayName)] = method.ID
+
+		if method.AccountID != "" {
+			accountIDs[method.AccountID] = method.ID
+		}
+	}
+
+	source = truncate(source, maxSourceLength)
+	owner := ctxutil.GetUser(ctx)
+	expenditures := make([]*model.Expenditure, len(records))
+
+	for i, record := range records {
+		method := defaultMethod
+
+		if record.Method != "" {
+			var ok bool
+			if method, ok = methodIDs[strings.ToLower(record.Method)]; !ok {
+				return nil, fmt.Errorf("unknown payment method on row %d: %w",
+					i+1, errors.InvalidInputError{Input: record.Method})
+			}
+		} else if id, ok := accountIDs[record.Account]; ok && record.Account != "" {
+			method = id
+		}
+
+		expenditures[i] = &model.Expenditure{
+			Owner:          owner,
+			Name:           record.Name,
+			Amount:         record.Amount,
+			Date:           record.Date,
+			Method:         method,
+			BudgetCategory: record.BudgetCategory,
+			RewardCategory: record.RewardCategory,
+			Comment:        record.Comment,
+			Source:         source,
+			ExternalID:     record.ExternalID,
+			Kind:           record.Kind,
+			Currency:       record.Currency,
+		}
+	}
+
+	return expenditures, nil
+}
+
+func truncate(s string, n int) string {
+	runes := []rune(s)
+	if len(runes) <= n {
+		return s
+	}
+
+	return string(runes[:n])
+}
@@ -0,0 +1,54 @@
+package importer_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+	"yaba/internal/ctxutil"
+	"yaba/internal/database"
+	"yaba/internal/importer"
+	"yaba/internal/model"
+	"yaba/internal/test/helper"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+func TestToExpenditures(t *testing.T) {
+	t.Parallel()
+
+	pool := helper.GetTestPool()
+	owner := uuid.New()
+	ctx := ctxutil.WithUser(t.Context(), owner)
+
+	visa := &model.PaymentMethod{ID: uuid.New(), DisplayName: "Visa"}
+	debit := &model.PaymentMethod{ID: uuid.New(), DisplayName: "Debit"}
+	chequing := &model.PaymentMethod{ID: uuid.New(), DisplayName: "Chequing", AccountID: "9876543"}
+
+	for _, method := range []*model.PaymentMethod{visa, debit, chequing} {
+		require.NoError(t, database.CreatePaymentMethod(ctx, pool, method))
+	}
+
+	date := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	records := []*importer.Record{
+		{Date: date, Amount: model.MoneyFromFloat(1), Name: "a", Method: "VISA"},
+		{Date: date, Amount: model.MoneyFromFloat(2), Name: "b"},
+		{Date: date, Amount: model.MoneyFromFloat(3), Name: "c", Account: "9876543", ExternalID: "A-1"},
+	}
+
+	source := strings.Repeat("x", 60) + ".csv"
+	expenditures, err := importer.ToExpenditures(ctx, pool, records, source, debit.ID)
+	require.NoError(t, err)
+	require.Len(t, expenditures, 3)
+
+	require.Equal(t, owner, expenditures[0].Owner)
+	require.Equal(t, visa.ID, expenditures[0].Method)
+	require.Equal(t, debit.ID, expenditures[1].Method)
+	require.Equal(t, chequing.ID, expenditures[2].Method)
+	require.Equal(t, "A-1", expenditures[2].ExternalID)
+	require.Equal(t, strings.Repeat("x", 50), expenditures[0].Source)
+
+	records[1].Method = "Amex"
+	_, err = importer.ToExpenditures(ctx, pool, records, "statement.csv", uuid.Nil)
+	require.ErrorContains(t, err, "unknown payment method on row 2")
+}
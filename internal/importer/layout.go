@@ -0,0 +1,218 @@
+package importer
+
+import (
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+)
+
+// StatementLayout reads transactions from the text lines of an issuer's statements.
+type StatementLayout interface {
+	Parse(lines []string) *Statement
+}
+
+//nolint:gochecknoglobals
+var layouts = struct {
+	sync.RWMutex
+	byIssuer map[string]StatementLayout
+}{byIssuer: map[string]StatementLayout{}}
+
+//nolint:gochecknoinits
+func init() {
+	RegisterLayout("Chase", ChaseLayout())
+	RegisterLayout("American Express", AmexLayout())
+	RegisterLayout("Tangerine", TangerineLayout())
+}
+
+// RegisterLayout sets the layout used for statements of cards from the issuer. Issuers are
+// matched case-insensitively against rewards_card.issuer.
+func RegisterLayout(issuer string, layout StatementLayout) {
+	layouts.Lock()
+	defer layouts.Unlock()
+
+	layouts.byIssuer[strings.ToLower(issuer)] = layout
+}
+
+// LayoutForIssuer returns the layout registered for the issuer, or DefaultLayout.
+func LayoutForIssuer(issuer string) StatementLayout {
+	layouts.RLock()
+	defer layouts.RUnlock()
+
+	if layout, ok := layouts.byIssuer[strings.ToLower(issuer)]; ok {
+		return layout
+	}
+
+	return DefaultLayout()
+}
+
+// RegexpLayout reads statements with one transaction per line.
+type RegexpLayout struct {
+	// Candidate matches lines that are expected to be transactions. Candidates that Transaction
+	// can't read are returned for review; other lines are ignored.
+	Candidate *regexp.Regexp
+	// Transaction matches a transaction line with the named groups date, name and amount.
+	Transaction *regexp.Regexp
+	// DateLayouts are tried in order to parse the date group.
+	DateLayouts []string
+	// ClosingDate matches the statement closing date in the named group date. Transaction dates
+	// without a year are placed in the year leading up to the closing date.
+	ClosingDate        *regexp.Regexp
+	ClosingDateLayouts []string
+}
+
+const (
+	shortDate   = `(?:[A-Za-z]{3}\.? \d{1,2}|\d{1,2}/\d{1,2}(?:/\d{2,4})?)`
+	moneyAmount = `(?:-?\$?[\d,]+\.\d{2}(?: ?CR)?|\(\$?[\d,]+\.\d{2}\))`
+)
+
+// DefaultLayout reads lines like "MAR 14 MAR 15 SQ *BLUE BOTTLE 4.50", with an optional posting
+// date. Payments and refunds are negative, in parentheses or end with "CR".
+func DefaultLayout() *RegexpLayout {
+	return &RegexpLayout{
+		Candidate: regexp.MustCompile(`^` + shortDate + `\s`),
+		Transaction: regexp.MustCompile(`^(?P<date>` + shortDate + `)\s+(?:` + shortDate + `\s+)?` +
+			`(?P<name>.+?)\s+(?P<amount>` + moneyAmount + `)$`),
+		DateLayouts: []string{"Jan 2", "Jan. 2", "01/02/2006", "1/2/2006", "01/02/06", "1/2/06", "01/02", "1/2"},
+		ClosingDate: regexp.MustCompile(`(?i)(?:statement|closing) date:?\s+(?P<date>.+)$`),
+		ClosingDateLayouts: []string{
+			"January 2, 2006", "Jan 2, 2006", "Jan. 2, 2006", "01/02/2006", time.DateOnly,
+		},
+	}
+}
+
+// ChaseLayout reads lines like "01/14 SQ *BLUE BOTTLE 4.50", placed in the year of the second date
+// of the "Opening/Closing Date 12/06/24 - 01/05/25" line. Payments and refunds are negative.
+func ChaseLayout() *RegexpLayout {
+	return &RegexpLayout{
+		Candidate:          regexp.MustCompile(`^\d{2}/\d{2}\s`),
+		Transaction:        regexp.MustCompile(`^(?P<date>\d{2}/\d{2})\s+(?P<name>.+?)\s+(?P<amount>-?[\d,]+\.\d{2})$`),
+		DateLayouts:        []string{"01/02"},
+		ClosingDate:        regexp.MustCompile(`(?i)opening/closing date\s+\S+\s+-\s+(?P<date>\S+)`),
+		ClosingDateLayouts: []string{"01/02/06"},
+	}
+}
+
+// AmexLayout reads lines like "01/14/25* AMAZON.CA TORONTO $45.67", where the date is followed by an
+// asterisk if the charge was made by an additional card member. Credits are "-$45.67".
+func AmexLayout() *RegexpLayout {
+	return &RegexpLayout{
+		Candidate: regexp.MustCompile(`^\d{2}/\d{2}/\d{2}\*?\s`),
+		Transaction: regexp.MustCompile(`^(?P<date>\d{2}/\d{2}/\d{2})\*?\s+(?P<name>.+?)\s+` +
+			`(?P<amount>-?\$[\d,]+\.\d{2})$`),
+		DateLayouts: []string{"01/02/06"},
+	}
+}
+
+// TangerineLayout reads lines like "Jan 14, 2025 Jan 15, 2025 LOBLAWS #1234 TORONTO ON 45.67", with
+// the transaction and posting dates. Payments and refunds are negative.
+func TangerineLayout() *RegexpLayout {
+	const longDate = `[A-Za-z]{3} \d{1,2}, \d{4}`
+
+	return &RegexpLayout{
+		Candidate: regexp.MustCompile(`^` + longDate + `\s`),
+		Transaction: regexp.MustCompile(`^(?P<date>` + longDate + `)\s+(?:` + longDate + `\s+)?` +
+			`(?P<name>.+?)\s+(?P<amount>-?[\d,]+\.\d{2})$`),
+		DateLayouts: []string{"Jan 2, 2006"},
+	}
+}
+
+func (l *RegexpLayout) Parse(lines []string) *Statement {
+	closing := l.closingDate(lines)
+	statement := &Statement{Records: []*Record{}, Unparsed: []string{}}
+
+	for _, line := range lines {
+		if !l.Candidate.MatchString(line) {
+			continue
+		}
+
+		record, ok := l.parseLine(line, closing)
+		if !ok {
+			statement.Unparsed = append(statement.Unparsed, line)
+
+			continue
+		}
+
+		statement.Records = append(statement.Records, record)
+	}
+
+	return statement
+}
+
+func (l *RegexpLayout) parseLine(line string, closing time.Time) (*Record, bool) {
+	match := l.Transaction.FindStringSubmatch(line)
+	if match == nil {
+		return nil, false
+	}
+
+	group := func(name string) string {
+		return strings.TrimSpace(match[l.Transaction.SubexpIndex(name)])
+	}
+
+	date, ok := parseDate(normalizeMonth(group("date")), l.DateLayouts)
+	if !ok {
+		return nil, false
+	}
+
+	if date.Year() == 0 {
+		date = date.AddDate(closing.Year(), 0, 0)
+		if date.After(closing) {
+			date = date.AddDate(-1, 0, 0)
+		}
+	}
+
+	amountText := group("amount")
+	credit := strings.HasSuffix(amountText, "CR")
+
+	amount, err := parseAmount(strings.TrimSpace(strings.TrimSuffix(amountText, "CR")))
+	if err != nil {
+		return nil, false
+	}
+
+	if credit {
+		amount = -amount
+	}
+
+	return &Record{
+		Date:   date,
+		Amount: amount,
+		Name:   group("name"),
+	}, true
+}
+
+func (l *RegexpLayout) closingDate(lines []string) time.Time {
+	if l.ClosingDate != nil {
+		for _, line := range lines {
+			if match := l.ClosingDate.FindStringSubmatch(line); match != nil {
+				value := match[l.ClosingDate.SubexpIndex("date")]
+				if date, ok := parseDate(normalizeMonth(value), l.ClosingDateLayouts); ok {
+					return date
+				}
+			}
+		}
+	}
+
+	return time.Now().UTC().Truncate(24 * time.Hour)
+}
+
+func parseDate(value string, dateLayouts []string) (time.Time, bool) {
+	value = strings.TrimSpace(value)
+
+	for _, layout := range dateLayouts {
+		if date, err := time.ParseInLocation(layout, value, time.UTC); err == nil {
+			return date, true
+		}
+	}
+
+	return time.Time{}, false
+}
+
+// normalizeMonth title-cases words so that upper case month names such as "MAR" can be parsed.
+func normalizeMonth(value string) string {
+	words := strings.Fields(value)
+	for i, word := range words {
+		words[i] = strings.ToUpper(word[:1]) + strings.ToLower(word[1:])
+	}
+
+	return strings.Join(words, " ")
+}
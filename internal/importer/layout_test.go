@@ -0,0 +1,117 @@
+package importer_test
+
+import (
+	"regexp"
+	"testing"
+	"time"
+	"yaba/internal/importer"
+	"yaba/internal/model"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDefaultLayout(t *testing.T) {
+	t.Parallel()
+
+	statement := importer.DefaultLayout().Parse([]string{
+		"Closing Date 01/05/2025",
+		"12/28 12/29 AIR CANADA 1,234.56",
+		"01/02 01/03 RETURN - HUDSON'S BAY -45.00",
+		"01/03/2025 SPOTIFY $11.99",
+		"01/04 SOMETHING SMUDGED",
+		"Previous balance 100.00",
+	})
+
+	require.Equal(t, []string{"01/04 SOMETHING SMUDGED"}, statement.Unparsed)
+	require.Equal(t, []*importer.Record{
+		{Date: time.Date(2024, 12, 28, 0, 0, 0, 0, time.UTC), Amount: model.MoneyFromFloat(1234.56), Name: "AIR CANADA"},
+		{Date: time.Date(2025, 1, 2, 0, 0, 0, 0, time.UTC), Amount: model.MoneyFromFloat(-45), Name: "RETURN - HUDSON'S BAY"},
+		{Date: time.Date(2025, 1, 3, 0, 0, 0, 0, time.UTC), Amount: model.MoneyFromFloat(11.99), Name: "SPOTIFY"},
+	}, statement.Records)
+}
+
+func TestLayoutForIssuer(t *testing.T) {
+	t.Parallel()
+
+	layout := &importer.RegexpLayout{
+		Candidate:   regexp.MustCompile(`^\d{4}-\d{2}-\d{2} `),
+		Transaction: regexp.MustCompile(`^(?P<date>\S+) (?P<amount>\S+) (?P<name>.+)$`),
+		DateLayouts: []string{time.DateOnly},
+	}
+	importer.RegisterLayout("Test Issuer", layout)
+
+	require.Same(t, layout, importer.LayoutForIssuer("TEST ISSUER"))
+	require.IsType(t, importer.DefaultLayout(), importer.LayoutForIssuer("unknown"))
+
+	statement := importer.LayoutForIssuer("test issuer").Parse([]string{
+		"2025-02-03 9.99 NETFLIX",
+		"2025-02-04 ?? GARBLED",
+	})
+	require.Equal(t, []*importer.Record{
+		{Date: time.Date(2025, 2, 3, 0, 0, 0, 0, time.UTC), Amount: model.MoneyFromFloat(9.99), Name: "NETFLIX"},
+	}, statement.Records)
+	require.Equal(t, []string{"2025-02-04 ?? GARBLED"}, statement.Unparsed)
+}
+
+func TestIssuerLayouts(t *testing.T) {
+	t.Parallel()
+
+	day := func(year int, month time.Month, day int) time.Time {
+		return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+	}
+
+	tests := []struct {
+		issuer   string
+		layout   *importer.RegexpLayout
+		lines    []string
+		expected []*importer.Record
+	}{
+		{
+			issuer: "chase",
+			layout: importer.ChaseLayout(),
+			lines: []string{
+				"Opening/Closing Date 12/06/24 - 01/05/25",
+				"12/28 AIR CANADA 1,234.56",
+				"01/02 Payment Thank You-Mobile -500.00",
+			},
+			expected: []*importer.Record{
+				{Date: day(2024, 12, 28), Amount: model.MoneyFromFloat(1234.56), Name: "AIR CANADA"},
+				{Date: day(2025, 1, 2), Amount: model.MoneyFromFloat(-500), Name: "Payment Thank You-Mobile"},
+			},
+		},
+		{
+			issuer: "AMERICAN EXPRESS",
+			layout: importer.AmexLayout(),
+			lines: []string{
+				"01/14/25* AMAZON.CA TORONTO $45.67",
+				"01/16/25 AMAZON.CA TORONTO -$12.00",
+			},
+			expected: []*importer.Record{
+				{Date: day(2025, 1, 14), Amount: model.MoneyFromFloat(45.67), Name: "AMAZON.CA TORONTO"},
+				{Date: day(2025, 1, 16), Amount: model.MoneyFromFloat(-12), Name: "AMAZON.CA TORONTO"},
+			},
+		},
+		{
+			issuer: "Tangerine",
+			layout: importer.TangerineLayout(),
+			lines: []string{
+				"Jan 14, 2025 Jan 15, 2025 LOBLAWS #1234 TORONTO ON 45.67",
+			},
+			expected: []*importer.Record{
+				{Date: day(2025, 1, 14), Amount: model.MoneyFromFloat(45.67), Name: "LOBLAWS #1234 TORONTO ON"},
+			},
+		},
+	}
+
+	for _, test := range tests {
+		layout := importer.LayoutForIssuer(test.issuer)
+		require.Equal(t, test.layout, layout, test.issuer)
+
+		statement := layout.Parse(test.lines)
+		require.Empty(t, statement.Unparsed, test.issuer)
+		require.Equal(t, test.expected, statement.Records, test.issuer)
+
+		// The default layout can't read the issuer's statements.
+		require.NotEqual(t, test.expected, importer.DefaultLayout().Parse(test.lines).Records, test.issuer)
+	}
+}
@@ -0,0 +1,143 @@
+package importer
+
+import (
+	"fmt"
+	"html"
+	"io"
+	"strings"
+	"time"
+	"yaba/errors"
+	"yaba/internal/model"
+)
+
+// ParseOFX reads the STMTTRN entries of an OFX or QFX file. Both OFX 1.x (SGML, where leaf elements
+// are not closed) and OFX 2.x (XML) are supported, since leaf values are read up to the next tag.
+func ParseOFX(r io.Reader) ([]*Record, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read ofx: %w", err)
+	}
+
+	body := string(data)
+
+	start := strings.Index(strings.ToUpper(body), "<OFX>")
+	if start < 0 {
+		return nil, errors.InvalidInputError{Input: "missing <OFX> element"}
+	}
+
+	records := []*Record{}
+	account := ""
+	currency := ""
+
+	var transaction *ofxTransaction
+
+	for body = body[start:]; ; {
+		open := strings.IndexByte(body, '<')
+		if open < 0 {
+			break
+		}
+
+		end := strings.IndexByte(body[open:], '>')
+		if end < 0 {
+			return nil, errors.InvalidInputError{Input: "unterminated ofx tag"}
+		}
+
+		tag := strings.ToUpper(strings.TrimSpace(body[open+1 : open+end]))
+		body = body[open+end+1:]
+
+		next := strings.IndexByte(body, '<')
+		if next < 0 {
+			next = len(body)
+		}
+
+		value := strings.TrimSpace(html.UnescapeString(body[:next]))
+
+		switch {
+		case tag == "STMTTRN":
+			transaction = &ofxTransaction{}
+		case tag == "/STMTTRN" && transaction != nil:
+			record, err := transaction.toRecord(account, currency)
+			if err != nil {
+				return nil, fmt.Errorf("transaction %d: %w", len(records)+1, err)
+			}
+
+			records = append(records, record)
+			transaction = nil
+		case transaction != nil:
+			transaction.set(tag, value)
+		case tag == "ACCTID":
+			// Transfers list the other account inside STMTTRN, so only the statement's account is used.
+			account = value
+		case tag == "CURDEF":
+			// Every amount in the statement is in its default currency.
+			currency = value
+		}
+	}
+
+	return records, nil
+}
+
+type ofxTransaction struct {
+	trnType, posted, amount, fitID, name, memo string
+}
+
+// ofxKinds maps the OFX transaction types that aren't spending to the kind of expenditure.
+var ofxKinds = map[string]model.Kind{ //nolint:gochecknoglobals
+	"XFER":      model.KindTransfer,
+	"DIRECTDEP": model.KindIncome,
+	"DIV":       model.KindIncome,
+	"INT":       model.KindIncome,
+}
+
+func (t *ofxTransaction) set(tag, value string) {
+	switch tag {
+	case "TRNTYPE":
+		t.trnType = strings.ToUpper(value)
+	case "DTPOSTED":
+		t.posted = value
+	case "TRNAMT":
+		t.amount = value
+	case "FITID":
+		t.fitID = value
+	case "NAME":
+		t.name = value
+	case "MEMO":
+		t.memo = value
+	}
+}
+
+func (t *ofxTransaction) toRecord(account, currency string) (*Record, error) {
+	// Dates look like 20250314120000.000[-5:EST]; only the day is kept.
+	if len(t.posted) < len(ofxDateLayout) {
+		return nil, errors.InvalidInputError{Input: "DTPOSTED " + t.posted}
+	}
+
+	date, err := time.ParseInLocation(ofxDateLayout, t.posted[:len(ofxDateLayout)], time.UTC)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse date: %w", err)
+	}
+
+	amount, err := model.ParseMoney(strings.ReplaceAll(t.amount, ",", "."))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse amount: %w", err)
+	}
+
+	name, comment := t.name, t.memo
+	if name == "" {
+		name, comment = t.memo, ""
+	}
+
+	return &Record{
+		Date: date,
+		// OFX amounts are signed from the account holder's point of view, so purchases are negative.
+		Amount:     -amount,
+		Name:       name,
+		Comment:    comment,
+		ExternalID: t.fitID,
+		Account:    account,
+		Kind:       ofxKinds[t.trnType],
+		Currency:   currency,
+	}, nil
+}
+
+const ofxDateLayout = "20060102"
@@ -0,0 +1,124 @@
+package importer_test
+
+import (
+	"os"
+	"strings"
+	"testing"
+	"time"
+	"yaba/internal/importer"
+	"yaba/internal/model"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseOFX(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name     string
+		file     string
+		expected []*importer.Record
+	}{
+		{
+			name: "ofx 1.x sgml",
+			file: "testdata/statement.qfx",
+			expected: []*importer.Record{
+				{
+					Date:       time.Date(2025, 3, 14, 0, 0, 0, 0, time.UTC),
+					Amount:     model.MoneyFromFloat(4.5),
+					Name:       "SQ *BLUE BOTTLE 1234 SF",
+					Comment:    "Coffee & pastry",
+					ExternalID: "2025031400001",
+					Account:    "4510XXXXXXXX1234",
+					Currency:   "CAD",
+				},
+				{
+					Date:       time.Date(2025, 3, 15, 0, 0, 0, 0, time.UTC),
+					Amount:     model.MoneyFromFloat(-100),
+					Name:       "PAYMENT - THANK YOU",
+					ExternalID: "2025031500002",
+					Account:    "4510XXXXXXXX1234",
+					Currency:   "CAD",
+				},
+			},
+		},
+		{
+			name: "ofx 2.x xml",
+			file: "testdata/statement.ofx",
+			expected: []*importer.Record{
+				{
+					Date:       time.Date(2025, 1, 5, 0, 0, 0, 0, time.UTC),
+					Amount:     model.MoneyFromFloat(250),
+					Name:       "CREDIT CARD PAYMENT",
+					ExternalID: "A-1",
+					Account:    "9876543",
+					Currency:   "CAD",
+					Kind:       model.KindTransfer,
+				},
+				{
+					Date:       time.Date(2025, 1, 7, 0, 0, 0, 0, time.UTC),
+					Amount:     model.MoneyFromFloat(61.27),
+					Name:       "COSTCO WHOLESALE #123",
+					ExternalID: "A-2",
+					Account:    "9876543",
+					Currency:   "CAD",
+				},
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			f, err := os.Open(tc.file)
+			require.NoError(t, err)
+
+			defer func() { _ = f.Close() }()
+
+			records, err := importer.ParseOFX(f)
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, records)
+		})
+	}
+}
+
+func TestParseOFXErrors(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name string
+		data string
+		err  string
+	}{
+		{
+			name: "not ofx",
+			data: "date,amount\n",
+			err:  "missing <OFX> element",
+		},
+		{
+			name: "unterminated tag",
+			data: "<OFX><STMTTRN><DTPOSTED",
+			err:  "unterminated ofx tag",
+		},
+		{
+			name: "bad date",
+			data: "<OFX><STMTTRN><DTPOSTED>2025<TRNAMT>1</STMTTRN></OFX>",
+			err:  "transaction 1: invalid input value: DTPOSTED 2025",
+		},
+		{
+			name: "bad amount",
+			data: "<OFX><STMTTRN><DTPOSTED>20250101<TRNAMT>one</STMTTRN></OFX>",
+			err:  "transaction 1: failed to parse amount",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := importer.ParseOFX(strings.NewReader(tc.data))
+			require.ErrorContains(t, err, tc.err)
+		})
+	}
+}
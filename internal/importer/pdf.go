@@ -0,0 +1,82 @@
+package importer
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"yaba/internal/database"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/ledongthuc/pdf"
+)
+
+// ExtractPDFText returns the text of each row of a PDF, from the top of the first page to the
+// bottom of the last.
+func ExtractPDFText(r io.ReaderAt, size int64) ([]string, error) {
+	reader, err := pdf.NewReader(r, size)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read pdf: %w", err)
+	}
+
+	var lines []string
+
+	for i := 1; i <= reader.NumPage(); i++ {
+		page := reader.Page(i)
+		if page.V.IsNull() {
+			continue
+		}
+
+		rows, err := page.GetTextByRow()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read page %d: %w", i, err)
+		}
+
+		for _, row := range rows {
+			words := make([]string, 0, len(row.Content))
+			for _, text := range row.Content {
+				words = append(words, text.S)
+			}
+
+			if line := strings.Join(strings.Fields(strings.Join(words, " ")), " "); line != "" {
+				lines = append(lines, line)
+			}
+		}
+	}
+
+	return lines, nil
+}
+
+// ParsePDFStatement reads a credit card statement using the layout registered for the issuer of
+// the payment method's card.
+func ParsePDFStatement(
+	ctx context.Context,
+	pool *pgxpool.Pool,
+	r io.Reader,
+	method uuid.UUID,
+) (*Statement, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read pdf: %w", err)
+	}
+
+	lines, err := ExtractPDFText(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, err
+	}
+
+	issuer := ""
+
+	if method != uuid.Nil {
+		paymentMethod, err := database.GetPaymentMethod(ctx, pool, method)
+		if err != nil {
+			return nil, err
+		}
+
+		issuer = paymentMethod.Rewards.Issuer
+	}
+
+	return LayoutForIssuer(issuer).Parse(lines), nil
+}
@@ -0,0 +1,59 @@
+package importer_test
+
+import (
+	"os"
+	"testing"
+	"time"
+	"yaba/internal/importer"
+	"yaba/internal/model"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestExtractPDFText(t *testing.T) {
+	t.Parallel()
+
+	f, err := os.Open("testdata/statement.pdf")
+	require.NoError(t, err)
+
+	defer func() { _ = f.Close() }()
+
+	info, err := f.Stat()
+	require.NoError(t, err)
+
+	lines, err := importer.ExtractPDFText(f, info.Size())
+	require.NoError(t, err)
+	require.Equal(t, []string{
+		"Example Bank Visa Statement",
+		"Statement Date: March 20, 2025",
+		"MAR 14 MAR 15 SQ *BLUE BOTTLE 1234 SF 4.50",
+		"MAR 15 MAR 15 PAYMENT - THANK YOU 100.00 CR",
+		"DEC 30 DEC 31 CORNER STORE (12.00)",
+		"MAR 16 MAR 17 ILLEGIBLE ROW",
+		"Total new charges 4.50",
+	}, lines)
+
+	statement := importer.DefaultLayout().Parse(lines)
+	require.Equal(t, []string{"MAR 16 MAR 17 ILLEGIBLE ROW"}, statement.Unparsed)
+	require.Equal(t, []*importer.Record{
+		{Date: time.Date(2025, 3, 14, 0, 0, 0, 0, time.UTC), Amount: model.MoneyFromFloat(4.5),
+			Name: "SQ *BLUE BOTTLE 1234 SF"},
+		{Date: time.Date(2025, 3, 15, 0, 0, 0, 0, time.UTC), Amount: model.MoneyFromFloat(-100), Name: "PAYMENT - THANK YOU"},
+		{Date: time.Date(2024, 12, 30, 0, 0, 0, 0, time.UTC), Amount: model.MoneyFromFloat(-12), Name: "CORNER STORE"},
+	}, statement.Records)
+}
+
+func TestExtractPDFTextNotAPDF(t *testing.T) {
+	t.Parallel()
+
+	f, err := os.Open("testdata/bank.csv")
+	require.NoError(t, err)
+
+	defer func() { _ = f.Close() }()
+
+	info, err := f.Stat()
+	require.NoError(t, err)
+
+	_, err = importer.ExtractPDFText(f, info.Size())
+	require.ErrorContains(t, err, "failed to read pdf")
+}
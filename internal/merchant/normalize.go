@@ -0,0 +1,64 @@
+// Package merchant derives canonical merchant names from the raw names on bank statements.
+package merchant
+
+import (
+	"strings"
+	"unicode"
+)
+
+// processorPrefixes are added to merchant names by payment processors.
+var processorPrefixes = []string{ //nolint:gochecknoglobals
+	"SQ *", "SQ*", "TST* ", "TST*", "SP * ", "SP *", "PAYPAL *", "PP*", "GOOGLE *", "APL*", "IC* ",
+}
+
+// Normalize returns the key that identifies the merchant in a raw statement name. Processor
+// prefixes, store numbers, punctuation and a trailing region code are removed, so
+// "SQ *BLUE BOTTLE 1234 SF" becomes "BLUE BOTTLE". It returns an empty string if nothing is left.
+func Normalize(name string) string {
+	name = strings.ToUpper(strings.TrimSpace(name))
+
+	for _, prefix := range processorPrefixes {
+		if strings.HasPrefix(name, prefix) {
+			name = name[len(prefix):]
+
+			break
+		}
+	}
+
+	words := strings.FieldsFunc(name, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '\'' && r != '&'
+	})
+
+	kept := make([]string, 0, len(words))
+	hadNumber := false
+
+	for _, word := range words {
+		if strings.ContainsFunc(word, unicode.IsDigit) {
+			hadNumber = true
+
+			continue
+		}
+
+		kept = append(kept, word)
+	}
+
+	// A two letter word after a store number is usually a city or province code.
+	if hadNumber && len(kept) > 1 && len([]rune(kept[len(kept)-1])) == 2 {
+		kept = kept[:len(kept)-1]
+	}
+
+	return strings.Join(kept, " ")
+}
+
+// DisplayName title-cases a normalized merchant key.
+func DisplayName(key string) string {
+	words := strings.Fields(strings.ToLower(key))
+
+	for i, word := range words {
+		runes := []rune(word)
+		runes[0] = unicode.ToUpper(runes[0])
+		words[i] = string(runes)
+	}
+
+	return strings.Join(words, " ")
+}
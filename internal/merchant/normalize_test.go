@@ -0,0 +1,43 @@
+package merchant_test
+
+import (
+	"testing"
+	"yaba/internal/merchant"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestNormalize(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name     string
+		expected string
+	}{
+		{"SQ *BLUE BOTTLE 1234 SF", "BLUE BOTTLE"},
+		{"Sq *Blue Bottle", "BLUE BOTTLE"},
+		{"TST* JOE'S DINER", "JOE'S DINER"},
+		{"COSTCO WHOLESALE #123 TORONTO ON", "COSTCO WHOLESALE TORONTO"},
+		{"PAYPAL *STEAM GAMES", "STEAM GAMES"},
+		{"AMZN Mktp CA*2K1AB3CD4", "AMZN MKTP"},
+		{"A&W", "A&W"},
+		{"UBER EATS", "UBER EATS"},
+		{"  ", ""},
+		{"#1234", ""},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			require.Equal(t, tc.expected, merchant.Normalize(tc.name))
+		})
+	}
+}
+
+func TestDisplayName(t *testing.T) {
+	t.Parallel()
+
+	require.Equal(t, "Blue Bottle", merchant.DisplayName("BLUE BOTTLE"))
+	require.Equal(t, "Joe's Diner", merchant.DisplayName("JOE'S DINER"))
+	require.Empty(t, merchant.DisplayName(""))
+}
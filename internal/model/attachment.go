@@ -0,0 +1,23 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Attachment is a file attached to an expenditure, like a receipt. The file itself is kept in a
+// blob store under BlobKey.
+type Attachment struct {
+	ID            uuid.UUID `db:"id"`
+	Owner         uuid.UUID `db:"owner"`
+	ExpenditureID int       `db:"expenditure_id"`
+	Filename      string    `db:"filename"`
+	ContentType   string    `db:"content_type"`
+	Size          int64     `db:"size"`
+	CreatedTime   time.Time `db:"created"`
+}
+
+func (a *Attachment) BlobKey() string {
+	return a.Owner.String() + "/" + a.ID.String()
+}
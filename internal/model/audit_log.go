@@ -0,0 +1,23 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// AuditEntry is a change to a budget, income, expense, payment method or expenditure. Before and After
+// are the row as JSON, and are nil when the row was inserted or deleted.
+type AuditEntry struct {
+	ID         int64     `db:"id"`
+	Owner      uuid.UUID `db:"owner"`
+	EntityType string    `db:"entity_type"`
+	// EntityID is the ID of the row, or the ID of the budget for incomes.
+	EntityID string    `db:"entity_id"`
+	Action   string    `db:"action"`
+	Before   []byte    `db:"before"`
+	After    []byte    `db:"after"`
+	Changed  time.Time `db:"changed"`
+	// TransactionID is shared by the changes that were saved together.
+	TransactionID int64 `db:"transaction_id"`
+}
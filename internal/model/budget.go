@@ -1,6 +1,9 @@
 package model
 
 import (
+	"database/sql"
+	"time"
+
 	"github.com/google/uuid"
 )
 
@@ -12,34 +15,47 @@ type Budget struct {
 	Name     string    `db:"name"`
 	Incomes  []*Income
 	Expenses []*Expense
+	// Period is how the budget is divided into periods, starting on PeriodStart. PeriodDays is the
+	// length of custom periods.
+	Period      BudgetPeriodType `db:"period"`
+	PeriodStart time.Time        `db:"period_start"`
+	PeriodDays  int              `db:"period_days"`
+	// DeletedAt is when the budget was moved to the trash.
+	DeletedAt sql.NullTime `db:"deleted_at"`
 }
 
 type Income struct {
-	Owner  uuid.UUID `db:"owner"  json:"-"`
-	Source string    `db:"source" json:"source"`
-	Amount float64   `db:"amount" json:"amount"`
+	Owner    uuid.UUID `db:"owner"    json:"-"`
+	Source   string    `db:"source"   json:"source"`
+	Amount   Money     `db:"amount"   json:"amount"`
+	Currency string    `db:"currency" json:"currency"`
 }
 
 type Expense struct {
 	BudgetID uuid.UUID `db:"budget_id" json:"-"`
 	ID       uuid.UUID `db:"id"        json:"id"`
 	Category string    `db:"category"  json:"category"`
-	Amount   float64   `db:"amount"    json:"amount"`
+	Amount   Money     `db:"amount"    json:"amount"`
 	Fixed    bool      `db:"is_fixed"  json:"isFixed"`
 	Slack    bool      `db:"is_slack"  json:"isSlack"`
+	Currency string    `db:"currency"  json:"currency"`
+	// Rollover carries what is left at the end of a period, or what was overspent, into the next one.
+	Rollover bool `db:"rollover" json:"rollover"`
 }
 
 func NewBudget(owner uuid.UUID, name string) *Budget {
 	return &Budget{
-		ID:       uuid.New(),
-		Owner:    owner,
-		Name:     name,
-		Incomes:  []*Income{},
-		Expenses: []*Expense{},
+		ID:          uuid.New(),
+		Owner:       owner,
+		Name:        name,
+		Incomes:     []*Income{},
+		Expenses:    []*Expense{},
+		Period:      BudgetPeriodMonthly,
+		PeriodStart: StartOfMonth(time.Now()),
 	}
 }
 
-func (b *Budget) SetBudgetIncome(source string, amount float64) {
+func (b *Budget) SetBudgetIncome(source string, amount Money) {
 	b.Incomes = append(b.Incomes, &Income{
 		Owner:  b.ID,
 		Source: source,
@@ -57,11 +73,11 @@ func (b *Budget) RemoveBudgetIncome(source string) {
 	}
 }
 
-func (b *Budget) SetFixedExpense(category string, amount float64) {
+func (b *Budget) SetFixedExpense(category string, amount Money) {
 	b.SetExpense(category, amount, true, false)
 }
 
-func (b *Budget) SetBasicExpense(category string, amount float64) {
+func (b *Budget) SetBasicExpense(category string, amount Money) {
 	b.SetExpense(category, amount, false, false)
 }
 
@@ -69,7 +85,7 @@ func (b *Budget) SetSlackExpense(category string) {
 	b.SetExpense(category, 0, false, true)
 }
 
-func (b *Budget) SetExpense(category string, amount float64, fixed, slack bool) {
+func (b *Budget) SetExpense(category string, amount Money, fixed, slack bool) {
 	b.Expenses = append(b.Expenses, &Expense{
 		BudgetID: b.ID,
 		Category: category,
@@ -0,0 +1,52 @@
+package model
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"yaba/errors"
+
+	"github.com/google/uuid"
+)
+
+// BudgetCursor is the position of a budget in the order of names. Budgets with the same name are
+// ordered by ID.
+type BudgetCursor struct {
+	Name string    `json:"n"`
+	ID   uuid.UUID `json:"i"`
+}
+
+// NewBudgetCursor returns the position of the budget.
+func NewBudgetCursor(budget *Budget) *BudgetCursor {
+	return &BudgetCursor{Name: budget.Name, ID: budget.ID}
+}
+
+// ParseBudgetCursor parses a cursor returned by String.
+func ParseBudgetCursor(cursor string) (*BudgetCursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return nil, fmt.Errorf("invalid cursor: %w", errors.InvalidInputError{Input: cursor})
+	}
+
+	var parsed BudgetCursor
+	if err = json.Unmarshal(data, &parsed); err != nil || parsed.ID == uuid.Nil {
+		return nil, fmt.Errorf("invalid cursor: %w", errors.InvalidInputError{Input: cursor})
+	}
+
+	return &parsed, nil
+}
+
+// String returns the cursor as an opaque string.
+func (c *BudgetCursor) String() string {
+	data, _ := json.Marshal(c) //nolint:errchkjson
+
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// BudgetPage is a page of budgets ordered by name.
+type BudgetPage struct {
+	Budgets     []*Budget
+	HasNextPage bool
+	// TotalCount is the number of budgets on all pages.
+	TotalCount int
+}
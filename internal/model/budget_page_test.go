@@ -0,0 +1,28 @@
+package model_test
+
+import (
+	"testing"
+	"yaba/errors"
+	"yaba/internal/model"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBudgetCursor(t *testing.T) {
+	t.Parallel()
+
+	budget := model.NewBudget(uuid.New(), "Café \"Olé\"")
+
+	cursor := model.NewBudgetCursor(budget)
+	require.Equal(t, &model.BudgetCursor{Name: budget.Name, ID: budget.ID}, cursor)
+
+	parsed, err := model.ParseBudgetCursor(cursor.String())
+	require.NoError(t, err)
+	require.Equal(t, cursor, parsed)
+
+	for _, invalid := range []string{"", "not a cursor", "e30", "W10"} {
+		_, err := model.ParseBudgetCursor(invalid)
+		require.ErrorAs(t, err, &errors.InvalidInputError{}, invalid)
+	}
+}
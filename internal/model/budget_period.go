@@ -0,0 +1,169 @@
+package model
+
+import (
+	"fmt"
+	"slices"
+	"time"
+	"yaba/errors"
+
+	"github.com/google/uuid"
+)
+
+type BudgetPeriodType string
+
+const (
+	// BudgetPeriodMonthly periods are calendar months.
+	BudgetPeriodMonthly  BudgetPeriodType = "MONTHLY"
+	BudgetPeriodBiweekly BudgetPeriodType = "BIWEEKLY"
+	// BudgetPeriodCustom periods are PeriodDays long.
+	BudgetPeriodCustom BudgetPeriodType = "CUSTOM"
+)
+
+const biweeklyPeriodDays = 14
+
+// maxBudgetPeriods is how many periods of a budget can be read. Each one depends on the ones before
+// it, so they are all read at once.
+const maxBudgetPeriods = 1000
+
+// BudgetPeriod is a period of a budget, with a snapshot of the budget's expenses.
+type BudgetPeriod struct {
+	ID       uuid.UUID `db:"id"`
+	BudgetID uuid.UUID `db:"budget_id"`
+	// Start and End are the first and last days of the period.
+	Start       time.Time `db:"start_date"`
+	End         time.Time `db:"end_date"`
+	Allocations []*BudgetAllocation
+}
+
+// BudgetAllocation is the amount of an expense of a budget for a period.
+type BudgetAllocation struct {
+	PeriodID  uuid.UUID `db:"period_id"`
+	ExpenseID uuid.UUID `db:"expense_id"`
+	Category  string    `db:"category"`
+	Amount    Money     `db:"amount"`
+	Currency  string    `db:"currency"`
+	Rollover  bool      `db:"rollover"`
+	// HomeAmount is Amount in the home currency, at the rate on the first day of the period.
+	HomeAmount Money `db:"-"`
+	// Spent is what was spent on the expense's category in the period, in the home currency.
+	Spent Money `db:"-"`
+	// RolledOver is what was left of the expense at the end of the previous period, or what was
+	// overspent if it is negative.
+	RolledOver Money `db:"-"`
+}
+
+// Remaining is what is left of the expense in the home currency, or what was overspent if it is
+// negative.
+func (a *BudgetAllocation) Remaining() Money {
+	return a.HomeAmount + a.RolledOver - a.Spent
+}
+
+// StartOfMonth returns the first day of the date's month, in UTC.
+func StartOfMonth(date time.Time) time.Time {
+	return time.Date(date.Year(), date.Month(), 1, 0, 0, 0, 0, time.UTC)
+}
+
+// ValidatePeriod returns an error if the budget can't be divided into periods.
+func (b *Budget) ValidatePeriod() error {
+	switch b.Period {
+	case BudgetPeriodMonthly, BudgetPeriodBiweekly:
+		return nil
+	case BudgetPeriodCustom:
+		if b.PeriodDays < 1 {
+			return fmt.Errorf("custom periods must be at least a day: %w", errors.InvalidInputError{Input: b.PeriodDays})
+		}
+
+		return nil
+	default:
+		return fmt.Errorf("unknown budget period: %w", errors.InvalidInputError{Input: b.Period})
+	}
+}
+
+// Periods returns the budget's periods from the first, which starts on PeriodStart, up to the one
+// containing the date, without IDs or allocations. Monthly periods start with PeriodStart's month.
+func (b *Budget) Periods(date time.Time) ([]*BudgetPeriod, error) {
+	if err := b.ValidatePeriod(); err != nil {
+		return nil, err
+	}
+
+	date = time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, time.UTC)
+	start := b.firstPeriodStart()
+
+	if date.Before(start) {
+		return nil, fmt.Errorf("the budget's periods start on %s: %w",
+			start.Format(time.DateOnly), errors.NoSuchElementError{Element: date.Format(time.DateOnly)})
+	}
+
+	var periods []*BudgetPeriod
+
+	for !start.After(date) {
+		if len(periods) == maxBudgetPeriods {
+			return nil, fmt.Errorf("the budget has more than %d periods: %w", maxBudgetPeriods,
+				errors.InvalidInputError{Input: date.Format(time.DateOnly)})
+		}
+
+		period := b.periodContaining(start)
+		periods = append(periods, period)
+		start = period.End.AddDate(0, 0, 1)
+	}
+
+	return periods, nil
+}
+
+// EndedPeriods returns the budget's periods that ended before the date, oldest first, without IDs or
+// allocations. Only the latest periods are returned if there are more than a budget can have.
+func (b *Budget) EndedPeriods(date time.Time) ([]*BudgetPeriod, error) {
+	if err := b.ValidatePeriod(); err != nil {
+		return nil, err
+	}
+
+	date = time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, time.UTC)
+	first := b.firstPeriodStart()
+
+	if date.Before(first) {
+		return nil, nil
+	}
+
+	var periods []*BudgetPeriod
+
+	for start := b.periodContaining(date).Start; start.After(first) && len(periods) < maxBudgetPeriods; {
+		period := b.periodContaining(start.AddDate(0, 0, -1))
+		periods = append(periods, period)
+		start = period.Start
+	}
+
+	slices.Reverse(periods)
+
+	return periods, nil
+}
+
+// firstPeriodStart returns the first day of the budget's first period.
+func (b *Budget) firstPeriodStart() time.Time {
+	start := time.Date(b.PeriodStart.Year(), b.PeriodStart.Month(), b.PeriodStart.Day(), 0, 0, 0, 0, time.UTC)
+	if b.Period == BudgetPeriodMonthly {
+		return StartOfMonth(start)
+	}
+
+	return start
+}
+
+// periodContaining returns the budget's period containing the date, which must be a UTC day that isn't
+// before the first period.
+func (b *Budget) periodContaining(date time.Time) *BudgetPeriod {
+	if b.Period == BudgetPeriodMonthly {
+		start := StartOfMonth(date)
+
+		return &BudgetPeriod{BudgetID: b.ID, Start: start, End: start.AddDate(0, 1, -1)}
+	}
+
+	days := b.PeriodDays
+	if b.Period == BudgetPeriodBiweekly {
+		days = biweeklyPeriodDays
+	}
+
+	first := b.firstPeriodStart()
+	elapsed := int(date.Sub(first) / (24 * time.Hour)) //nolint:mnd
+	start := first.AddDate(0, 0, elapsed-elapsed%days)
+
+	return &BudgetPeriod{BudgetID: b.ID, Start: start, End: start.AddDate(0, 0, days-1)}
+}
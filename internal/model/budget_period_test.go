@@ -0,0 +1,161 @@
+package model_test
+
+import (
+	"testing"
+	"time"
+	"yaba/errors"
+	"yaba/internal/model"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBudgetPeriods(t *testing.T) {
+	t.Parallel()
+
+	date := func(s string) time.Time {
+		parsed, err := time.Parse(time.DateOnly, s)
+		require.NoError(t, err)
+
+		return parsed
+	}
+
+	tests := []struct {
+		name     string
+		period   model.BudgetPeriodType
+		start    string
+		days     int
+		date     string
+		expected [][2]string
+	}{
+		{
+			name:   "monthly",
+			period: model.BudgetPeriodMonthly,
+			start:  "2025-01-15",
+			date:   "2025-03-31",
+			expected: [][2]string{
+				{"2025-01-01", "2025-01-31"},
+				{"2025-02-01", "2025-02-28"},
+				{"2025-03-01", "2025-03-31"},
+			},
+		},
+		{
+			name:   "biweekly",
+			period: model.BudgetPeriodBiweekly,
+			start:  "2025-01-03",
+			date:   "2025-01-31",
+			expected: [][2]string{
+				{"2025-01-03", "2025-01-16"},
+				{"2025-01-17", "2025-01-30"},
+				{"2025-01-31", "2025-02-13"},
+			},
+		},
+		{
+			name:   "custom",
+			period: model.BudgetPeriodCustom,
+			start:  "2025-01-01",
+			days:   10,
+			date:   "2025-01-10",
+			expected: [][2]string{
+				{"2025-01-01", "2025-01-10"},
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			budget := model.NewBudget(uuid.New(), tc.name)
+			budget.Period = tc.period
+			budget.PeriodStart = date(tc.start)
+			budget.PeriodDays = tc.days
+
+			periods, err := budget.Periods(date(tc.date))
+			require.NoError(t, err)
+
+			actual := make([][2]string, len(periods))
+			for i, period := range periods {
+				require.Equal(t, budget.ID, period.BudgetID)
+				actual[i] = [2]string{period.Start.Format(time.DateOnly), period.End.Format(time.DateOnly)}
+			}
+
+			require.Equal(t, tc.expected, actual)
+		})
+	}
+}
+
+func TestEndedBudgetPeriods(t *testing.T) {
+	t.Parallel()
+
+	budget := model.NewBudget(uuid.New(), "budget")
+	budget.Period = model.BudgetPeriodBiweekly
+	budget.PeriodStart = time.Date(2025, 1, 3, 0, 0, 0, 0, time.UTC)
+
+	ended, err := budget.EndedPeriods(time.Date(2025, 1, 31, 12, 0, 0, 0, time.UTC))
+	require.NoError(t, err)
+	require.Len(t, ended, 2)
+	require.Equal(t, time.Date(2025, 1, 3, 0, 0, 0, 0, time.UTC), ended[0].Start)
+	require.Equal(t, time.Date(2025, 1, 16, 0, 0, 0, 0, time.UTC), ended[0].End)
+	require.Equal(t, time.Date(2025, 1, 17, 0, 0, 0, 0, time.UTC), ended[1].Start)
+	require.Equal(t, time.Date(2025, 1, 30, 0, 0, 0, 0, time.UTC), ended[1].End)
+
+	// The first period hasn't ended.
+	ended, err = budget.EndedPeriods(time.Date(2025, 1, 16, 0, 0, 0, 0, time.UTC))
+	require.NoError(t, err)
+	require.Empty(t, ended)
+
+	budget.Period = model.BudgetPeriodMonthly
+	ended, err = budget.EndedPeriods(time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC))
+	require.NoError(t, err)
+	require.Len(t, ended, 2)
+	require.Equal(t, time.Date(2025, 2, 1, 0, 0, 0, 0, time.UTC), ended[1].Start)
+	require.Equal(t, time.Date(2025, 2, 28, 0, 0, 0, 0, time.UTC), ended[1].End)
+
+	// The first period hasn't started.
+	ended, err = budget.EndedPeriods(time.Date(2024, 12, 31, 0, 0, 0, 0, time.UTC))
+	require.NoError(t, err)
+	require.Empty(t, ended)
+
+	// Only the latest periods are kept when there are too many.
+	budget.Period = model.BudgetPeriodCustom
+	budget.PeriodDays = 1
+	ended, err = budget.EndedPeriods(budget.PeriodStart.AddDate(10, 0, 0))
+	require.NoError(t, err)
+	require.Len(t, ended, 1000)
+	require.Equal(t, budget.PeriodStart.AddDate(10, 0, -1), ended[999].Start)
+}
+
+func TestInvalidBudgetPeriods(t *testing.T) {
+	t.Parallel()
+
+	budget := model.NewBudget(uuid.New(), "budget")
+	budget.PeriodStart = time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)
+
+	_, err := budget.Periods(time.Date(2025, 2, 28, 0, 0, 0, 0, time.UTC))
+	require.ErrorAs(t, err, &errors.NoSuchElementError{})
+
+	budget.Period = model.BudgetPeriodCustom
+	_, err = budget.Periods(budget.PeriodStart)
+	require.ErrorAs(t, err, &errors.InvalidInputError{})
+
+	budget.PeriodDays = 1
+	_, err = budget.Periods(budget.PeriodStart.AddDate(10, 0, 0))
+	require.ErrorAs(t, err, &errors.InvalidInputError{})
+
+	budget.Period = "WEEKLY"
+	require.ErrorAs(t, budget.ValidatePeriod(), &errors.InvalidInputError{})
+}
+
+func TestBudgetAllocationRemaining(t *testing.T) {
+	t.Parallel()
+
+	allocation := &model.BudgetAllocation{
+		Amount:     model.MoneyFromFloat(250),
+		Currency:   "EUR",
+		HomeAmount: model.MoneyFromFloat(500),
+		RolledOver: model.MoneyFromFloat(-20),
+		Spent:      model.MoneyFromFloat(430.5),
+	}
+	require.Equal(t, model.MoneyFromFloat(49.5), allocation.Remaining())
+}
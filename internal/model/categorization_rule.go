@@ -0,0 +1,40 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type MatchType string
+
+const (
+	MatchTypeContains   MatchType = "CONTAINS"
+	MatchTypeEquals     MatchType = "EQUALS"
+	MatchTypeStartsWith MatchType = "STARTS_WITH"
+	MatchTypeRegex      MatchType = "REGEX"
+)
+
+// CategorizationRule assigns a budget category, reward category and payment method to
+// expenditures whose name matches the pattern. Empty values are left alone.
+type CategorizationRule struct {
+	ID    uuid.UUID `db:"id"`
+	Owner uuid.UUID `db:"owner"`
+	// Rules with a higher priority win when several rules match the same expenditure.
+	Priority       int       `db:"priority"`
+	MatchType      MatchType `db:"match_type"`
+	Pattern        string    `db:"pattern"`
+	BudgetCategory string    `db:"budget_category"`
+	RewardCategory string    `db:"reward_category"`
+	Method         uuid.UUID `db:"method"`
+	CreatedTime    time.Time `db:"created"`
+}
+
+// FieldChange is a field of an expenditure that was changed by a categorization rule or a bulk
+// update.
+type FieldChange struct {
+	ExpenditureID int
+	Field         string
+	Before        string
+	After         string
+}
@@ -0,0 +1,11 @@
+package model
+
+import "time"
+
+// ExchangeRate is the price of one unit of the base currency in the quote currency on a day.
+type ExchangeRate struct {
+	Date  time.Time `db:"date"`
+	Base  string    `db:"base"`
+	Quote string    `db:"quote"`
+	Rate  float64   `db:"rate"`
+}
@@ -1,6 +1,7 @@
 package model
 
 import (
+	"database/sql"
 	"time"
 
 	"github.com/google/uuid"
@@ -10,7 +11,7 @@ type Expenditure struct {
 	ID             int       `db:"id"`
 	Owner          uuid.UUID `db:"owner"`
 	Name           string    `db:"name"`
-	Amount         float64   `db:"amount"`
+	Amount         Money     `db:"amount"`
 	Date           time.Time `db:"date"`
 	Method         uuid.UUID `db:"method"`
 	BudgetCategory string    `db:"budget_category"`
@@ -19,12 +20,64 @@ type Expenditure struct {
 	CreatedTime    time.Time `db:"created"`
 	Source         string    `db:"source"`
 	ExpenseID      uuid.UUID `db:"expense_id"`
+	ExternalID     string    `db:"external_id"`
+	// Fingerprint identifies the transaction so that importing it again is a no-op. It is set on
+	// insert and kept when the expenditure is edited.
+	Fingerprint string    `db:"fingerprint"`
+	BatchID     uuid.UUID `db:"batch_id"`
+	MerchantID  uuid.UUID `db:"merchant_id"`
+	// RefundOf is the ID of the purchase that this credit reverses.
+	RefundOf sql.NullInt64 `db:"refund_of"`
+	Kind     Kind          `db:"kind"`
+	// TransferID is the ID of the other side of a transfer, e.g. the chequing withdrawal that paid
+	// off a credit card.
+	TransferID sql.NullInt64 `db:"transfer_id"`
+	// Currency is the ISO 4217 code of the amount, or empty for the owner's home currency.
+	Currency string `db:"currency"`
+}
+
+// ExpenditurePatch is a change to many expenditures at once. Nil fields are left as they are.
+type ExpenditurePatch struct {
+	BudgetCategory *string
+	RewardCategory *string
+	Method         *uuid.UUID
+	Comment        *string
+	// Tags replace the tags of the expenditures if not nil. An empty slice removes all of them.
+	Tags []string
+}
+
+// Kind tells spending apart from money coming in and money moving between the user's own accounts.
+type Kind string
+
+const (
+	KindExpense  Kind = "EXPENSE"
+	KindIncome   Kind = "INCOME"
+	KindTransfer Kind = "TRANSFER"
+)
+
+// ImportResult reports the outcome of saving a batch of expenditures.
+type ImportResult struct {
+	Batch    *ImportBatch
+	Inserted []*Expenditure
+	// Skipped is the number of expenditures that were already saved.
+	Skipped            int
+	PossibleDuplicates []*PossibleDuplicate
+}
+
+// PossibleDuplicate is a newly inserted expenditure that resembles expenditures saved before it,
+// e.g. the same transaction imported from two different statement formats.
+type PossibleDuplicate struct {
+	Expenditure *Expenditure
+	Matches     []*Expenditure
 }
 
 type ExpenditureSummary struct {
 	Category  string    `db:"category"`
-	Amount    float64   `db:"amount"`
+	Amount    Money     `db:"amount"`
 	StartDate time.Time `db:"date"`
+	// Unconverted is the number of expenditures left out of Amount because no exchange rate for their
+	// currency is known.
+	Unconverted int `db:"unconverted"`
 }
 
 type Aggregation string
@@ -43,10 +96,21 @@ const (
 	TimespanYear  Timespan = "YEAR"
 )
 
+// RefundAttribution is the period that a refund is counted in. Refunds always count towards the
+// category of the purchase they reverse.
+type RefundAttribution string
+
+const (
+	RefundAttributionOriginal   RefundAttribution = "ORIGINAL"
+	RefundAttributionRefundDate RefundAttribution = "REFUND_DATE"
+)
+
 type GroupBy string
 
 const (
 	GroupByNone           GroupBy = "NONE"
 	GroupByBudgetCategory GroupBy = "BUDGET_CATEGORY"
 	GroupByRewardCategory GroupBy = "REWARD_CATEGORY"
+	GroupByMerchant       GroupBy = "MERCHANT"
+	GroupByTag            GroupBy = "TAG"
 )
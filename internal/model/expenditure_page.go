@@ -0,0 +1,91 @@
+package model
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"time"
+	"yaba/errors"
+)
+
+// ExpenditureFilter selects a user's expenditures between two dates. Nil and empty fields don't
+// filter.
+type ExpenditureFilter struct {
+	// Search is in the syntax of the search package.
+	Search        *string
+	Category      *string
+	PaymentMethod *string
+	Source        *string
+	// Tags must all be on the expenditure.
+	Tags  []string
+	Since time.Time
+	Until time.Time
+}
+
+// ExpenditureSort is the order of a page of expenditures. Expenditures with the same value are
+// ordered by ID.
+type ExpenditureSort string
+
+const (
+	ExpenditureSortDate    ExpenditureSort = "DATE"
+	ExpenditureSortAmount  ExpenditureSort = "AMOUNT"
+	ExpenditureSortName    ExpenditureSort = "NAME"
+	ExpenditureSortCreated ExpenditureSort = "CREATED"
+)
+
+// ExpenditureCursor is the position of an expenditure in a sort order. Pages continue after the
+// position rather than after a number of rows, so they don't shift when expenditures are added.
+type ExpenditureCursor struct {
+	Sort ExpenditureSort `json:"s"`
+	// Value is the expenditure's value of the sort, as text.
+	Value string `json:"v"`
+	ID    int    `json:"i"`
+}
+
+// NewExpenditureCursor returns the position of the expenditure in the sort order.
+func NewExpenditureCursor(sort ExpenditureSort, expenditure *Expenditure) *ExpenditureCursor {
+	var value string
+
+	switch sort {
+	case ExpenditureSortAmount:
+		value = expenditure.Amount.String()
+	case ExpenditureSortName:
+		value = expenditure.Name
+	case ExpenditureSortCreated:
+		value = expenditure.CreatedTime.UTC().Format(time.RFC3339Nano)
+	default:
+		value = expenditure.Date.Format(time.DateOnly)
+	}
+
+	return &ExpenditureCursor{Sort: sort, Value: value, ID: expenditure.ID}
+}
+
+// ParseExpenditureCursor parses a cursor returned by String.
+func ParseExpenditureCursor(cursor string) (*ExpenditureCursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return nil, fmt.Errorf("invalid cursor: %w", errors.InvalidInputError{Input: cursor})
+	}
+
+	var parsed ExpenditureCursor
+	if err = json.Unmarshal(data, &parsed); err != nil {
+		return nil, fmt.Errorf("invalid cursor: %w", errors.InvalidInputError{Input: cursor})
+	}
+
+	return &parsed, nil
+}
+
+// String returns the cursor as an opaque string.
+func (c *ExpenditureCursor) String() string {
+	data, _ := json.Marshal(c) //nolint:errchkjson
+
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// ExpenditurePage is a page of expenditures in a sort order.
+type ExpenditurePage struct {
+	Expenditures []*Expenditure
+	HasNextPage  bool
+	// TotalCount is the number of expenditures on all pages.
+	TotalCount int
+}
@@ -0,0 +1,43 @@
+package model_test
+
+import (
+	"testing"
+	"time"
+	"yaba/errors"
+	"yaba/internal/model"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestExpenditureCursor(t *testing.T) {
+	t.Parallel()
+
+	expenditure := &model.Expenditure{
+		ID:          42,
+		Name:        "Café \"Olé\"",
+		Amount:      model.NewMoney(12, 34),
+		Date:        time.Date(2025, 3, 14, 0, 0, 0, 0, time.UTC),
+		CreatedTime: time.Date(2025, 3, 15, 9, 26, 53, 589000, time.FixedZone("EST", -5*60*60)),
+	}
+
+	expected := map[model.ExpenditureSort]string{
+		model.ExpenditureSortDate:    "2025-03-14",
+		model.ExpenditureSortAmount:  "12.34",
+		model.ExpenditureSortName:    "Café \"Olé\"",
+		model.ExpenditureSortCreated: "2025-03-15T14:26:53.000589Z",
+	}
+
+	for sort, value := range expected {
+		cursor := model.NewExpenditureCursor(sort, expenditure)
+		require.Equal(t, &model.ExpenditureCursor{Sort: sort, Value: value, ID: 42}, cursor)
+
+		parsed, err := model.ParseExpenditureCursor(cursor.String())
+		require.NoError(t, err)
+		require.Equal(t, cursor, parsed)
+	}
+
+	for _, invalid := range []string{"", "not a cursor", "e30=", "W10"} {
+		_, err := model.ParseExpenditureCursor(invalid)
+		require.ErrorAs(t, err, &errors.InvalidInputError{}, invalid)
+	}
+}
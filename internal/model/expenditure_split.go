@@ -0,0 +1,14 @@
+package model
+
+import "github.com/google/uuid"
+
+// ExpenditureSplit is the part of an expenditure's amount that counts towards a budget category.
+// The splits of an expenditure add up to its amount.
+type ExpenditureSplit struct {
+	ID             int       `db:"id"`
+	ExpenditureID  int       `db:"expenditure_id"`
+	Owner          uuid.UUID `db:"owner"`
+	BudgetCategory string    `db:"budget_category"`
+	ExpenseID      uuid.UUID `db:"expense_id"`
+	Amount         Money     `db:"amount"`
+}
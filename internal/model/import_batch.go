@@ -0,0 +1,19 @@
+package model
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ImportBatch records a set of expenditures saved together so they can be reviewed and undone.
+type ImportBatch struct {
+	ID          uuid.UUID    `db:"id"`
+	Owner       uuid.UUID    `db:"owner"`
+	Source      string       `db:"source"`
+	RowCount    int          `db:"row_count"`
+	StartDate   sql.NullTime `db:"start_date"`
+	EndDate     sql.NullTime `db:"end_date"`
+	CreatedTime time.Time    `db:"created"`
+}
@@ -0,0 +1,16 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Merchant groups the expenditures whose normalized statement names are one of its aliases.
+type Merchant struct {
+	ID          uuid.UUID `db:"id"`
+	Owner       uuid.UUID `db:"owner"`
+	Name        string    `db:"name"`
+	CreatedTime time.Time `db:"created"`
+	Aliases     []string
+}
@@ -0,0 +1,208 @@
+package model
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"math/big"
+	"strconv"
+	"strings"
+	"yaba/errors"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// MoneyScale is the number of decimal places kept in a Money, the same as the NUMERIC(20,4) amount
+// columns.
+const MoneyScale = 4
+
+// moneyUnits is the number of Money units in a whole currency unit.
+const moneyUnits = 10000
+
+// Money is an exact amount in ten-thousandths of a currency unit. It is read from and written to
+// NUMERIC columns without going through float64, so sums computed in postgres round-trip exactly.
+// Use NewMoney, ParseMoney or MoneyFromFloat rather than converting numbers directly.
+type Money int64
+
+// NewMoney returns whole units plus cents, e.g. NewMoney(12, 34) is 12.34 and NewMoney(-1, 50) is
+// -1.50.
+func NewMoney(units, cents int64) Money {
+	if units < 0 {
+		cents = -cents
+	}
+
+	return Money(units*moneyUnits + cents*(moneyUnits/100)) //nolint:mnd
+}
+
+// MoneyFromFloat rounds the float to the nearest ten-thousandth.
+func MoneyFromFloat(f float64) Money {
+	return Money(math.Round(f * moneyUnits))
+}
+
+// ParseMoney parses a decimal number such as "-1234.56". Digits past the fourth decimal place are
+// rounded half away from zero.
+func ParseMoney(s string) (Money, error) {
+	s = strings.TrimSpace(s)
+
+	rat, ok := new(big.Rat).SetString(s)
+	if !ok || strings.ContainsAny(s, "/eE") {
+		return 0, errors.InvalidInputError{Input: "amount " + s}
+	}
+
+	return moneyFromRat(rat)
+}
+
+func moneyFromRat(rat *big.Rat) (Money, error) {
+	scaled := new(big.Rat).Mul(rat, new(big.Rat).SetInt64(moneyUnits))
+
+	// Round half away from zero
+	num, den := scaled.Num(), scaled.Denom()
+	quotient, remainder := new(big.Int).QuoRem(num, den, new(big.Int))
+
+	if remainder.Sign() != 0 && new(big.Int).Mul(new(big.Int).Abs(remainder), big.NewInt(2)).Cmp(den) >= 0 {
+		quotient.Add(quotient, big.NewInt(int64(num.Sign())))
+	}
+
+	if !quotient.IsInt64() {
+		return 0, errors.InvalidInputError{Input: "amount " + rat.FloatString(MoneyScale)}
+	}
+
+	return Money(quotient.Int64()), nil
+}
+
+// MulRat returns the amount multiplied by the ratio, such as an exchange rate, rounded half away from
+// zero to the nearest ten-thousandth. The product is exact, unlike going through Float64.
+func (m Money) MulRat(rat *big.Rat) (Money, error) {
+	return moneyFromRat(new(big.Rat).Mul(big.NewRat(int64(m), moneyUnits), rat))
+}
+
+// Float64 returns the amount as a float, for ratios and display only.
+func (m Money) Float64() float64 {
+	return float64(m) / moneyUnits
+}
+
+// Abs returns the amount without its sign.
+func (m Money) Abs() Money {
+	if m < 0 {
+		return -m
+	}
+
+	return m
+}
+
+// Round rounds the amount half away from zero to whole cents.
+func (m Money) Round() Money {
+	const unitsPerCent = moneyUnits / 100
+
+	if m < 0 {
+		return -(-m + unitsPerCent/2) / unitsPerCent * unitsPerCent
+	}
+
+	return (m + unitsPerCent/2) / unitsPerCent * unitsPerCent
+}
+
+// String formats the amount with at least two decimal places, e.g. "12.50" or "0.1234".
+func (m Money) String() string {
+	sign := ""
+	units := int64(m)
+
+	if units < 0 {
+		sign = "-"
+		units = -units
+	}
+
+	fraction := fmt.Sprintf("%04d", units%moneyUnits)
+	fraction = strings.TrimRight(fraction, "0")
+
+	for len(fraction) < 2 {
+		fraction += "0"
+	}
+
+	return sign + strconv.FormatInt(units/moneyUnits, 10) + "." + fraction
+}
+
+// ScanNumeric implements pgtype.NumericScanner.
+func (m *Money) ScanNumeric(n pgtype.Numeric) error {
+	if !n.Valid {
+		return errors.InvalidInputError{Input: "NULL amount"}
+	}
+
+	if n.NaN || n.InfinityModifier != pgtype.Finite {
+		return errors.InvalidInputError{Input: "amount NaN or infinity"}
+	}
+
+	rat := new(big.Rat).SetInt(n.Int)
+	exp := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(abs(n.Exp))), nil) //nolint:mnd
+
+	if n.Exp >= 0 {
+		rat.Mul(rat, new(big.Rat).SetInt(exp))
+	} else {
+		rat.Quo(rat, new(big.Rat).SetInt(exp))
+	}
+
+	money, err := moneyFromRat(rat)
+	if err != nil {
+		return err
+	}
+
+	*m = money
+
+	return nil
+}
+
+// NumericValue implements pgtype.NumericValuer.
+func (m Money) NumericValue() (pgtype.Numeric, error) {
+	return pgtype.Numeric{Int: big.NewInt(int64(m)), Exp: -MoneyScale, Valid: true}, nil
+}
+
+// MarshalJSON writes the amount as a JSON number.
+func (m Money) MarshalJSON() ([]byte, error) {
+	return []byte(m.String()), nil
+}
+
+// UnmarshalJSON reads the amount from a JSON number or string.
+func (m *Money) UnmarshalJSON(data []byte) error {
+	money, err := ParseMoney(strings.Trim(string(data), `"`))
+	if err != nil {
+		return err
+	}
+
+	*m = money
+
+	return nil
+}
+
+// MarshalGQL writes the amount as a decimal string so that clients don't lose precision.
+func (m Money) MarshalGQL(w io.Writer) {
+	_, _ = io.WriteString(w, strconv.Quote(m.String()))
+}
+
+// UnmarshalGQL reads the amount from a decimal string or a number.
+func (m *Money) UnmarshalGQL(v any) error {
+	var err error
+
+	switch v := v.(type) {
+	case string:
+		*m, err = ParseMoney(v)
+	case interface{ String() string }: // json.Number
+		*m, err = ParseMoney(v.String())
+	case int:
+		*m = NewMoney(int64(v), 0)
+	case int64:
+		*m = NewMoney(v, 0)
+	case float64:
+		*m = MoneyFromFloat(v)
+	default:
+		err = errors.InvalidInputError{Input: v}
+	}
+
+	return err
+}
+
+func abs(n int32) int32 {
+	if n < 0 {
+		return -n
+	}
+
+	return n
+}
@@ -0,0 +1,128 @@
+package model_test
+
+import (
+	"encoding/json"
+	"math/big"
+	"strings"
+	"testing"
+	"yaba/internal/model"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseMoney(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		input    string
+		expected model.Money
+	}{
+		{"12.34", model.NewMoney(12, 34)},
+		{" -1.5 ", model.NewMoney(-1, 50)},
+		{"0.1", model.NewMoney(0, 10)},
+		{"100", model.NewMoney(100, 0)},
+		{"0.00005", 1},
+		{"-0.00005", -1},
+		{"0.00004", 0},
+		{"1.23456", 12346},
+	}
+
+	for _, test := range tests {
+		money, err := model.ParseMoney(test.input)
+		require.NoError(t, err, test.input)
+		require.Equal(t, test.expected, money, test.input)
+	}
+
+	for _, input := range []string{"", "abc", "1/3", "1e3", "$5", "99999999999999999999"} {
+		_, err := model.ParseMoney(input)
+		require.Error(t, err, input)
+	}
+}
+
+func TestMoneyArithmetic(t *testing.T) {
+	t.Parallel()
+
+	// Adding ten cents ten times is exactly a dollar
+	var total model.Money
+	for range 10 {
+		total += model.NewMoney(0, 10)
+	}
+
+	require.Equal(t, model.NewMoney(1, 0), total)
+	require.Equal(t, "1.00", total.String())
+
+	require.Equal(t, "-12.50", model.NewMoney(-12, 50).String())
+	require.Equal(t, "0.1234", model.Money(1234).String())
+	require.Equal(t, "0.01", model.Money(125).Round().String())
+	require.Equal(t, "-0.02", model.Money(-150).Round().String())
+	require.Equal(t, model.NewMoney(3, 0), model.NewMoney(-3, 0).Abs())
+	require.InDelta(t, 12.34, model.NewMoney(12, 34).Float64(), 0.00001)
+}
+
+func TestMoneyMulRat(t *testing.T) {
+	t.Parallel()
+
+	// 5.00 at 1.10005 is exactly 5.50025, which float64 rounds down to 5.5002
+	rate, ok := new(big.Rat).SetString("1.10005")
+	require.True(t, ok)
+
+	money, err := model.NewMoney(5, 0).MulRat(rate)
+	require.NoError(t, err)
+	require.Equal(t, model.Money(55003), money)
+	require.Equal(t, model.Money(55002), model.MoneyFromFloat(model.NewMoney(5, 0).Float64()*1.10005))
+
+	money, err = model.NewMoney(-5, 0).MulRat(rate)
+	require.NoError(t, err)
+	require.Equal(t, model.Money(-55003), money)
+
+	_, err = model.NewMoney(900000000000000, 0).MulRat(big.NewRat(1000, 1))
+	require.Error(t, err)
+}
+
+func TestMoneyNumeric(t *testing.T) {
+	t.Parallel()
+
+	money := model.NewMoney(-42, 7)
+	numeric, err := money.NumericValue()
+	require.NoError(t, err)
+
+	var scanned model.Money
+	require.NoError(t, scanned.ScanNumeric(numeric))
+	require.Equal(t, money, scanned)
+
+	// Results of division have more decimal places than a Money
+	require.NoError(t, scanned.ScanNumeric(pgtype.Numeric{Int: big.NewInt(333333333), Exp: -9, Valid: true}))
+	require.Equal(t, model.Money(3333), scanned)
+
+	require.NoError(t, scanned.ScanNumeric(pgtype.Numeric{Int: big.NewInt(5), Exp: 2, Valid: true}))
+	require.Equal(t, model.NewMoney(500, 0), scanned)
+
+	require.Error(t, scanned.ScanNumeric(pgtype.Numeric{}))
+	require.Error(t, scanned.ScanNumeric(pgtype.Numeric{NaN: true, Valid: true}))
+}
+
+func TestMoneyEncoding(t *testing.T) {
+	t.Parallel()
+
+	var gql strings.Builder
+	model.NewMoney(10, 50).MarshalGQL(&gql)
+	require.Equal(t, `"10.50"`, gql.String())
+
+	var money model.Money
+	for _, input := range []any{"10.5", json.Number("10.50"), 10.5} {
+		require.NoError(t, money.UnmarshalGQL(input))
+		require.Equal(t, model.NewMoney(10, 50), money)
+	}
+
+	require.NoError(t, money.UnmarshalGQL(int64(7)))
+	require.Equal(t, model.NewMoney(7, 0), money)
+	require.Error(t, money.UnmarshalGQL(true))
+
+	data, err := json.Marshal(struct{ Amount model.Money }{model.NewMoney(-3, 25)})
+	require.NoError(t, err)
+	require.JSONEq(t, `{"Amount": -3.25}`, string(data))
+
+	require.NoError(t, json.Unmarshal([]byte(`"4.20"`), &money))
+	require.Equal(t, model.NewMoney(4, 20), money)
+}
@@ -12,7 +12,10 @@ type PaymentMethod struct {
 	AcquiredDate sql.NullTime `db:"acquired_date"`
 	CancelByDate sql.NullTime `db:"cancel_by_date"`
 	CardType     uuid.UUID    `db:"card_type"`
+	AccountID    string       `db:"account_id"`
 	Rewards      *RewardCard
+	// DeletedAt is when the payment method was moved to the trash.
+	DeletedAt sql.NullTime `db:"deleted_at"`
 }
 
 type RewardCard struct {
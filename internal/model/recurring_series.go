@@ -0,0 +1,37 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type Cadence string
+
+const (
+	CadenceWeekly  Cadence = "WEEKLY"
+	CadenceMonthly Cadence = "MONTHLY"
+	CadenceYearly  Cadence = "YEARLY"
+)
+
+// RecurringSeries is a charge from one merchant that repeats at a regular cadence, like a
+// subscription.
+type RecurringSeries struct {
+	ID         uuid.UUID `db:"id"`
+	Owner      uuid.UUID `db:"owner"`
+	MerchantID uuid.UUID `db:"merchant_id"`
+	Name       string    `db:"name"`
+	Cadence    Cadence   `db:"cadence"`
+	// Amount is the latest charge, which is expected again on NextDate.
+	Amount         Money     `db:"amount"`
+	PreviousAmount Money     `db:"previous_amount"`
+	Occurrences    int       `db:"occurrences"`
+	LastDate       time.Time `db:"last_date"`
+	NextDate       time.Time `db:"next_date"`
+	// PriceChanged is set if the latest charge differs from the one before it.
+	PriceChanged bool `db:"price_changed"`
+	// Missed is set if the expected charge is overdue. It depends on the current date, so it is not
+	// stored.
+	Missed      bool
+	CreatedTime time.Time `db:"created"`
+}
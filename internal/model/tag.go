@@ -0,0 +1,16 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Tag is a label on any number of expenditures, e.g. "trip-japan-2026" or "reimbursable". Unlike
+// categories, an expenditure can have several tags.
+type Tag struct {
+	ID          uuid.UUID `db:"id"`
+	Owner       uuid.UUID `db:"owner"`
+	Name        string    `db:"name"`
+	CreatedTime time.Time `db:"created"`
+}
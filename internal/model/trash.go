@@ -0,0 +1,19 @@
+package model
+
+import "time"
+
+type TrashType string
+
+const (
+	TrashTypeBudget        TrashType = "BUDGET"
+	TrashTypePaymentMethod TrashType = "PAYMENT_METHOD"
+	TrashTypeExpenditure   TrashType = "EXPENDITURE"
+)
+
+// TrashItem is a deleted budget, payment method or expenditure that can still be restored.
+type TrashItem struct {
+	ID        string    `db:"id"`
+	Type      TrashType `db:"type"`
+	Name      string    `db:"name"`
+	DeletedAt time.Time `db:"deleted_at"`
+}
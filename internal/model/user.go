@@ -2,8 +2,14 @@ package model
 
 import "github.com/google/uuid"
 
+// DefaultCurrency is the home currency of new users.
+const DefaultCurrency = "CAD"
+
 type User struct {
 	ID           uuid.UUID `db:"id"`
 	Username     string    `db:"username"`
 	PasswordHash []byte    `db:"password_hash"`
+	// HomeCurrency is the currency that amounts are totalled in, and the currency of amounts that
+	// don't specify one.
+	HomeCurrency string `db:"home_currency"`
 }
@@ -0,0 +1,251 @@
+// Package recurring finds charges that repeat at a regular cadence, such as subscriptions and
+// bills, in a user's expenditures.
+package recurring
+
+import (
+	"slices"
+	"time"
+	"yaba/internal/model"
+
+	"github.com/google/uuid"
+)
+
+// similarAmount is how much a charge can differ from the previous one in a group of similar charges,
+// as a fraction of the previous one. Larger changes start a new group, which continues the series of
+// the group before it if it picks up where that one stopped, at the same cadence.
+const similarAmount = 0.2
+
+// endedAfterMissed is the number of charges that can be missed before a series is considered ended.
+const endedAfterMissed = 3
+
+type cadence struct {
+	cadence model.Cadence
+	// days is the typical number of days between charges and tolerance how far off a charge can be.
+	days, tolerance int
+	// minOccurrences is the number of charges needed to detect the cadence.
+	minOccurrences int
+	next           func(time.Time) time.Time
+}
+
+var cadences = []*cadence{ //nolint:gochecknoglobals
+	{
+		cadence:        model.CadenceWeekly,
+		days:           7,
+		tolerance:      1,
+		minOccurrences: 3,
+		next:           func(t time.Time) time.Time { return t.AddDate(0, 0, 7) },
+	},
+	{
+		cadence:        model.CadenceMonthly,
+		days:           30,
+		tolerance:      4,
+		minOccurrences: 3,
+		next:           func(t time.Time) time.Time { return t.AddDate(0, 1, 0) },
+	},
+	{
+		cadence:        model.CadenceYearly,
+		days:           365,
+		tolerance:      10,
+		minOccurrences: 2,
+		next:           func(t time.Time) time.Time { return t.AddDate(1, 0, 0) },
+	},
+}
+
+// Detect finds the recurring series in the expenditures. Charges are grouped by merchant and by
+// similar amount, and a series is the longest run of the latest charges in a group that are a
+// week, a month or a year apart. A run continues into the group of an earlier price if that group's
+// charges stopped one cadence before the run started, so a series survives price changes of any size.
+// Series that have missed several charges are considered ended and left out. Expenditures without a
+// merchant, credits and anything other than expenses are ignored.
+func Detect(expenditures []*model.Expenditure, today time.Time) []*model.RecurringSeries {
+	merchants := []uuid.UUID{}
+	byMerchant := make(map[uuid.UUID][]*model.Expenditure)
+
+	for _, e := range expenditures {
+		if e.MerchantID == uuid.Nil || e.Amount <= 0 || (e.Kind != "" && e.Kind != model.KindExpense) {
+			continue
+		}
+
+		if _, ok := byMerchant[e.MerchantID]; !ok {
+			merchants = append(merchants, e.MerchantID)
+		}
+
+		byMerchant[e.MerchantID] = append(byMerchant[e.MerchantID], e)
+	}
+
+	series := []*model.RecurringSeries{}
+
+	for _, merchantID := range merchants {
+		charges := byMerchant[merchantID]
+		slices.SortStableFunc(charges, func(a, b *model.Expenditure) int {
+			return a.Date.Compare(b.Date)
+		})
+
+		series = append(series, detectMerchantSeries(groupBySimilarAmount(charges), today)...)
+	}
+
+	slices.SortStableFunc(series, func(a, b *model.RecurringSeries) int {
+		return a.NextDate.Compare(b.NextDate)
+	})
+
+	return series
+}
+
+// Missed reports whether the next charge of the series is overdue.
+func Missed(series *model.RecurringSeries, today time.Time) bool {
+	for _, c := range cadences {
+		if c.cadence == series.Cadence {
+			return today.After(series.NextDate.AddDate(0, 0, c.tolerance))
+		}
+	}
+
+	return false
+}
+
+// groupBySimilarAmount splits charges ordered by date into groups where each charge is close to the
+// previous one in its group.
+func groupBySimilarAmount(charges []*model.Expenditure) [][]*model.Expenditure {
+	groups := [][]*model.Expenditure{}
+
+	for _, charge := range charges {
+		found := false
+
+		for i, group := range groups {
+			last := group[len(group)-1].Amount
+			if (charge.Amount - last).Abs().Float64() <= similarAmount*last.Float64() {
+				groups[i] = append(group, charge)
+				found = true
+
+				break
+			}
+		}
+
+		if !found {
+			groups = append(groups, []*model.Expenditure{charge})
+		}
+	}
+
+	return groups
+}
+
+// detectMerchantSeries returns the series formed by one merchant's groups of similar charges. The
+// groups with the latest charges are tried first, so that they take over the groups of earlier prices.
+func detectMerchantSeries(groups [][]*model.Expenditure, today time.Time) []*model.RecurringSeries {
+	slices.SortStableFunc(groups, func(a, b []*model.Expenditure) int {
+		return b[len(b)-1].Date.Compare(a[len(a)-1].Date)
+	})
+
+	series := []*model.RecurringSeries{}
+	used := make([]bool, len(groups))
+
+	for i := range groups {
+		if used[i] {
+			continue
+		}
+
+		for _, c := range cadences {
+			charges, earlier := continueSeries(groups, used, i, c)
+			if len(charges) < c.minOccurrences {
+				continue
+			}
+
+			for _, j := range earlier {
+				used[j] = true
+			}
+
+			if s := newSeries(charges, c, today); s != nil {
+				series = append(series, s)
+			}
+
+			break
+		}
+
+		used[i] = true
+	}
+
+	return series
+}
+
+// continueSeries returns the charges of the trailing run of group i at the cadence, preceded by the
+// runs of the unused groups of earlier prices it continues, oldest first, and the indexes of those
+// groups.
+func continueSeries(
+	groups [][]*model.Expenditure,
+	used []bool,
+	i int,
+	c *cadence,
+) ([]*model.Expenditure, []int) {
+	group := groups[i]
+	charges := group[len(group)-trailingRun(group, c):]
+
+	var earlier []int
+
+	for {
+		found := -1
+
+		for j, other := range groups {
+			if used[j] || j == i || slices.Contains(earlier, j) {
+				continue
+			}
+
+			if isCadenceApart(other[len(other)-1], charges[0], c) {
+				found = j
+
+				break
+			}
+		}
+
+		if found < 0 {
+			return charges, earlier
+		}
+
+		other := groups[found]
+		charges = append(slices.Clone(other[len(other)-trailingRun(other, c):]), charges...)
+		earlier = append(earlier, found)
+	}
+}
+
+// newSeries returns the series of the charges, which are the cadence apart, or nil if it has ended.
+func newSeries(charges []*model.Expenditure, c *cadence, today time.Time) *model.RecurringSeries {
+	last := charges[len(charges)-1]
+	previous := charges[len(charges)-2]
+	next := c.next(last.Date)
+
+	if today.After(next.AddDate(0, 0, endedAfterMissed*c.days)) {
+		return nil
+	}
+
+	s := &model.RecurringSeries{
+		Owner:          last.Owner,
+		MerchantID:     last.MerchantID,
+		Name:           last.Name,
+		Cadence:        c.cadence,
+		Amount:         last.Amount,
+		PreviousAmount: previous.Amount,
+		Occurrences:    len(charges),
+		LastDate:       last.Date,
+		NextDate:       next,
+		PriceChanged:   last.Amount.Round() != previous.Amount.Round(),
+	}
+	s.Missed = Missed(s, today)
+
+	return s
+}
+
+// trailingRun counts the latest charges that are the cadence apart from each other.
+func trailingRun(charges []*model.Expenditure, c *cadence) int {
+	run := 1
+
+	for i := len(charges) - 1; i > 0 && isCadenceApart(charges[i-1], charges[i], c); i-- {
+		run++
+	}
+
+	return run
+}
+
+// isCadenceApart reports whether the later charge came the cadence after the earlier one.
+func isCadenceApart(earlier, later *model.Expenditure, c *cadence) bool {
+	days := int(later.Date.Sub(earlier.Date).Hours() / 24)
+
+	return days >= c.days-c.tolerance && days <= c.days+c.tolerance
+}
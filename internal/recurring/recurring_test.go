@@ -0,0 +1,115 @@
+package recurring_test
+
+import (
+	"testing"
+	"time"
+	"yaba/internal/model"
+	"yaba/internal/recurring"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDetect(t *testing.T) {
+	t.Parallel()
+
+	netflix, gym, amazon, domain, coffee, spotify := uuid.New(), uuid.New(), uuid.New(), uuid.New(), uuid.New(),
+		uuid.New()
+	day := func(month time.Month, d int) time.Time { return time.Date(2024, month, d, 0, 0, 0, 0, time.UTC) }
+	charge := func(merchant uuid.UUID, name string, amount float64, date time.Time) *model.Expenditure {
+		return &model.Expenditure{MerchantID: merchant, Name: name, Amount: model.MoneyFromFloat(amount), Date: date,
+			Kind: model.KindExpense}
+	}
+
+	expenditures := []*model.Expenditure{
+		// Monthly, with a price increase
+		charge(netflix, "NETFLIX.COM", 15.49, day(1, 15)),
+		charge(netflix, "NETFLIX.COM", 15.49, day(2, 15)),
+		charge(netflix, "NETFLIX.COM", 15.49, day(3, 14)),
+		charge(netflix, "NETFLIX.COM", 17.99, day(4, 15)),
+		// Monthly, with a price increase of more than half
+		charge(spotify, "SPOTIFY", 9.99, day(1, 5)),
+		charge(spotify, "SPOTIFY", 9.99, day(2, 5)),
+		charge(spotify, "SPOTIFY", 9.99, day(3, 5)),
+		charge(spotify, "SPOTIFY", 15.99, day(4, 5)),
+		// Monthly, but the May charge never came
+		charge(gym, "GOODLIFE", 50, day(1, 1)),
+		charge(gym, "GOODLIFE", 50, day(2, 1)),
+		charge(gym, "GOODLIFE", 50, day(3, 1)),
+		charge(gym, "GOODLIFE", 50, day(4, 1)),
+		// Irregular shopping next to a monthly subscription at the same merchant
+		charge(amazon, "AMAZON PRIME", 9.99, day(2, 3)),
+		charge(amazon, "AMAZON", 120, day(2, 10)),
+		charge(amazon, "AMAZON PRIME", 9.99, day(3, 3)),
+		charge(amazon, "AMAZON", 35, day(3, 21)),
+		charge(amazon, "AMAZON PRIME", 9.99, day(4, 3)),
+		// Yearly
+		charge(domain, "NAMECHEAP", 12, day(1, 20).AddDate(-1, 0, 0)),
+		charge(domain, "NAMECHEAP", 12, day(1, 22)),
+		// Weekly, but only twice so far
+		charge(coffee, "BLUE BOTTLE", 5, day(4, 1)),
+		charge(coffee, "BLUE BOTTLE", 5, day(4, 8)),
+		// No merchant
+		charge(uuid.Nil, "CASH", 20, day(1, 1)),
+		charge(uuid.Nil, "CASH", 20, day(2, 1)),
+		charge(uuid.Nil, "CASH", 20, day(3, 1)),
+	}
+
+	series := recurring.Detect(expenditures, day(5, 10))
+	require.Len(t, series, 5)
+
+	byMerchant := make(map[uuid.UUID]*model.RecurringSeries)
+	for _, s := range series {
+		byMerchant[s.MerchantID] = s
+	}
+
+	require.Equal(t, &model.RecurringSeries{
+		MerchantID:     netflix,
+		Name:           "NETFLIX.COM",
+		Cadence:        model.CadenceMonthly,
+		Amount:         model.MoneyFromFloat(17.99),
+		PreviousAmount: model.MoneyFromFloat(15.49),
+		Occurrences:    4,
+		LastDate:       day(4, 15),
+		NextDate:       day(5, 15),
+		PriceChanged:   true,
+	}, byMerchant[netflix])
+
+	// The price change doesn't start a new series
+	require.Equal(t, model.MoneyFromFloat(15.99), byMerchant[spotify].Amount)
+	require.Equal(t, model.MoneyFromFloat(9.99), byMerchant[spotify].PreviousAmount)
+	require.Equal(t, 4, byMerchant[spotify].Occurrences)
+	require.True(t, byMerchant[spotify].PriceChanged)
+
+	require.True(t, byMerchant[gym].Missed)
+	require.Equal(t, day(5, 1), byMerchant[gym].NextDate)
+	require.False(t, byMerchant[gym].PriceChanged)
+
+	require.Equal(t, "AMAZON PRIME", byMerchant[amazon].Name)
+	require.Equal(t, 3, byMerchant[amazon].Occurrences)
+
+	require.Equal(t, model.CadenceYearly, byMerchant[domain].Cadence)
+	require.Equal(t, day(1, 22).AddDate(1, 0, 0), byMerchant[domain].NextDate)
+
+	// Ordered by the next expected charge
+	require.Equal(t, gym, series[0].MerchantID)
+	require.Equal(t, domain, series[4].MerchantID)
+
+	// Series that missed several charges have ended
+	series = recurring.Detect(expenditures, day(9, 1))
+	require.Len(t, series, 1)
+	require.Equal(t, domain, series[0].MerchantID)
+}
+
+func TestMissed(t *testing.T) {
+	t.Parallel()
+
+	series := &model.RecurringSeries{
+		Cadence:  model.CadenceWeekly,
+		NextDate: time.Date(2024, 4, 8, 0, 0, 0, 0, time.UTC),
+	}
+
+	require.False(t, recurring.Missed(series, series.NextDate))
+	require.False(t, recurring.Missed(series, series.NextDate.AddDate(0, 0, 1)))
+	require.True(t, recurring.Missed(series, series.NextDate.AddDate(0, 0, 2)))
+}
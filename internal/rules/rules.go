@@ -0,0 +1,146 @@
+// Package rules assigns categories and payment methods to expenditures using the owner's
+// categorization rules.
+package rules
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+	"yaba/errors"
+	"yaba/internal/model"
+
+	"github.com/google/uuid"
+)
+
+const (
+	FieldBudgetCategory = "budget_category"
+	FieldRewardCategory = "reward_category"
+	FieldMethod         = "method"
+)
+
+// Engine applies a set of categorization rules in priority order.
+type Engine struct {
+	rules []*compiledRule
+}
+
+type compiledRule struct {
+	*model.CategorizationRule
+	matches func(name string) bool
+}
+
+// NewEngine compiles the rules. Rules with equal priority are tried in the order given.
+func NewEngine(rules []*model.CategorizationRule) (*Engine, error) {
+	compiled := make([]*compiledRule, len(rules))
+
+	for i, rule := range rules {
+		matches, err := compile(rule)
+		if err != nil {
+			return nil, err
+		}
+
+		compiled[i] = &compiledRule{CategorizationRule: rule, matches: matches}
+	}
+
+	slices.SortStableFunc(compiled, func(a, b *compiledRule) int {
+		return b.Priority - a.Priority
+	})
+
+	return &Engine{rules: compiled}, nil
+}
+
+// Validate returns an InvalidInputError if the rule can't match anything or doesn't assign
+// anything.
+func Validate(rule *model.CategorizationRule) error {
+	if rule.BudgetCategory == "" && rule.RewardCategory == "" && rule.Method == uuid.Nil {
+		return fmt.Errorf("rule must assign a category or payment method: %w",
+			errors.InvalidInputError{Input: rule.Pattern})
+	}
+
+	_, err := compile(rule)
+
+	return err
+}
+
+func compile(rule *model.CategorizationRule) (func(string) bool, error) {
+	if rule.Pattern == "" {
+		return nil, fmt.Errorf("rule pattern is empty: %w", errors.InvalidInputError{Input: rule.Pattern})
+	}
+
+	pattern := strings.ToLower(rule.Pattern)
+
+	switch rule.MatchType {
+	case model.MatchTypeContains:
+		return func(name string) bool { return strings.Contains(strings.ToLower(name), pattern) }, nil
+	case model.MatchTypeEquals:
+		return func(name string) bool { return strings.EqualFold(strings.TrimSpace(name), rule.Pattern) }, nil
+	case model.MatchTypeStartsWith:
+		return func(name string) bool { return strings.HasPrefix(strings.ToLower(name), pattern) }, nil
+	case model.MatchTypeRegex:
+		re, err := regexp.Compile("(?i)" + rule.Pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid rule pattern: %w", errors.InvalidInputError{Input: rule.Pattern})
+		}
+
+		return re.MatchString, nil
+	default:
+		return nil, fmt.Errorf("unknown match type: %w", errors.InvalidInputError{Input: rule.MatchType})
+	}
+}
+
+// Apply sets each field of the expenditure from the highest priority matching rule that assigns
+// it and returns the fields that changed. Fields that are already set are only replaced if
+// overwrite is true.
+func (e *Engine) Apply(expenditure *model.Expenditure, overwrite bool) []*model.FieldChange {
+	var budgetCategory, rewardCategory, method *compiledRule
+
+	for _, rule := range e.rules {
+		if !rule.matches(expenditure.Name) {
+			continue
+		}
+
+		if budgetCategory == nil && rule.BudgetCategory != "" {
+			budgetCategory = rule
+		}
+
+		if rewardCategory == nil && rule.RewardCategory != "" {
+			rewardCategory = rule
+		}
+
+		if method == nil && rule.Method != uuid.Nil {
+			method = rule
+		}
+	}
+
+	var changes []*model.FieldChange
+
+	change := func(field, before, after string) {
+		changes = append(changes, &model.FieldChange{
+			ExpenditureID: expenditure.ID,
+			Field:         field,
+			Before:        before,
+			After:         after,
+		})
+	}
+
+	if budgetCategory != nil && replaces(expenditure.BudgetCategory, budgetCategory.BudgetCategory, "", overwrite) {
+		change(FieldBudgetCategory, expenditure.BudgetCategory, budgetCategory.BudgetCategory)
+		expenditure.BudgetCategory = budgetCategory.BudgetCategory
+	}
+
+	if rewardCategory != nil && replaces(expenditure.RewardCategory, rewardCategory.RewardCategory, "", overwrite) {
+		change(FieldRewardCategory, expenditure.RewardCategory, rewardCategory.RewardCategory)
+		expenditure.RewardCategory = rewardCategory.RewardCategory
+	}
+
+	if method != nil && replaces(expenditure.Method, method.Method, uuid.Nil, overwrite) {
+		change(FieldMethod, expenditure.Method.String(), method.Method.String())
+		expenditure.Method = method.Method
+	}
+
+	return changes
+}
+
+func replaces[T comparable](current, value, blank T, overwrite bool) bool {
+	return current != value && (current == blank || overwrite)
+}
@@ -0,0 +1,149 @@
+package rules_test
+
+import (
+	"testing"
+	"yaba/internal/model"
+	"yaba/internal/rules"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+func TestApply(t *testing.T) {
+	t.Parallel()
+
+	card := uuid.New()
+	otherCard := uuid.New()
+
+	engine, err := rules.NewEngine([]*model.CategorizationRule{
+		{MatchType: model.MatchTypeContains, Pattern: "costco", BudgetCategory: "Groceries",
+			RewardCategory: "grocery", Method: card},
+		// Gas at Costco is still gas
+		{Priority: 10, MatchType: model.MatchTypeRegex, Pattern: `costco (gas|fuel)`, BudgetCategory: "Gas"},
+		{MatchType: model.MatchTypeStartsWith, Pattern: "uber eats", BudgetCategory: "Restaurants"},
+		{MatchType: model.MatchTypeStartsWith, Pattern: "uber", BudgetCategory: "Transit"},
+		{MatchType: model.MatchTypeEquals, Pattern: "Netflix", RewardCategory: "recurring bill", Method: otherCard},
+	})
+	require.NoError(t, err)
+
+	testCases := []struct {
+		name        string
+		expenditure model.Expenditure
+		overwrite   bool
+		expected    model.Expenditure
+		changes     []string
+	}{
+		{
+			name:        "all fields",
+			expenditure: model.Expenditure{Name: "COSTCO WHOLESALE #123"},
+			expected: model.Expenditure{Name: "COSTCO WHOLESALE #123", BudgetCategory: "Groceries",
+				RewardCategory: "grocery", Method: card},
+			changes: []string{rules.FieldBudgetCategory, rules.FieldRewardCategory, rules.FieldMethod},
+		},
+		{
+			name:        "higher priority wins",
+			expenditure: model.Expenditure{Name: "Costco Gas W1234"},
+			expected: model.Expenditure{Name: "Costco Gas W1234", BudgetCategory: "Gas",
+				RewardCategory: "grocery", Method: card},
+			changes: []string{rules.FieldBudgetCategory, rules.FieldRewardCategory, rules.FieldMethod},
+		},
+		{
+			name:        "first rule wins on equal priority",
+			expenditure: model.Expenditure{Name: "UBER EATS TORONTO"},
+			expected:    model.Expenditure{Name: "UBER EATS TORONTO", BudgetCategory: "Restaurants"},
+			changes:     []string{rules.FieldBudgetCategory},
+		},
+		{
+			name:        "existing values are kept",
+			expenditure: model.Expenditure{Name: "costco", BudgetCategory: "Household", Method: otherCard},
+			expected: model.Expenditure{Name: "costco", BudgetCategory: "Household",
+				RewardCategory: "grocery", Method: otherCard},
+			changes: []string{rules.FieldRewardCategory},
+		},
+		{
+			name:        "existing values are overwritten",
+			expenditure: model.Expenditure{Name: "costco", BudgetCategory: "Household", Method: otherCard},
+			overwrite:   true,
+			expected: model.Expenditure{Name: "costco", BudgetCategory: "Groceries",
+				RewardCategory: "grocery", Method: card},
+			changes: []string{rules.FieldBudgetCategory, rules.FieldRewardCategory, rules.FieldMethod},
+		},
+		{
+			name:        "equals ignores case but not extra words",
+			expenditure: model.Expenditure{Name: "NETFLIX.COM"},
+			expected:    model.Expenditure{Name: "NETFLIX.COM"},
+		},
+		{
+			name:        "unchanged",
+			expenditure: model.Expenditure{Name: "netflix", RewardCategory: "recurring bill", Method: otherCard},
+			overwrite:   true,
+			expected:    model.Expenditure{Name: "netflix", RewardCategory: "recurring bill", Method: otherCard},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			expenditure := tc.expenditure
+			changes := engine.Apply(&expenditure, tc.overwrite)
+			require.Equal(t, tc.expected, expenditure)
+
+			fields := make([]string, len(changes))
+			for i, change := range changes {
+				fields[i] = change.Field
+			}
+
+			require.ElementsMatch(t, tc.changes, fields)
+		})
+	}
+}
+
+func TestValidate(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name string
+		rule model.CategorizationRule
+		err  string
+	}{
+		{
+			name: "valid",
+			rule: model.CategorizationRule{MatchType: model.MatchTypeRegex, Pattern: "^amzn", BudgetCategory: "Shopping"},
+		},
+		{
+			name: "empty pattern",
+			rule: model.CategorizationRule{MatchType: model.MatchTypeContains, BudgetCategory: "Shopping"},
+			err:  "rule pattern is empty",
+		},
+		{
+			name: "bad regex",
+			rule: model.CategorizationRule{MatchType: model.MatchTypeRegex, Pattern: "(", BudgetCategory: "Shopping"},
+			err:  "invalid rule pattern",
+		},
+		{
+			name: "unknown match type",
+			rule: model.CategorizationRule{MatchType: "FUZZY", Pattern: "amzn", BudgetCategory: "Shopping"},
+			err:  "unknown match type",
+		},
+		{
+			name: "no actions",
+			rule: model.CategorizationRule{MatchType: model.MatchTypeContains, Pattern: "amzn"},
+			err:  "rule must assign a category or payment method",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			err := rules.Validate(&tc.rule)
+			if tc.err == "" {
+				require.NoError(t, err)
+			} else {
+				require.ErrorContains(t, err, tc.err)
+				require.ErrorContains(t, err, "invalid input")
+			}
+		})
+	}
+}
@@ -0,0 +1,241 @@
+// Package search parses the search syntax of the expenditures query into SQL conditions on the
+// expenditure table.
+//
+// A search is a list of terms separated by spaces, all of which must match:
+//
+//	coffee                words match the start of words of the name or comment, ignoring case, so
+//	                      coffee finds COFFEEHOUSE
+//	"gift card"           phrases match consecutive words
+//	amount:>50            amounts compare with >, >=, <, <= or =
+//	date:2025-03          dates are a year, month or day, and also compare with >, >=, < and <=
+//	method:"Visa"         the display name of the payment method
+//	category:groceries    the budget category; category:"" matches uncategorized expenditures
+//
+// Any term can be negated with a leading "-", e.g. -starbucks or -category:travel.
+package search
+
+import (
+	"fmt"
+	"strings"
+	"time"
+	"unicode"
+	"yaba/errors"
+	"yaba/internal/model"
+
+	"github.com/Masterminds/squirrel"
+	"github.com/google/uuid"
+)
+
+// Config is the text search configuration of the expenditure search column. It doesn't stem words
+// or drop stop words, so merchant names match as written.
+const Config = "simple"
+
+type term struct {
+	negated bool
+	// key is the operator, or empty for words and phrases.
+	key    string
+	value  string
+	quoted bool
+}
+
+// Parse returns the conditions that the user's expenditures must meet to match the search.
+func Parse(input string, owner uuid.UUID) (squirrel.Sqlizer, error) {
+	conditions := squirrel.And{}
+
+	for _, t := range tokenize(input) {
+		condition, err := t.condition(owner)
+		if err != nil {
+			return nil, err
+		}
+
+		if condition == nil {
+			continue
+		}
+
+		if t.negated {
+			condition = not{condition}
+		}
+
+		conditions = append(conditions, condition)
+	}
+
+	return conditions, nil
+}
+
+func tokenize(input string) []*term {
+	var terms []*term
+
+	runes := []rune(input)
+
+	for i := 0; i < len(runes); {
+		if unicode.IsSpace(runes[i]) {
+			i++
+
+			continue
+		}
+
+		t := &term{}
+		if runes[i] == '-' && i+1 < len(runes) && !unicode.IsSpace(runes[i+1]) {
+			t.negated = true
+			i++
+		}
+
+		// Operators are a known key followed by a colon.
+		if colon := indexOf(runes[i:], ':'); colon > 0 {
+			if key := strings.ToLower(string(runes[i : i+colon])); isOperator(key) {
+				t.key = key
+				i += colon + 1
+			}
+		}
+
+		if i < len(runes) && runes[i] == '"' {
+			// An unterminated quote runs to the end of the search.
+			end := indexOf(runes[i+1:], '"')
+			if end < 0 {
+				end = len(runes) - i - 1
+			}
+
+			t.value = string(runes[i+1 : i+1+end])
+			t.quoted = true
+			i += end + 2 //nolint:mnd
+		} else {
+			start := i
+			for i < len(runes) && !unicode.IsSpace(runes[i]) {
+				i++
+			}
+
+			t.value = string(runes[start:i])
+		}
+
+		terms = append(terms, t)
+	}
+
+	return terms
+}
+
+func indexOf(runes []rune, r rune) int {
+	for i, c := range runes {
+		if c == r {
+			return i
+		}
+
+		if unicode.IsSpace(c) && r != '"' {
+			return -1
+		}
+	}
+
+	return -1
+}
+
+func isOperator(key string) bool {
+	switch key {
+	case "amount", "date", "method", "category":
+		return true
+	default:
+		return false
+	}
+}
+
+func (t *term) condition(owner uuid.UUID) (squirrel.Sqlizer, error) {
+	switch t.key {
+	case "amount":
+		return amountCondition(t.value)
+	case "date":
+		return dateCondition(t.value)
+	case "method":
+		return squirrel.Expr(`method IN (SELECT id FROM payment_method WHERE owner = ? AND LOWER(display_name) = LOWER(?))`,
+			owner, t.value), nil
+	case "category":
+		return squirrel.Eq{"budget_category": t.value}, nil
+	}
+
+	switch {
+	case !strings.ContainsFunc(t.value, isWordRune):
+		// Searches for punctuation alone have no words to match, so they're ignored like spaces.
+		return nil, nil //nolint:nilnil
+	case t.quoted:
+		return squirrel.Expr("search @@ phraseto_tsquery('"+Config+"', ?)", t.value), nil
+	default:
+		// plainto_tsquery splits the term into quoted words the way the search column was, and the last
+		// one is made a prefix so that a word matches the longer words it starts.
+		return squirrel.Expr("search @@ to_tsquery('"+Config+"', plainto_tsquery('"+Config+"', ?)::TEXT || ':*')",
+			t.value), nil
+	}
+}
+
+func isWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+// splitComparison splits the comparison operator off the start of a value.
+func splitComparison(value string) (string, string) {
+	for _, op := range []string{">=", "<=", ">", "<", "="} {
+		if rest, ok := strings.CutPrefix(value, op); ok {
+			return op, rest
+		}
+	}
+
+	return "=", value
+}
+
+func amountCondition(value string) (squirrel.Sqlizer, error) {
+	op, value := splitComparison(value)
+
+	amount, err := model.ParseMoney(value)
+	if err != nil {
+		return nil, fmt.Errorf("invalid amount in search: %w", err)
+	}
+
+	return squirrel.Expr("amount "+op+" ?", amount), nil
+}
+
+func dateCondition(value string) (squirrel.Sqlizer, error) {
+	op, value := splitComparison(value)
+
+	var start, end time.Time
+	var err error
+
+	switch strings.Count(value, "-") {
+	case 0:
+		start, err = time.ParseInLocation("2006", value, time.UTC)
+		end = start.AddDate(1, 0, 0)
+	case 1:
+		start, err = time.ParseInLocation("2006-01", value, time.UTC)
+		end = start.AddDate(0, 1, 0)
+	default:
+		start, err = time.ParseInLocation(time.DateOnly, value, time.UTC)
+		end = start.AddDate(0, 0, 1)
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("invalid date in search: %w", errors.InvalidInputError{Input: value})
+	}
+
+	// Dates are periods, so "after March" starts in April.
+	switch op {
+	case ">":
+		return squirrel.Expr("date >= ?", end), nil
+	case ">=":
+		return squirrel.Expr("date >= ?", start), nil
+	case "<":
+		return squirrel.Expr("date < ?", start), nil
+	case "<=":
+		return squirrel.Expr("date < ?", end), nil
+	default:
+		return squirrel.Expr("date >= ? AND date < ?", start, end), nil
+	}
+}
+
+// not negates a condition.
+type not struct {
+	squirrel.Sqlizer
+}
+
+func (n not) ToSql() (string, []any, error) {
+	sql, args, err := n.Sqlizer.ToSql()
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to negate condition: %w", err)
+	}
+
+	return "NOT (" + sql + ")", args, nil
+}
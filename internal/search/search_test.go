@@ -0,0 +1,124 @@
+package search_test
+
+import (
+	"testing"
+	"time"
+	"yaba/internal/model"
+	"yaba/internal/search"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+// word is the condition of a free-text word, which matches the start of words.
+const word = "search @@ to_tsquery('simple', plainto_tsquery('simple', ?)::TEXT || ':*')"
+
+func TestParse(t *testing.T) {
+	t.Parallel()
+
+	owner := uuid.New()
+	march := time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)
+	april := time.Date(2025, 4, 1, 0, 0, 0, 0, time.UTC)
+
+	testCases := []struct {
+		name     string
+		input    string
+		expected string
+		args     []any
+	}{
+		{
+			name:     "empty",
+			input:    `  - * ""`,
+			expected: "(1=1)",
+			args:     []any{},
+		},
+		{
+			name:     "words",
+			input:    "coffee  Shop",
+			expected: "(" + word + " AND " + word + ")",
+			args:     []any{"coffee", "Shop"},
+		},
+		{
+			name:     "prefix",
+			input:    "coff* o'neil*",
+			expected: "(" + word + " AND " + word + ")",
+			args:     []any{"coff*", "o'neil*"},
+		},
+		{
+			name:     "phrase",
+			input:    `"gift card" -"late fee`,
+			expected: "(search @@ phraseto_tsquery('simple', ?) AND NOT (search @@ phraseto_tsquery('simple', ?)))",
+			args:     []any{"gift card", "late fee"},
+		},
+		{
+			name:     "negation",
+			input:    "-starbucks -category:travel",
+			expected: "(NOT (" + word + ") AND NOT (budget_category = ?))",
+			args:     []any{"starbucks", "travel"},
+		},
+		{
+			name:     "amount",
+			input:    "amount:>50 AMOUNT:<=100.25 amount:12.5",
+			expected: "(amount > ? AND amount <= ? AND amount = ?)",
+			args:     []any{model.NewMoney(50, 0), model.NewMoney(100, 25), model.NewMoney(12, 50)},
+		},
+		{
+			name:     "month",
+			input:    "date:2025-03",
+			expected: "(date >= ? AND date < ?)",
+			args:     []any{march, april},
+		},
+		{
+			name:     "date comparisons",
+			input:    "date:>2025-03 date:<=2025 date:>=2025-03-31",
+			expected: "(date >= ? AND date < ? AND date >= ?)",
+			args: []any{
+				april,
+				time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC),
+				time.Date(2025, 3, 31, 0, 0, 0, 0, time.UTC),
+			},
+		},
+		{
+			name:  "method",
+			input: `method:"Visa Infinite"`,
+			expected: "(method IN (SELECT id FROM payment_method WHERE owner = ? AND " +
+				"LOWER(display_name) = LOWER(?)))",
+			args: []any{owner, "Visa Infinite"},
+		},
+		{
+			name:     "uncategorized",
+			input:    `category:""`,
+			expected: "(budget_category = ?)",
+			args:     []any{""},
+		},
+		{
+			name:     "unknown operator",
+			input:    "note:receipt",
+			expected: "(" + word + ")",
+			args:     []any{"note:receipt"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			conditions, err := search.Parse(tc.input, owner)
+			require.NoError(t, err)
+
+			sql, args, err := conditions.ToSql()
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, sql)
+			require.Equal(t, tc.args, args)
+		})
+	}
+}
+
+func TestParseInvalid(t *testing.T) {
+	t.Parallel()
+
+	for _, input := range []string{"amount:>fifty", "amount:", "date:2025-13", "date:March", "date:2025-03-01-02"} {
+		_, err := search.Parse(input, uuid.New())
+		require.Error(t, err, input)
+	}
+}
@@ -110,7 +110,7 @@ func (g *TestDataGenerator) GenerateExpenditures(
 			ID:     i + 1,
 			Owner:  owner,
 			Name:   g.faker.BeerStyle(),
-			Amount: g.faker.Float64Range(0.01, 100),
+			Amount: model.MoneyFromFloat(g.faker.Float64Range(0.01, 100)).Round(),
 			Date: g.faker.DateRange(startDate, endDate.AddDate(0, 0, 1)).
 				UTC().
 				Truncate(24 * time.Hour),
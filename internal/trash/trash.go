@@ -0,0 +1,52 @@
+// Package trash permanently deletes budgets, payment methods and expenditures once they have been in
+// the trash for longer than the retention period.
+package trash
+
+import (
+	"context"
+	"log"
+	"time"
+	"yaba/internal/blob"
+	"yaba/internal/database"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// Sweep purges everything that was trashed more than retention ago, along with the files attached to
+// purged expenditures, and returns the number of records purged.
+func Sweep(ctx context.Context, pool *pgxpool.Pool, store blob.Store, retention time.Duration) (int64, error) {
+	purged, attachments, err := database.PurgeTrash(ctx, pool, time.Now().Add(-retention))
+	if err != nil {
+		return 0, err
+	}
+
+	// The records are already gone, so a file that can't be deleted is only logged.
+	for _, attachment := range attachments {
+		if err = store.Delete(ctx, attachment.BlobKey()); err != nil {
+			log.Println("failed to delete blob of purged attachment:", err)
+		}
+	}
+
+	return purged, nil
+}
+
+// Run sweeps the trash now and then every interval until the context is done.
+func Run(ctx context.Context, pool *pgxpool.Pool, store blob.Store, retention, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		purged, err := Sweep(ctx, pool, store, retention)
+		if err != nil {
+			log.Println("failed to sweep trash:", err)
+		} else if purged > 0 {
+			log.Println("Purged", purged, "records from the trash")
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
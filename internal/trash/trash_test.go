@@ -0,0 +1,68 @@
+package trash_test
+
+import (
+	"bytes"
+	"os"
+	"testing"
+	"time"
+	"yaba/errors"
+	"yaba/internal/attachment"
+	"yaba/internal/blob"
+	"yaba/internal/ctxutil"
+	"yaba/internal/database"
+	"yaba/internal/model"
+	"yaba/internal/test/helper"
+	"yaba/internal/trash"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSweep(t *testing.T) {
+	t.Parallel()
+
+	pool := helper.GetTestPool()
+	owner := uuid.New()
+	ctx := ctxutil.WithUser(t.Context(), owner)
+
+	store, err := blob.NewLocalStore(t.TempDir())
+	require.NoError(t, err)
+
+	expenditures := []*model.Expenditure{
+		{Owner: owner, Name: "Laptop", Amount: model.MoneyFromFloat(1200), Date: time.Now()},
+	}
+	require.NoError(t, database.PersistExpenditures(ctx, pool, expenditures))
+
+	receipt, err := os.ReadFile("../importer/testdata/statement.pdf")
+	require.NoError(t, err)
+
+	saved, err := attachment.Save(ctx, pool, store, expenditures[0].ID, "receipt.pdf", bytes.NewReader(receipt))
+	require.NoError(t, err)
+
+	_, err = database.DeleteExpenditures(ctx, pool, []int{expenditures[0].ID})
+	require.NoError(t, err)
+
+	// Recently trashed records are kept.
+	_, err = trash.Sweep(t.Context(), pool, store, 24*time.Hour)
+	require.NoError(t, err)
+
+	items, err := database.ListTrash(ctx, pool)
+	require.NoError(t, err)
+	require.Len(t, items, 1)
+
+	_, err = pool.Exec(ctx, "UPDATE expenditure SET deleted_at = $1 WHERE id = $2",
+		time.Now().AddDate(0, 0, -2), expenditures[0].ID)
+	require.NoError(t, err)
+
+	purged, err := trash.Sweep(t.Context(), pool, store, 24*time.Hour)
+	require.NoError(t, err)
+	require.GreaterOrEqual(t, purged, int64(1))
+
+	items, err = database.ListTrash(ctx, pool)
+	require.NoError(t, err)
+	require.Empty(t, items)
+
+	// The attached file is deleted with the expenditure.
+	_, err = store.Get(t.Context(), saved.BlobKey())
+	require.ErrorAs(t, err, &errors.NoSuchElementError{})
+}
@@ -8,8 +8,11 @@ import (
 	"net/http"
 	"os"
 	"time"
+	"yaba/config"
+	"yaba/internal/blob"
 	"yaba/internal/database"
 	"yaba/internal/handlers"
+	"yaba/internal/trash"
 
 	"github.com/golang-migrate/migrate/v4"
 	"github.com/golang-migrate/migrate/v4/database/postgres"
@@ -43,7 +46,16 @@ func main() {
 
 	log.Println("Migrations applied successfully!")
 
-	rootHandler, err := handlers.BuildServerHandler(pool)
+	blobs, err := blob.NewStoreFromEnv()
+	if err != nil {
+		log.Fatalln("could not create blob store:", err)
+	}
+
+	if days := config.TrashRetentionDays(); days > 0 {
+		go trash.Run(context.Background(), pool, blobs, time.Duration(days)*24*time.Hour, time.Hour)
+	}
+
+	rootHandler, err := handlers.BuildServerHandler(pool, blobs)
 	if err != nil {
 		log.Fatalln("could not build root handler:", err)
 	}
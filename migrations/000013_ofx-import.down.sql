@@ -0,0 +1,7 @@
+ALTER TABLE IF EXISTS payment_method
+    DROP COLUMN IF EXISTS account_id;
+
+DROP INDEX IF EXISTS idx_owner_method_external_id;
+
+ALTER TABLE IF EXISTS expenditure
+    DROP COLUMN IF EXISTS external_id;
@@ -0,0 +1,10 @@
+ALTER TABLE IF EXISTS expenditure
+    ADD COLUMN IF NOT EXISTS external_id VARCHAR(255) NOT NULL DEFAULT '';
+
+-- Transaction IDs from the bank (e.g. OFX FITID) are unique per account.
+CREATE UNIQUE INDEX IF NOT EXISTS idx_owner_method_external_id
+    ON expenditure USING BTREE(owner, method, external_id)
+    WHERE external_id != '';
+
+ALTER TABLE IF EXISTS payment_method
+    ADD COLUMN IF NOT EXISTS account_id TEXT NOT NULL DEFAULT '';
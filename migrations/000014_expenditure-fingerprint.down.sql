@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_owner_fingerprint;
+
+ALTER TABLE IF EXISTS expenditure
+    DROP COLUMN IF EXISTS fingerprint;
@@ -0,0 +1,30 @@
+ALTER TABLE IF EXISTS expenditure
+    ADD COLUMN IF NOT EXISTS fingerprint VARCHAR(64) NOT NULL DEFAULT '';
+
+-- Must match database.fingerprint. Identical rows are numbered in insertion order so that existing
+-- duplicates keep distinct fingerprints.
+WITH numbered AS (
+    SELECT id,
+        to_char(date, 'YYYY-MM-DD')
+            || '|' || round(amount, 2)::text
+            || '|' || lower(regexp_replace(trim(COALESCE(name, '')), '\s+', ' ', 'g'))
+            || '|' || COALESCE(method::text, '00000000-0000-0000-0000-000000000000')
+            || '|' || external_id AS fields,
+        owner
+    FROM expenditure
+), ordered AS (
+    SELECT id,
+        fields,
+        row_number() OVER (PARTITION BY owner, fields ORDER BY id) - 1 AS ordinal
+    FROM numbered
+)
+UPDATE expenditure
+    SET fingerprint = encode(sha256(convert_to(
+        ordered.fields || CASE WHEN ordered.ordinal > 0 THEN '#' || ordered.ordinal ELSE '' END,
+        'UTF8')), 'hex')
+    FROM ordered
+    WHERE expenditure.id = ordered.id;
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_owner_fingerprint
+    ON expenditure USING BTREE(owner, fingerprint)
+    WHERE fingerprint != '';
@@ -0,0 +1,6 @@
+DROP INDEX IF EXISTS idx_expenditure_batch_id;
+
+ALTER TABLE IF EXISTS expenditure
+    DROP COLUMN IF EXISTS batch_id;
+
+DROP TABLE IF EXISTS import_batch;
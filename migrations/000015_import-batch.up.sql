@@ -0,0 +1,18 @@
+CREATE TABLE IF NOT EXISTS import_batch
+(
+    id         UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    owner      UUID        NOT NULL,
+    source     VARCHAR(50) NOT NULL DEFAULT '',
+    row_count  INT         NOT NULL DEFAULT 0,
+    start_date DATE,
+    end_date   DATE,
+    created    TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_import_batch_owner_created ON import_batch USING BTREE(owner, created);
+
+-- Expenditures saved before batches were recorded belong to the nil batch.
+ALTER TABLE IF EXISTS expenditure
+    ADD COLUMN IF NOT EXISTS batch_id UUID NOT NULL DEFAULT uuid_nil();
+
+CREATE INDEX IF NOT EXISTS idx_expenditure_batch_id ON expenditure USING BTREE(batch_id);
@@ -0,0 +1,14 @@
+CREATE TABLE IF NOT EXISTS categorization_rule
+(
+    id              UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    owner           UUID        NOT NULL,
+    priority        INT         NOT NULL DEFAULT 0,
+    match_type      VARCHAR(20) NOT NULL DEFAULT 'CONTAINS',
+    pattern         TEXT        NOT NULL,
+    budget_category VARCHAR(50) NOT NULL DEFAULT '',
+    reward_category VARCHAR(30) NOT NULL DEFAULT '',
+    method          UUID        NOT NULL DEFAULT uuid_nil(),
+    created         TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_categorization_rule_owner ON categorization_rule USING BTREE(owner);
@@ -0,0 +1,8 @@
+-- Each user's category classifier, trained on their expenditures up to last_expenditure_id.
+CREATE TABLE IF NOT EXISTS category_model
+(
+    owner               UUID PRIMARY KEY,
+    model               JSONB       NOT NULL,
+    last_expenditure_id INT         NOT NULL DEFAULT 0,
+    updated             TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
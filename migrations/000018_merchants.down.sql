@@ -0,0 +1,8 @@
+DROP INDEX IF EXISTS idx_expenditure_owner_merchant_id;
+
+ALTER TABLE IF EXISTS expenditure
+    DROP COLUMN IF EXISTS merchant_id;
+
+DROP TABLE IF EXISTS merchant_alias;
+
+DROP TABLE IF EXISTS merchant;
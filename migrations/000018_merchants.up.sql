@@ -0,0 +1,25 @@
+CREATE TABLE IF NOT EXISTS merchant
+(
+    id      UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    owner   UUID         NOT NULL,
+    name    VARCHAR(100) NOT NULL,
+    created TIMESTAMPTZ  NOT NULL DEFAULT NOW(),
+    UNIQUE (owner, name)
+);
+
+-- Normalized statement names that belong to a merchant.
+CREATE TABLE IF NOT EXISTS merchant_alias
+(
+    owner       UUID NOT NULL,
+    pattern     TEXT NOT NULL,
+    merchant_id UUID NOT NULL REFERENCES merchant (id) ON DELETE CASCADE,
+    PRIMARY KEY (owner, pattern)
+);
+
+CREATE INDEX IF NOT EXISTS idx_merchant_alias_merchant_id ON merchant_alias USING BTREE(merchant_id);
+
+-- Expenditures saved before merchants were tracked belong to the nil merchant.
+ALTER TABLE IF EXISTS expenditure
+    ADD COLUMN IF NOT EXISTS merchant_id UUID NOT NULL DEFAULT uuid_nil();
+
+CREATE INDEX IF NOT EXISTS idx_expenditure_owner_merchant_id ON expenditure USING BTREE(owner, merchant_id);
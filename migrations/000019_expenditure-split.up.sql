@@ -0,0 +1,14 @@
+-- Divides the amount of an expenditure across budget categories. Expenditures without splits count
+-- entirely towards their own category.
+CREATE TABLE IF NOT EXISTS expenditure_split
+(
+    id              SERIAL PRIMARY KEY,
+    expenditure_id  INT           NOT NULL REFERENCES expenditure (id) ON DELETE CASCADE,
+    owner           UUID          NOT NULL,
+    budget_category CITEXT        NOT NULL DEFAULT '',
+    expense_id      UUID          NOT NULL DEFAULT uuid_nil(),
+    amount          NUMERIC(20,4) NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_expenditure_split_expenditure_id ON expenditure_split USING BTREE(expenditure_id);
+CREATE INDEX IF NOT EXISTS idx_expenditure_split_owner_category ON expenditure_split USING BTREE(owner, budget_category);
@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_expenditure_refund_of;
+
+ALTER TABLE IF EXISTS expenditure
+    DROP COLUMN IF EXISTS refund_of;
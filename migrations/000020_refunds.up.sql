@@ -0,0 +1,6 @@
+-- A credit that reverses an earlier purchase, fully or in part. Refunds count towards the category
+-- of the purchase they reverse.
+ALTER TABLE IF EXISTS expenditure
+    ADD COLUMN IF NOT EXISTS refund_of INT REFERENCES expenditure (id) ON DELETE SET NULL;
+
+CREATE INDEX IF NOT EXISTS idx_expenditure_refund_of ON expenditure USING BTREE(refund_of);
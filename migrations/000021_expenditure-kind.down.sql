@@ -0,0 +1,7 @@
+DROP INDEX IF EXISTS idx_expenditure_transfer_id;
+
+ALTER TABLE IF EXISTS expenditure
+    DROP COLUMN IF EXISTS transfer_id;
+
+ALTER TABLE IF EXISTS expenditure
+    DROP COLUMN IF EXISTS kind;
@@ -0,0 +1,8 @@
+ALTER TABLE IF EXISTS expenditure
+    ADD COLUMN IF NOT EXISTS kind VARCHAR(20) NOT NULL DEFAULT 'EXPENSE';
+
+-- Both sides of a transfer between the user's payment methods point at each other.
+ALTER TABLE IF EXISTS expenditure
+    ADD COLUMN IF NOT EXISTS transfer_id INT REFERENCES expenditure (id) ON DELETE SET NULL;
+
+CREATE INDEX IF NOT EXISTS idx_expenditure_transfer_id ON expenditure USING BTREE(transfer_id);
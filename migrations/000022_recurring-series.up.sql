@@ -0,0 +1,19 @@
+-- Charges that repeat at a regular cadence, detected from the owner's expenditures. The rows are
+-- replaced every time detection runs.
+CREATE TABLE IF NOT EXISTS recurring_series
+(
+    id              UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    owner           UUID          NOT NULL,
+    merchant_id     UUID          NOT NULL REFERENCES merchant (id) ON DELETE CASCADE,
+    name            VARCHAR(50)   NOT NULL DEFAULT '',
+    cadence         VARCHAR(20)   NOT NULL,
+    amount          NUMERIC(20,4) NOT NULL,
+    previous_amount NUMERIC(20,4) NOT NULL,
+    occurrences     INT           NOT NULL,
+    last_date       DATE          NOT NULL,
+    next_date       DATE          NOT NULL,
+    price_changed   BOOLEAN       NOT NULL DEFAULT FALSE,
+    created         TIMESTAMPTZ   NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_recurring_series_owner_next_date ON recurring_series USING BTREE(owner, next_date);
@@ -0,0 +1,6 @@
+DROP FUNCTION IF EXISTS exchange_rate_on(VARCHAR, VARCHAR, DATE);
+DROP TABLE IF EXISTS exchange_rate;
+ALTER TABLE expense DROP COLUMN IF EXISTS currency;
+ALTER TABLE income DROP COLUMN IF EXISTS currency;
+ALTER TABLE expenditure DROP COLUMN IF EXISTS currency;
+ALTER TABLE user_profile DROP COLUMN IF EXISTS home_currency;
@@ -0,0 +1,47 @@
+-- Amounts are in the currency of their row. An empty currency is the owner's home currency.
+ALTER TABLE user_profile ADD COLUMN IF NOT EXISTS home_currency VARCHAR(3) NOT NULL DEFAULT 'CAD';
+ALTER TABLE expenditure ADD COLUMN IF NOT EXISTS currency VARCHAR(3) NOT NULL DEFAULT '';
+ALTER TABLE income ADD COLUMN IF NOT EXISTS currency VARCHAR(3) NOT NULL DEFAULT '';
+ALTER TABLE expense ADD COLUMN IF NOT EXISTS currency VARCHAR(3) NOT NULL DEFAULT '';
+
+-- One unit of base is worth rate units of quote on the date. Rates are shared by all users.
+CREATE TABLE IF NOT EXISTS exchange_rate
+(
+    date  DATE           NOT NULL,
+    base  VARCHAR(3)     NOT NULL,
+    quote VARCHAR(3)     NOT NULL,
+    rate  NUMERIC(20,10) NOT NULL,
+    PRIMARY KEY (base, quote, date)
+);
+
+-- The value of one unit of from_currency in to_currency on the date. Direct, inverse and cross rates
+-- through a common base are considered, preferring the closest rate on or before the date, then the
+-- closest one after it. Returns NULL if no rate between the currencies is known.
+CREATE OR REPLACE FUNCTION exchange_rate_on(from_currency VARCHAR, to_currency VARCHAR, on_date DATE)
+    RETURNS NUMERIC
+    LANGUAGE SQL
+    STABLE
+AS
+$$
+SELECT CASE
+           WHEN from_currency = '' OR from_currency = to_currency THEN 1
+           ELSE (SELECT rate
+                 FROM (SELECT date, rate
+                       FROM exchange_rate
+                       WHERE base = from_currency
+                         AND quote = to_currency
+                       UNION ALL
+                       SELECT date, 1 / rate
+                       FROM exchange_rate
+                       WHERE base = to_currency
+                         AND quote = from_currency
+                       UNION ALL
+                       SELECT f.date, t.rate / f.rate
+                       FROM exchange_rate f
+                                JOIN exchange_rate t ON t.base = f.base AND t.date = f.date
+                       WHERE f.quote = from_currency
+                         AND t.quote = to_currency) AS rates
+                 ORDER BY date > on_date, ABS(date - on_date)
+                 LIMIT 1)
+           END
+$$;
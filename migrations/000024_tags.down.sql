@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS expenditure_tag;
+
+DROP TABLE IF EXISTS tag;
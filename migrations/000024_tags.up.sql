@@ -0,0 +1,19 @@
+-- Labels that cut across budget categories and payment methods, like a trip. Names are unique per
+-- user, ignoring case.
+CREATE TABLE IF NOT EXISTS tag
+(
+    id      UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    owner   UUID        NOT NULL,
+    name    CITEXT      NOT NULL,
+    created TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    UNIQUE (owner, name)
+);
+
+CREATE TABLE IF NOT EXISTS expenditure_tag
+(
+    expenditure_id INT  NOT NULL REFERENCES expenditure (id) ON DELETE CASCADE,
+    tag_id         UUID NOT NULL REFERENCES tag (id) ON DELETE CASCADE,
+    PRIMARY KEY (expenditure_id, tag_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_expenditure_tag_tag_id ON expenditure_tag USING BTREE(tag_id);
@@ -0,0 +1,14 @@
+-- Files attached to expenditures, like receipts. The content is kept in the blob store under
+-- "owner/id".
+CREATE TABLE IF NOT EXISTS attachment
+(
+    id             UUID PRIMARY KEY,
+    owner          UUID         NOT NULL,
+    expenditure_id INT          NOT NULL REFERENCES expenditure (id) ON DELETE CASCADE,
+    filename       VARCHAR(255) NOT NULL,
+    content_type   VARCHAR(100) NOT NULL,
+    size           BIGINT       NOT NULL,
+    created        TIMESTAMPTZ  NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_attachment_expenditure_id ON attachment USING BTREE(expenditure_id);
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_expenditure_search;
+
+ALTER TABLE expenditure DROP COLUMN IF EXISTS search;
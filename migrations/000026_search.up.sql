@@ -0,0 +1,7 @@
+-- Words of the name and comment for full-text search. The simple configuration keeps words as
+-- written, without stemming or stop words, which suits merchant names.
+ALTER TABLE expenditure
+    ADD COLUMN IF NOT EXISTS search TSVECTOR
+        GENERATED ALWAYS AS (to_tsvector('simple', COALESCE(name, '') || ' ' || COALESCE(comment, ''))) STORED;
+
+CREATE INDEX IF NOT EXISTS idx_expenditure_search ON expenditure USING GIN(search);
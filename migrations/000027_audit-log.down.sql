@@ -0,0 +1,14 @@
+DROP TRIGGER IF EXISTS audit_budget_insert_delete ON budget;
+DROP TRIGGER IF EXISTS audit_budget_update ON budget;
+DROP TRIGGER IF EXISTS audit_income_insert_delete ON income;
+DROP TRIGGER IF EXISTS audit_income_update ON income;
+DROP TRIGGER IF EXISTS audit_expense_insert_delete ON expense;
+DROP TRIGGER IF EXISTS audit_expense_update ON expense;
+DROP TRIGGER IF EXISTS audit_payment_method_insert_delete ON payment_method;
+DROP TRIGGER IF EXISTS audit_payment_method_update ON payment_method;
+DROP TRIGGER IF EXISTS audit_expenditure_insert_delete ON expenditure;
+DROP TRIGGER IF EXISTS audit_expenditure_update ON expenditure;
+DROP FUNCTION IF EXISTS audit_change();
+
+DROP TABLE IF EXISTS audit_log;
+DROP FUNCTION IF EXISTS audit_log_append_only();
@@ -0,0 +1,82 @@
+-- An append-only record of every change to budgets, incomes, expenses, payment methods and
+-- expenditures, with the row as JSON before and after the change. Entries are written by triggers, so
+-- they are part of the transaction that made the change. Users only change their own rows, so the
+-- owner is who made the change. Incomes have no ID of their own and are recorded under their budget.
+CREATE TABLE IF NOT EXISTS audit_log
+(
+    id             BIGSERIAL PRIMARY KEY,
+    owner          UUID        NOT NULL,
+    entity_type    VARCHAR(20) NOT NULL,
+    entity_id      TEXT        NOT NULL,
+    action         VARCHAR(6)  NOT NULL CHECK (action IN ('INSERT', 'UPDATE', 'DELETE')),
+    before         JSONB,
+    after          JSONB,
+    changed        TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    -- Changes made together, like saving a budget, share a transaction ID.
+    transaction_id BIGINT      NOT NULL DEFAULT txid_current()
+);
+
+CREATE INDEX IF NOT EXISTS idx_audit_log_entity ON audit_log USING BTREE(owner, entity_id, id);
+
+CREATE OR REPLACE FUNCTION audit_change()
+    RETURNS TRIGGER
+    LANGUAGE plpgsql
+AS
+$$
+DECLARE
+    -- The search column of expenditures is derived from the name and comment.
+    old_row     JSONB := CASE WHEN TG_OP <> 'INSERT' THEN to_jsonb(OLD) - 'search' END;
+    new_row     JSONB := CASE WHEN TG_OP <> 'DELETE' THEN to_jsonb(NEW) - 'search' END;
+    row_data    JSONB := COALESCE(new_row, old_row);
+    entry_owner UUID;
+    entry_id    TEXT  := row_data ->> 'id';
+BEGIN
+    CASE TG_TABLE_NAME
+        WHEN 'income' THEN
+            -- The owner of an income is its budget.
+            entry_id := row_data ->> 'owner';
+            SELECT owner INTO entry_owner FROM budget WHERE id = entry_id::UUID;
+        WHEN 'expense' THEN
+            SELECT owner INTO entry_owner FROM budget WHERE id = (row_data ->> 'budget_id')::UUID;
+        ELSE
+            entry_owner := (row_data ->> 'owner')::UUID;
+    END CASE;
+
+    INSERT INTO audit_log (owner, entity_type, entity_id, action, before, after)
+    VALUES (entry_owner, TG_TABLE_NAME, entry_id, TG_OP, old_row, new_row);
+
+    RETURN NULL;
+END
+$$;
+
+CREATE OR REPLACE FUNCTION audit_log_append_only()
+    RETURNS TRIGGER
+    LANGUAGE plpgsql
+AS
+$$
+BEGIN
+    RAISE EXCEPTION 'audit_log is append-only';
+END
+$$;
+
+CREATE OR REPLACE TRIGGER audit_log_append_only
+    BEFORE UPDATE OR DELETE OR TRUNCATE
+    ON audit_log
+    FOR EACH STATEMENT
+EXECUTE FUNCTION audit_log_append_only();
+
+-- Updates that don't change anything, like upserting an unchanged expense, aren't recorded.
+DO
+$$
+    DECLARE
+        audited TEXT;
+    BEGIN
+        FOREACH audited IN ARRAY ARRAY ['budget', 'income', 'expense', 'payment_method', 'expenditure']
+            LOOP
+                EXECUTE format('CREATE OR REPLACE TRIGGER audit_%1$s_insert_delete AFTER INSERT OR DELETE ON %1$I
+                    FOR EACH ROW EXECUTE FUNCTION audit_change()', audited);
+                EXECUTE format('CREATE OR REPLACE TRIGGER audit_%1$s_update AFTER UPDATE ON %1$I
+                    FOR EACH ROW WHEN (OLD.* IS DISTINCT FROM NEW.*) EXECUTE FUNCTION audit_change()', audited);
+            END LOOP;
+    END
+$$;
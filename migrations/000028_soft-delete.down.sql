@@ -0,0 +1,16 @@
+DELETE FROM expenditure WHERE deleted_at IS NOT NULL;
+DELETE FROM payment_method WHERE deleted_at IS NOT NULL;
+DELETE FROM income WHERE owner IN (SELECT id FROM budget WHERE deleted_at IS NOT NULL);
+DELETE FROM expense WHERE budget_id IN (SELECT id FROM budget WHERE deleted_at IS NOT NULL);
+DELETE FROM budget WHERE deleted_at IS NOT NULL;
+
+DROP INDEX IF EXISTS idx_owner_name;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_owner_name ON budget USING BTREE(owner, name);
+
+DROP INDEX IF EXISTS idx_expenditure_deleted_at;
+DROP INDEX IF EXISTS idx_payment_method_deleted_at;
+DROP INDEX IF EXISTS idx_budget_deleted_at;
+
+ALTER TABLE expenditure DROP COLUMN IF EXISTS deleted_at;
+ALTER TABLE payment_method DROP COLUMN IF EXISTS deleted_at;
+ALTER TABLE budget DROP COLUMN IF EXISTS deleted_at;
@@ -0,0 +1,18 @@
+-- Deleted budgets, payment methods and expenditures are kept in the trash until they are restored or
+-- the retention period is over. The incomes and expenses of a budget are trashed with it.
+ALTER TABLE budget ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMPTZ;
+ALTER TABLE payment_method ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMPTZ;
+ALTER TABLE expenditure ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMPTZ;
+
+CREATE INDEX IF NOT EXISTS idx_budget_deleted_at ON budget USING BTREE(deleted_at) WHERE deleted_at IS NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_payment_method_deleted_at
+    ON payment_method USING BTREE(deleted_at)
+    WHERE deleted_at IS NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_expenditure_deleted_at
+    ON expenditure USING BTREE(deleted_at)
+    WHERE deleted_at IS NOT NULL;
+
+-- A trashed budget doesn't keep its name from being used again. Trashed expenditures still keep
+-- their fingerprints, so importing the same statement again doesn't bring them back.
+DROP INDEX IF EXISTS idx_owner_name;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_owner_name ON budget USING BTREE(owner, name) WHERE deleted_at IS NULL;
@@ -0,0 +1,8 @@
+DROP TABLE IF EXISTS budget_allocation;
+DROP TABLE IF EXISTS budget_period;
+
+ALTER TABLE expense DROP COLUMN IF EXISTS rollover;
+
+ALTER TABLE budget DROP COLUMN IF EXISTS period_days;
+ALTER TABLE budget DROP COLUMN IF EXISTS period_start;
+ALTER TABLE budget DROP COLUMN IF EXISTS period;
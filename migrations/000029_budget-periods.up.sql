@@ -0,0 +1,34 @@
+-- A budget is divided into periods: calendar months, 14 days or a custom number of days, starting on
+-- period_start. Existing budgets start with the current month.
+ALTER TABLE budget ADD COLUMN IF NOT EXISTS period VARCHAR(10) NOT NULL DEFAULT 'MONTHLY'
+    CHECK (period IN ('MONTHLY', 'BIWEEKLY', 'CUSTOM'));
+ALTER TABLE budget ADD COLUMN IF NOT EXISTS period_start DATE NOT NULL DEFAULT date_trunc('month', NOW())::DATE;
+ALTER TABLE budget ADD COLUMN IF NOT EXISTS period_days INT NOT NULL DEFAULT 0 CHECK (period_days >= 0);
+
+-- What is left of an expense at the end of a period, or what was overspent, is carried into the next
+-- period if rollover is set.
+ALTER TABLE expense ADD COLUMN IF NOT EXISTS rollover BOOLEAN NOT NULL DEFAULT FALSE;
+
+-- Periods keep a snapshot of the expenses of their budget, so changing the budget doesn't change
+-- periods that are over. Snapshots of periods that aren't over yet are taken again when they are read.
+CREATE TABLE IF NOT EXISTS budget_period
+(
+    id         UUID PRIMARY KEY,
+    budget_id  UUID NOT NULL REFERENCES budget (id) ON DELETE CASCADE,
+    start_date DATE NOT NULL,
+    end_date   DATE NOT NULL
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_budget_period_dates
+    ON budget_period USING BTREE(budget_id, start_date, end_date);
+
+CREATE TABLE IF NOT EXISTS budget_allocation
+(
+    period_id  UUID          NOT NULL REFERENCES budget_period (id) ON DELETE CASCADE,
+    expense_id UUID          NOT NULL,
+    category   VARCHAR(20)   NOT NULL,
+    amount     NUMERIC(20,4) NOT NULL,
+    currency   VARCHAR(3)    NOT NULL DEFAULT '',
+    rollover   BOOLEAN       NOT NULL DEFAULT FALSE,
+    PRIMARY KEY (period_id, expense_id)
+);
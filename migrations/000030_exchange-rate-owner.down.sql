@@ -0,0 +1,42 @@
+DROP FUNCTION IF EXISTS exchange_rate_on(UUID, VARCHAR, VARCHAR, DATE);
+
+-- Rates can't be shared again without conflicts, so only one copy of each is kept.
+DELETE FROM exchange_rate r
+    USING exchange_rate o
+WHERE o.base = r.base
+  AND o.quote = r.quote
+  AND o.date = r.date
+  AND o.owner < r.owner;
+
+ALTER TABLE exchange_rate DROP CONSTRAINT IF EXISTS exchange_rate_pkey;
+ALTER TABLE exchange_rate DROP COLUMN IF EXISTS owner;
+ALTER TABLE exchange_rate ADD PRIMARY KEY (base, quote, date);
+
+CREATE OR REPLACE FUNCTION exchange_rate_on(from_currency VARCHAR, to_currency VARCHAR, on_date DATE)
+    RETURNS NUMERIC
+    LANGUAGE SQL
+    STABLE
+AS
+$$
+SELECT CASE
+           WHEN from_currency = '' OR from_currency = to_currency THEN 1
+           ELSE (SELECT rate
+                 FROM (SELECT date, rate
+                       FROM exchange_rate
+                       WHERE base = from_currency
+                         AND quote = to_currency
+                       UNION ALL
+                       SELECT date, 1 / rate
+                       FROM exchange_rate
+                       WHERE base = to_currency
+                         AND quote = from_currency
+                       UNION ALL
+                       SELECT f.date, t.rate / f.rate
+                       FROM exchange_rate f
+                                JOIN exchange_rate t ON t.base = f.base AND t.date = f.date
+                       WHERE f.quote = from_currency
+                         AND t.quote = to_currency) AS rates
+                 ORDER BY date > on_date, ABS(date - on_date)
+                 LIMIT 1)
+           END
+$$;
@@ -0,0 +1,54 @@
+-- Exchange rates belong to the user who imported them, so that one user's rates can't change another
+-- user's totals. Rates saved before this are copied to every user.
+ALTER TABLE exchange_rate ADD COLUMN IF NOT EXISTS owner UUID;
+ALTER TABLE exchange_rate DROP CONSTRAINT IF EXISTS exchange_rate_pkey;
+
+INSERT INTO exchange_rate (owner, date, base, quote, rate)
+SELECT u.id, r.date, r.base, r.quote, r.rate
+FROM user_profile u
+         CROSS JOIN exchange_rate r
+WHERE r.owner IS NULL;
+
+DELETE FROM exchange_rate WHERE owner IS NULL;
+
+ALTER TABLE exchange_rate ALTER COLUMN owner SET NOT NULL;
+ALTER TABLE exchange_rate ADD PRIMARY KEY (owner, base, quote, date);
+
+DROP FUNCTION IF EXISTS exchange_rate_on(VARCHAR, VARCHAR, DATE);
+
+-- The value of one unit of from_currency in to_currency on the date, from the owner's rates. Direct,
+-- inverse and cross rates through a common base are considered, preferring the closest rate on or
+-- before the date, then the closest one after it. Returns NULL if no rate between the currencies is
+-- known.
+CREATE OR REPLACE FUNCTION exchange_rate_on(rate_owner UUID, from_currency VARCHAR, to_currency VARCHAR,
+                                            on_date DATE)
+    RETURNS NUMERIC
+    LANGUAGE SQL
+    STABLE
+AS
+$$
+SELECT CASE
+           WHEN from_currency = '' OR from_currency = to_currency THEN 1
+           ELSE (SELECT rate
+                 FROM (SELECT date, rate
+                       FROM exchange_rate
+                       WHERE owner = rate_owner
+                         AND base = from_currency
+                         AND quote = to_currency
+                       UNION ALL
+                       SELECT date, 1 / rate
+                       FROM exchange_rate
+                       WHERE owner = rate_owner
+                         AND base = to_currency
+                         AND quote = from_currency
+                       UNION ALL
+                       SELECT f.date, t.rate / f.rate
+                       FROM exchange_rate f
+                                JOIN exchange_rate t ON t.owner = f.owner AND t.base = f.base AND t.date = f.date
+                       WHERE f.owner = rate_owner
+                         AND f.quote = from_currency
+                         AND t.quote = to_currency) AS rates
+                 ORDER BY date > on_date, ABS(date - on_date)
+                 LIMIT 1)
+           END
+$$;
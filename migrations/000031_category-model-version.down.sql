@@ -0,0 +1,6 @@
+DROP TRIGGER IF EXISTS category_model_outdated_update ON expenditure;
+DROP TRIGGER IF EXISTS category_model_outdated_delete ON expenditure;
+DROP FUNCTION IF EXISTS category_model_outdated();
+
+ALTER TABLE category_model DROP COLUMN IF EXISTS trained_version;
+ALTER TABLE category_model DROP COLUMN IF EXISTS version;
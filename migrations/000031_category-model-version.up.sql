@@ -0,0 +1,38 @@
+-- Category models are trained incrementally on new expenditures. Changing what an expenditure that was
+-- already learned says, or removing it, bumps the version of its owner's model, and a model whose
+-- trained_version is behind is trained again on all of the owner's expenditures.
+ALTER TABLE category_model ADD COLUMN IF NOT EXISTS version BIGINT NOT NULL DEFAULT 0;
+ALTER TABLE category_model ADD COLUMN IF NOT EXISTS trained_version BIGINT NOT NULL DEFAULT 0;
+
+-- Labels are now learned in lower case, so existing models are trained again.
+UPDATE category_model SET version = version + 1;
+
+CREATE OR REPLACE FUNCTION category_model_outdated()
+    RETURNS TRIGGER
+    LANGUAGE plpgsql
+AS
+$$
+BEGIN
+    -- The row is created if the model is being trained for the first time, so that training can't save
+    -- a model of the expenditures from before the change.
+    INSERT INTO category_model (owner, model, version)
+    VALUES (OLD.owner, '{}', 1)
+    ON CONFLICT (owner) DO UPDATE SET version = category_model.version + 1;
+
+    RETURN NULL;
+END
+$$;
+
+CREATE OR REPLACE TRIGGER category_model_outdated_update
+    AFTER UPDATE OF name, comment, budget_category, reward_category, deleted_at
+    ON expenditure
+    FOR EACH ROW
+    WHEN ((OLD.name, OLD.comment, OLD.budget_category, OLD.reward_category, OLD.deleted_at)
+        IS DISTINCT FROM (NEW.name, NEW.comment, NEW.budget_category, NEW.reward_category, NEW.deleted_at))
+EXECUTE FUNCTION category_model_outdated();
+
+CREATE OR REPLACE TRIGGER category_model_outdated_delete
+    AFTER DELETE
+    ON expenditure
+    FOR EACH ROW
+EXECUTE FUNCTION category_model_outdated();
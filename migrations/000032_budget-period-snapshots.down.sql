@@ -0,0 +1,3 @@
+COMMENT ON TABLE budget_period IS NULL;
+
+-- The dropped snapshots are taken again when the periods are read.
@@ -0,0 +1,7 @@
+-- Periods are now saved when their budget is saved, once they have ended, instead of when they are
+-- read. Snapshots of periods that hadn't ended when they were read have the expenses the budget had
+-- then rather than when the period ended, so they are dropped and taken again when the budget is
+-- next saved.
+DELETE FROM budget_period WHERE end_date >= CURRENT_DATE;
+
+COMMENT ON TABLE budget_period IS 'Ended periods of a budget, saved with their expenses when the budget is saved';
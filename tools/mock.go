@@ -2,7 +2,6 @@ package main
 
 import (
 	"encoding/csv"
-	"fmt"
 	"log"
 	"os"
 	"time"
@@ -47,7 +46,7 @@ func main() {
 
 	for _, exp := range expenditures {
 		err := csvWriter.Write(
-			[]string{exp.Date.Format(time.DateOnly), fmt.Sprintf("%.2f", exp.Amount),
+			[]string{exp.Date.Format(time.DateOnly), exp.Amount.String(),
 				exp.Name, id2method[exp.Method].DisplayName, exp.BudgetCategory, exp.RewardCategory, exp.Comment},
 		)
 		if err != nil {